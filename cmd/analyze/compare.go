@@ -0,0 +1,140 @@
+package main
+
+import (
+	"ClassiFaaS/internal/analysis"
+	"ClassiFaaS/internal/results"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+)
+
+// Exit codes of the compare command.
+const (
+	exitNoChange = 0
+	exitError    = 1
+	exitChanged  = 2
+)
+
+// runCompare compares runs statistically and exits with exitChanged if a
+// regression or CPU mix shift was detected.
+func runCompare(args []string) {
+	fs := flag.NewFlagSet("compare", flag.ExitOnError)
+	metric := fs.String("metric", analysis.MetricBenchmark, "Metric to compare: 'benchmark' (primary benchmark metric), 'latency' or a dotted body path")
+	alpha := fs.Float64("alpha", 0.05, "Significance level")
+	minEffect := fs.Float64("min-effect", 0.05, "Minimum relative change of the median flagged as regression")
+	resamples := fs.Int("resamples", 2000, "Number of bootstrap resamples")
+	againstFirst := fs.Bool("against-first", false, "Compare every run against the first run instead of its predecessor")
+	resultFolder := fs.String("results", "", "Compare all runs below this result folder (instead of explicit run folders)")
+	since := fs.String("since", "", "With --results: only runs started at or after this date (YYYY-MM-DD)")
+	until := fs.String("until", "", "With --results: only runs started before this date (YYYY-MM-DD)")
+	asJSON := fs.Bool("json", false, "Print the comparison as JSON")
+	fs.Parse(args)
+
+	if *resamples <= 0 {
+		fmt.Println("--resamples must be greater than 0")
+		os.Exit(exitError)
+	}
+
+	folders := fs.Args()
+	if *resultFolder != "" {
+		var err error
+		if folders, err = findRuns(*resultFolder, *since, *until); err != nil {
+			fmt.Println(err)
+			os.Exit(exitError)
+		}
+	}
+	if len(folders) < 2 {
+		fmt.Println("Usage: analyze compare [flags] <run folder> <run folder>... | --results <folder> [--since DATE] [--until DATE]")
+		os.Exit(exitError)
+	}
+
+	runs, err := loadRuns(folders)
+	if err != nil {
+		fmt.Println("Failed to load runs:", err)
+		os.Exit(exitError)
+	}
+
+	comparisons, err := analysis.Compare(runs, analysis.CompareOptions{
+		Metric:       *metric,
+		Alpha:        *alpha,
+		MinEffect:    *minEffect,
+		Resamples:    *resamples,
+		AgainstFirst: *againstFirst,
+	})
+	if err != nil {
+		fmt.Println("Failed to compare runs:", err)
+		os.Exit(exitError)
+	}
+
+	changed := false
+	for _, c := range comparisons {
+		if c.Regression || c.CPUMixShift {
+			changed = true
+		}
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(comparisons)
+	} else {
+		printComparisons(comparisons)
+	}
+
+	if changed {
+		os.Exit(exitChanged)
+	}
+	os.Exit(exitNoChange)
+}
+
+func printComparisons(comparisons []analysis.Comparison) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CANDIDATE\tPROVIDER\tREGION\tBENCHMARK\tMEMORY\tBASE MED\tCAND MED\tCHANGE\tCI\tMWU P\tCPU MIX P\tFLAGS")
+	for _, c := range comparisons {
+		flags := ""
+		switch {
+		case c.Regression:
+			flags = "REGRESSION"
+		case c.Significant:
+			flags = "improvement"
+		}
+		if c.CPUMixShift {
+			flags += " CPU-MIX-SHIFT"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%.2f\t%.2f\t%+.1f%%\t[%.2f, %.2f]\t%.4f\t%.4f\t%s\n",
+			filepath.Base(c.Candidate), c.Provider, c.Region, c.Benchmark, c.MemorySize,
+			c.BaselineMedian, c.CandidateMedian, c.RelativeChange*100,
+			c.MedianDiff.Lower, c.MedianDiff.Upper, c.MannWhitneyP, c.CPUMixP, flags)
+	}
+	w.Flush()
+}
+
+// findRuns returns the run folders below resultFolder started within the
+// optional --since and --until dates.
+func findRuns(resultFolder, since, until string) ([]string, error) {
+	from, err := parseDate(since)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --since: %v", err)
+	}
+	to, err := parseDate(until)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --until: %v", err)
+	}
+	folders, err := results.FindRuns(resultFolder, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find runs: %v", err)
+	}
+	return folders, nil
+}
+
+func parseDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.ParseInLocation("2006-01-02", value, time.Local)
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"ClassiFaaS/internal/export"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// runExport flattens the invocations of the given runs into CSV or Parquet files.
+func runExport(args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	format := fs.String("format", export.FormatCSV, "Output format: 'csv' or 'parquet'")
+	out := fs.String("out", "", "Output file, or output directory with --partition")
+	partition := fs.Bool("partition", false, "Write one file per run below provider=/region=/date= directories")
+	resultFolder := fs.String("results", "", "Export all runs below this result folder (instead of explicit run folders)")
+	since := fs.String("since", "", "With --results: only runs started at or after this date (YYYY-MM-DD)")
+	until := fs.String("until", "", "With --results: only runs started before this date (YYYY-MM-DD)")
+	fs.Parse(args)
+
+	folders := fs.Args()
+	if *resultFolder != "" {
+		var err error
+		if folders, err = findRuns(*resultFolder, *since, *until); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+	if len(folders) == 0 || *out == "" {
+		fmt.Println("Usage: analyze export --out <file|dir> [--format csv|parquet] [--partition] <run folder>... | --results <folder> [--since DATE] [--until DATE]")
+		os.Exit(1)
+	}
+
+	runs, err := loadRuns(folders)
+	if err != nil {
+		fmt.Println("Failed to load runs:", err)
+		os.Exit(1)
+	}
+
+	files, err := export.Export(runs, export.Options{
+		Format:    *format,
+		Out:       *out,
+		Partition: *partition,
+	})
+	if err != nil {
+		fmt.Println("Export failed:", err)
+		os.Exit(1)
+	}
+	for _, file := range files {
+		fmt.Println(file)
+	}
+}
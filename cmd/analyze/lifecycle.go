@@ -0,0 +1,63 @@
+package main
+
+import (
+	"ClassiFaaS/internal/analysis"
+	"ClassiFaaS/internal/results"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+)
+
+// runLifecycle reports instance reuse and container lifecycles of one or more runs.
+func runLifecycle(args []string) {
+	fs := flag.NewFlagSet("lifecycle", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "Print the report as JSON")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fmt.Println("Usage: analyze lifecycle [--json] <run folder>...")
+		os.Exit(1)
+	}
+
+	runs, err := loadRuns(fs.Args())
+	if err != nil {
+		fmt.Println("Failed to load runs:", err)
+		os.Exit(1)
+	}
+
+	reports := analysis.Lifecycle(runs)
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(reports)
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROVIDER\tMEMORY\tINVOCATIONS\tINSTANCES\tINV/INSTANCE (MED/MAX)\tLIFETIME S (MED/MAX)\tHOSTS\tSHARED HOSTS\tCOLD\tCOLD MS (MED)\tWARM MS (MED)")
+	for _, r := range reports {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%.0f/%.0f\t%.1f/%.1f\t%d\t%d\t%d\t%.1f\t%.1f\n",
+			r.Provider, r.MemorySize, r.Invocations, r.Instances,
+			r.InvocationsPerInstance.Median, r.InvocationsPerInstance.Max,
+			r.InstanceLifetimeSec.Median, r.InstanceLifetimeSec.Max,
+			r.Hosts, r.SharedHosts, r.ColdStarts,
+			r.ColdLatencyMs.Median, r.WarmLatencyMs.Median)
+	}
+	w.Flush()
+}
+
+// loadRuns reads the given run folders.
+func loadRuns(folders []string) ([]*results.Run, error) {
+	var runs []*results.Run
+	for _, folder := range folders {
+		run, err := results.LoadRun(folder)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
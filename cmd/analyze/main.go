@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Println("Usage: analyze [lifecycle|compare|export|ingest|query|report] <run folder>...")
+		os.Exit(1)
+	}
+
+	cmd := os.Args[1]
+
+	switch cmd {
+	case "lifecycle":
+		runLifecycle(os.Args[2:])
+	case "compare":
+		runCompare(os.Args[2:])
+	case "export":
+		runExport(os.Args[2:])
+	case "ingest":
+		runIngest(os.Args[2:])
+	case "query":
+		runQuery(os.Args[2:])
+	case "report":
+		runReport(os.Args[2:])
+	default:
+		fmt.Println("Unknown command:", cmd)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"ClassiFaaS/internal/report"
+	"ClassiFaaS/internal/results"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// runReport renders the self-contained HTML report of a run.
+func runReport(args []string) {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	runFolder := fs.String("run", "", "Run folder to report on")
+	out := fs.String("out", "", "Output file (default: report.html in the run folder)")
+	fs.Parse(args)
+
+	if *runFolder == "" {
+		fmt.Println("Usage: analyze report --run <run folder> [--out FILE]")
+		os.Exit(1)
+	}
+	if *out == "" {
+		*out = filepath.Join(*runFolder, "report.html")
+	}
+
+	run, err := results.LoadRun(*runFolder)
+	if err != nil {
+		fmt.Println("Failed to load run:", err)
+		os.Exit(1)
+	}
+
+	file, err := os.Create(*out)
+	if err != nil {
+		fmt.Println("Failed to create report:", err)
+		os.Exit(1)
+	}
+	if err := report.Build(run).Write(file); err != nil {
+		file.Close()
+		fmt.Println("Failed to write report:", err)
+		os.Exit(1)
+	}
+	if err := file.Close(); err != nil {
+		fmt.Println("Failed to write report:", err)
+		os.Exit(1)
+	}
+	fmt.Println(*out)
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"ClassiFaaS/internal/analysis"
+	"ClassiFaaS/internal/store"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+)
+
+// runIngest loads runs into the SQLite results store.
+func runIngest(args []string) {
+	fs := flag.NewFlagSet("ingest", flag.ExitOnError)
+	db := fs.String("db", "results/results.db", "Path to the SQLite results store")
+	resultFolder := fs.String("results", "", "Ingest all runs below this result folder (instead of explicit run folders)")
+	since := fs.String("since", "", "With --results: only runs started at or after this date (YYYY-MM-DD)")
+	until := fs.String("until", "", "With --results: only runs started before this date (YYYY-MM-DD)")
+	fs.Parse(args)
+
+	folders := fs.Args()
+	if *resultFolder != "" {
+		var err error
+		if folders, err = findRuns(*resultFolder, *since, *until); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+	if len(folders) == 0 {
+		fmt.Println("Usage: analyze ingest [--db FILE] <run folder>... | --results <folder> [--since DATE] [--until DATE]")
+		os.Exit(1)
+	}
+
+	s, err := store.Open(*db)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	defer s.Close()
+
+	// Runs are loaded one at a time to bound memory usage.
+	for _, folder := range folders {
+		runs, err := loadRuns([]string{folder})
+		if err != nil {
+			fmt.Println("Failed to load run:", err)
+			os.Exit(1)
+		}
+		result, err := s.Ingest(runs[0])
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s: %d invocations ingested, %d already present\n", folder, result.Inserted, result.Skipped)
+	}
+}
+
+// runQuery prints one of the canned reports of the SQLite results store.
+func runQuery(args []string) {
+	fs := flag.NewFlagSet("query", flag.ExitOnError)
+	db := fs.String("db", "results/results.db", "Path to the SQLite results store")
+	period := fs.String("period", store.PeriodWeek, "Aggregation period: 'day' or 'week'")
+	metric := fs.String("metric", analysis.MetricBenchmark, "Metric of the memory-median report: 'benchmark' or 'latency'")
+	asJSON := fs.Bool("json", false, "Print the report as JSON")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fmt.Println("Usage: analyze query [--db FILE] [--period day|week] [--metric benchmark|latency] [--json] cpu-mix|memory-median")
+		os.Exit(1)
+	}
+
+	// Querying must not create an empty store as a side effect.
+	if _, err := os.Stat(*db); err != nil {
+		fmt.Println("Failed to open store:", err)
+		os.Exit(1)
+	}
+	s, err := store.Open(*db)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	defer s.Close()
+
+	var report any
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	switch fs.Arg(0) {
+	case "cpu-mix":
+		shares, err := s.CPUMix(*period)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		report = shares
+		fmt.Fprintln(w, "PERIOD\tPROVIDER\tREGION\tCPU\tINVOCATIONS\tSHARE")
+		for _, c := range shares {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%.1f%%\n", c.Period, c.Provider, c.Region, c.CPU, c.Count, c.Share*100)
+		}
+	case "memory-median":
+		medians, err := s.MemoryMedians(*metric, *period)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		report = medians
+		fmt.Fprintln(w, "PERIOD\tPROVIDER\tREGION\tBENCHMARK\tMEMORY\tINVOCATIONS\tMEDIAN")
+		for _, m := range medians {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%d\t%.3f\n", m.Period, m.Provider, m.Region, m.Benchmark, m.MemorySize, m.Count, m.Median)
+		}
+	default:
+		fmt.Println("Unknown report:", fs.Arg(0))
+		os.Exit(1)
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(report)
+		return
+	}
+	w.Flush()
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"ClassiFaaS/internal/config"
+	"ClassiFaaS/internal/distributed"
+	"ClassiFaaS/internal/loadgenerator"
+	"ClassiFaaS/internal/utils"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// runCoordinator distributes a benchmark run between agents and merges their results.
+func runCoordinator(args []string) {
+	fs := flag.NewFlagSet("coordinator", flag.ExitOnError)
+	configPath := fs.String("config", "configs/generated.yaml", "Path to the benchmark configuration YAML file")
+	listen := fs.String("listen", ":7070", "Address the coordinator listens on")
+	agents := fs.Int("agents", 1, "Number of agents taking part in the run")
+	startDelay := fs.Duration("start-delay", 10*time.Second, "Delay between the last agent registering and the synchronized start")
+	agentTimeout := fs.Duration("agent-timeout", time.Minute, "Fail the run if an agent is not heard of for this long (0 waits forever)")
+	fs.Parse(args)
+
+	ep := utils.NewEventLogger()
+	defer ep.Close()
+
+	loadPlugins(ep, *configPath)
+	cfg, err := config.LoadBenchmarkConfig(*configPath)
+	if err != nil {
+		ep.SendEvent(utils.SeverityError, "load_config", fmt.Sprintf("Failed to load config: %v", err))
+		return
+	}
+
+	coordinator, err := distributed.NewCoordinator(*cfg, *agents, *startDelay, *agentTimeout, ep)
+	if err != nil {
+		ep.SendEvent(utils.SeverityError, "init_coordinator", fmt.Sprintf("Failed to initialize coordinator: %v", err))
+		return
+	}
+
+	ctx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	runFolder, err := coordinator.Run(ctx, *listen)
+	if err != nil {
+		ep.SendEvent(utils.SeverityError, "coordinator", err.Error())
+		if runFolder != "" {
+			ep.SendEvent(utils.SeverityInfo, "coordinator", "Results received so far written to "+runFolder)
+		}
+		// deferred calls do not run on exit
+		stopSignals()
+		ep.Close()
+		os.Exit(1)
+	}
+	ep.SendEvent(utils.SeverityInfo, "coordinator", "Merged results written to "+runFolder)
+
+	if err := storeRun(cfg.WorkloadParameters.Store, runFolder, ep); err != nil {
+		ep.SendEvent(utils.SeverityError, "store_run", err.Error())
+	}
+}
+
+// runAgent registers with a coordinator, executes the assigned slice of the
+// workload and streams the results back.
+func runAgent(args []string) {
+	hostname, _ := os.Hostname()
+
+	fs := flag.NewFlagSet("agent", flag.ExitOnError)
+	coordinatorURL := fs.String("coordinator", "http://localhost:7070", "URL of the coordinator")
+	id := fs.String("id", fmt.Sprintf("%s-%d", hostname, os.Getpid()), "Unique id of this agent")
+	metricsAddr := fs.String("metrics-addr", "", "Serve live metrics on this address, e.g. :9090 (disabled if empty)")
+	logLevel := fs.String("log-level", "info", "Minimum severity of events shown on the console: info, warning or error")
+	backpressure := fs.String("log-backpressure", "block", "What to do with events while the event buffer is full: block, drop or spill (to a temporary file)")
+	fs.Parse(args)
+
+	ep, live, stop := setupOutput(false, *logLevel, *backpressure, *metricsAddr, "")
+	defer stop()
+
+	ctx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	agent := distributed.NewAgent(*coordinatorURL, *id)
+	if err := agent.Register(ctx); err != nil {
+		ep.SendEvent(utils.SeverityError, "agent_register", err.Error())
+		return
+	}
+	ep.SendEvent(utils.SeverityInfo, "agent_register", fmt.Sprintf("Agent %s registered with %s", agent.ID, *coordinatorURL))
+
+	keepAliveCtx, stopKeepAlive := context.WithCancel(ctx)
+	defer stopKeepAlive()
+	go agent.KeepAlive(keepAliveCtx)
+
+	assignment, err := agent.WaitForAssignment(ctx)
+	if err != nil {
+		ep.SendEvent(utils.SeverityError, "agent_assignment", err.Error())
+		return
+	}
+
+	ep.SendEvent(utils.SeverityInfo, "agent_assignment",
+		fmt.Sprintf("Assigned %d requests with %d parallel requests per function, starting at %s",
+			assignment.Config.WorkloadParameters.TotalRequests, assignment.Config.WorkloadParameters.ParallelRequests,
+			assignment.StartAt.Format(time.RFC3339Nano)))
+
+	select {
+	case <-ctx.Done():
+		return
+	case <-time.After(time.Until(assignment.StartAt)):
+	}
+
+	if live != nil {
+		live.StartRun("")
+	}
+	functions, err := executeBenchmark(&assignment.Config, ep, live, func(i int, wp *config.WorkloadParameters, fn config.BenchmarkFunctionConfig) (*loadgenerator.LoadGenerator, error) {
+		archiver, err := utils.NewArchiveClient(agent.ResultStream(i, ep), "")
+		if err != nil {
+			return nil, err
+		}
+		return loadgenerator.NewLoadGeneratorWithArchive(wp, fn, archiver), nil
+	})
+
+	report := distributed.DoneReport{Functions: functions}
+	if err != nil {
+		report.Error = err.Error()
+		ep.SendEvent(utils.SeverityError, "agent_run", err.Error())
+	}
+	if err := agent.ReportDone(report); err != nil {
+		ep.SendEvent(utils.SeverityError, "agent_done", fmt.Sprintf("Failed to report completion: %v", err))
+		return
+	}
+	ep.SendEvent(utils.SeverityInfo, "agent_done", "Reported completion to coordinator")
+}
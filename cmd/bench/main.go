@@ -1,81 +1,114 @@
 package main
 
 import (
-	"ClassiFaaS/internal/auth"
-	"ClassiFaaS/internal/config"
-	"ClassiFaaS/internal/loadgenerator"
+	"ClassiFaaS/internal/dashboard"
+	"ClassiFaaS/internal/deployment"
+	"ClassiFaaS/internal/metrics"
 	"ClassiFaaS/internal/utils"
 	"flag"
 	"fmt"
-	"math/rand"
-	"strings"
-	"time"
+	"os"
+	"os/signal"
+	"syscall"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "schedule":
+			runSchedule(os.Args[2:])
+			return
+		case "coordinator":
+			runCoordinator(os.Args[2:])
+			return
+		case "agent":
+			runAgent(os.Args[2:])
+			return
+		}
+	}
+
 	configPath := flag.String("config", "configs/generated.yaml", "Path to the benchmark configuration YAML file")
+	metricsAddr := flag.String("metrics-addr", "", "Serve live metrics on this address, e.g. :9090 (disabled if empty)")
+	tui := flag.Bool("tui", false, "Show a live terminal dashboard instead of the event log (only if stdout is a terminal)")
+	logLevel := flag.String("log-level", "info", "Minimum severity of events shown on the console: info, warning or error")
+	backpressure := flag.String("log-backpressure", "block", "What to do with events while the event buffer is full: block, drop or spill (to a temporary file)")
 	flag.Parse()
 
-	cfg, err := config.LoadBenchmarkConfig(*configPath)
+	ep, live, stop := setupOutput(*tui, *logLevel, *backpressure, *metricsAddr, "ClassiFaaS benchmark "+*configPath)
+
+	// the dashboard must restore the terminal when the run is interrupted
+	if *tui {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			<-signals
+			stop()
+			os.Exit(130)
+		}()
+	}
+
+	_, err := runBenchmark(*configPath, ep, live)
+	stop()
 	if err != nil {
 		panic(err)
 	}
+}
 
-	ep := utils.NewEventLogger()
-	defer ep.Close()
-
-	// Group functions by provider and region
-	loadGenerators := make(map[string]loadgenerator.LoadGenerator)
-	WorkloadParameters := cfg.WorkloadParameters
-
-	for _, fn := range cfg.Functions {
-
-		if fn.Provider == "gcp" {
-			gcpToken, err := auth.GetGoogleIdentityToken(fn.URL)
-			fn.Auth.Value = fmt.Sprintf("Bearer %s", gcpToken)
-
-			if err != nil {
-				panic(err)
-			}
-		}
+// setupOutput creates the event publisher of a command and, if needed, the
+// live metrics registry. With tui set and stdout being a terminal, events
+// are shown on the dashboard; otherwise they are logged to stdout if at
+// least logLevel. The returned function stops the publisher.
+func setupOutput(tui bool, logLevel, backpressure, metricsAddr, title string) (utils.EventPublisher, *metrics.Registry, func()) {
+	minSeverity, err := utils.ParseSeverity(logLevel)
+	if err != nil {
+		fmt.Println("Invalid --log-level:", err)
+		os.Exit(1)
+	}
+	policy, err := utils.ParseBackpressure(backpressure)
+	if err != nil {
+		fmt.Println("Invalid --log-backpressure:", err)
+		os.Exit(1)
+	}
+	ep, err := utils.NewEventLoggerWithOptions(utils.EventLoggerOptions{Backpressure: policy})
+	if err != nil {
+		fmt.Println("Failed to start event logger:", err)
+		os.Exit(1)
+	}
 
-		name := fmt.Sprintf("%s-%s-%s-%d-%d", fn.Provider, fn.Region, fn.Name, fn.MemSize, rand.Intn(1000))
+	useDashboard := tui && dashboard.IsTerminal(os.Stdout)
+	if tui && !useDashboard {
+		fmt.Println("stdout is not a terminal, falling back to the event log")
+	}
 
-		ep.SendEvent(utils.SeverityInfo, "executor_setup", "Setting up executor for "+name)
-		if strings.Contains(fn.Provider, "azure") && WorkloadParameters.ParallelRequests > 300 {
-			ep.SendEvent(utils.SeverityWarning, "azure_limitations", "Azure can´t handle more than 300 parallel requests. Limiting to 300.")
-			WorkloadParameters.ParallelRequests = 300
-		}
+	var live *metrics.Registry
+	if metricsAddr != "" || useDashboard {
+		live = metrics.NewRegistry()
+	}
 
-		lgen, err := loadgenerator.NewLoadGenerator(&WorkloadParameters, fn)
-		if err != nil {
-			panic(err)
+	var stop func()
+	if useDashboard {
+		dash := dashboard.New(os.Stdout, live, title)
+		dash.Start()
+		ep.AddSink(dash, minSeverity)
+		stop = func() {
+			// deliver pending events before the dashboard prints the final state
+			ep.Close()
+			dash.Stop()
 		}
-		loadGenerators[name] = *lgen
-
+	} else {
+		ep.AddSink(utils.NewConsoleSink(os.Stdout), minSeverity)
+		stop = ep.Close
 	}
 
-	// setup benchmark timeline
-	benchTimeLine := utils.NewTimeline("Benchmark Timeline", utils.RunParallel)
-	for _, e := range loadGenerators {
-		benchTimeLine.Step(e.Run)
+	if metricsAddr != "" {
+		live.Serve(metricsAddr, ep)
 	}
+	return ep, live, stop
+}
 
-	// periodic progress update
-	go func() {
-		ticker := time.NewTicker(10 * time.Second)
-
-		for range ticker.C {
-			var progressUpdate string
-			for name, e := range loadGenerators {
-				total, remaining := e.GetQueueState()
-				progressUpdate += fmt.Sprintf("%s: %d/%d tasks started.", name, total-remaining, total)
-			}
-
-			ep.SendEvent(utils.SeverityInfo, "progress_update", progressUpdate)
-		}
-	}()
-
-	// run the benchmark
-	benchTimeLine.Run(ep)
+// loadPlugins loads the deployer plugins if the benchmark config at path uses
+// a provider that is not built in. Functions of plugin providers pass the
+// config validation only once their plugins are loaded.
+func loadPlugins(ep utils.EventPublisher, path string) {
+	deployment.LoadPlugins(ep, deployment.ConfiguredProviders(path))
 }
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"ClassiFaaS/internal/auth"
+	"ClassiFaaS/internal/config"
+	"ClassiFaaS/internal/loadgenerator"
+	"ClassiFaaS/internal/metrics"
+	"ClassiFaaS/internal/results"
+	"ClassiFaaS/internal/store"
+	"ClassiFaaS/internal/tracing"
+	"ClassiFaaS/internal/utils"
+	"context"
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// eventLogFile is the file in the run folder events are logged to as JSON lines.
+const eventLogFile = "events.jsonl"
+
+// loadGeneratorFactory creates the load generator for the i-th function of a benchmark config.
+type loadGeneratorFactory func(i int, wp *config.WorkloadParameters, fn config.BenchmarkFunctionConfig) (*loadgenerator.LoadGenerator, error)
+
+// runBenchmark executes a single benchmark run for the config at configPath
+// and returns the folder the results were written to. Requests are reported
+// to live unless it is nil.
+func runBenchmark(configPath string, ep utils.EventPublisher, live *metrics.Registry) (string, error) {
+	loadPlugins(ep, configPath)
+	ep.SendEvent(utils.SeverityInfo, "load_config", "Validating functions of "+configPath)
+	cfg, err := config.LoadBenchmarkConfig(configPath)
+	if err != nil {
+		return "", err
+	}
+
+	runStart := time.Now()
+	runFolder := loadgenerator.RunFolder(cfg.WorkloadParameters.ResultFolder, runStart)
+	if live != nil {
+		live.StartRun(runFolder)
+	}
+
+	detach, err := attachRunLogs(ep, cfg.WorkloadParameters.Logging, runFolder)
+	if err != nil {
+		return runFolder, err
+	}
+	defer detach()
+
+	functions, err := executeBenchmark(cfg, ep, live, func(_ int, wp *config.WorkloadParameters, fn config.BenchmarkFunctionConfig) (*loadgenerator.LoadGenerator, error) {
+		return loadgenerator.NewLoadGenerator(wp, fn, runFolder)
+	})
+	if err != nil {
+		return runFolder, err
+	}
+
+	summary := loadgenerator.RunSummary{Start: runStart, End: time.Now(), Functions: functions}
+	if err := summary.WriteToFile(runFolder); err != nil {
+		return runFolder, err
+	}
+	ep.SendEvent(utils.SeverityInfo, "run_summary", "Wrote run summary to "+runFolder)
+
+	if err := storeRun(cfg.WorkloadParameters.Store, runFolder, ep); err != nil {
+		return runFolder, err
+	}
+
+	return runFolder, nil
+}
+
+// attachRunLogs adds the event log sinks configured per run, by default
+// events.jsonl in the run folder. The returned function detaches them.
+func attachRunLogs(ep utils.EventPublisher, cfg *config.LoggingConfig, runFolder string) (func(), error) {
+	if cfg == nil {
+		cfg = &config.LoggingConfig{File: "info", Syslog: "off"}
+	}
+
+	var removers []func() error
+	detach := func() {
+		for _, remove := range removers {
+			remove()
+		}
+	}
+
+	if cfg.File != "off" {
+		sink, err := utils.NewJSONFileSink(filepath.Join(runFolder, eventLogFile))
+		if err != nil {
+			return nil, err
+		}
+		removers = append(removers, ep.AddSink(sink, utils.Severity(cfg.File)))
+	}
+	if cfg.Syslog != "off" {
+		sink, err := utils.NewSyslogSink("classifaas")
+		if err != nil {
+			detach()
+			return nil, err
+		}
+		removers = append(removers, ep.AddSink(sink, utils.Severity(cfg.Syslog)))
+	}
+	return detach, nil
+}
+
+// storeRun ingests a finished run into the results store, if one is configured.
+func storeRun(path, runFolder string, ep utils.EventPublisher) error {
+	if path == "" {
+		return nil
+	}
+
+	run, err := results.LoadRun(runFolder)
+	if err != nil {
+		return err
+	}
+	s, err := store.Open(path)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	result, err := s.Ingest(run)
+	if err != nil {
+		return fmt.Errorf("failed to ingest run into %s: %v", path, err)
+	}
+	ep.SendEvent(utils.SeverityInfo, "store_run", fmt.Sprintf("Ingested %d invocations into %s", result.Inserted, path))
+	return nil
+}
+
+// executeBenchmark runs a load generator per configured function in parallel
+// and returns their summaries sorted by function name. Requests are reported
+// to live unless it is nil.
+func executeBenchmark(cfg *config.BenchmarkConfig, ep utils.EventPublisher, live *metrics.Registry, newLoadGenerator loadGeneratorFactory) ([]loadgenerator.FunctionSummary, error) {
+	// Group functions by provider and region
+	loadGenerators := make(map[string]*loadgenerator.LoadGenerator)
+	WorkloadParameters := cfg.WorkloadParameters
+
+	var schedules map[string][]loadgenerator.ScheduledRequest
+	if WorkloadParameters.Trace != nil {
+		var err error
+		schedules, err = loadgenerator.LoadTrace(*WorkloadParameters.Trace, cfg.Functions)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if WorkloadParameters.Pattern != nil {
+		pattern := loadgenerator.GeneratePattern(*WorkloadParameters.Pattern)
+		schedules = make(map[string][]loadgenerator.ScheduledRequest)
+		for _, fn := range cfg.Functions {
+			schedules[fn.Key()] = pattern
+		}
+	}
+
+	var tracer trace.Tracer
+	if WorkloadParameters.Tracing != nil {
+		provider, err := tracing.NewProvider(context.Background(), *WorkloadParameters.Tracing)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			if err := provider.Shutdown(ctx); err != nil {
+				ep.SendEvent(utils.SeverityWarning, "tracing", fmt.Sprintf("Failed to flush spans: %v", err))
+			}
+		}()
+		tracer = provider.Tracer()
+	}
+
+	for i, fn := range cfg.Functions {
+
+		if fn.Provider == "gcp" {
+			gcpToken, err := auth.GetGoogleIdentityToken(fn.URL)
+			if err != nil {
+				return nil, err
+			}
+			fn.Auth.Value = fmt.Sprintf("Bearer %s", gcpToken)
+		}
+
+		name := fmt.Sprintf("%s-%s-%s-%d-%d", fn.Provider, fn.Region, fn.Name, fn.MemSize, rand.Intn(1000))
+
+		scope := utils.Scope{Provider: fn.Provider, Region: fn.Region, Function: fn.Name}
+		ep.SendScopedEvent(scope, utils.SeverityInfo, "executor_setup", "Setting up executor for "+name)
+		if strings.Contains(fn.Provider, "azure") && WorkloadParameters.ParallelRequests > 300 {
+			ep.SendScopedEvent(scope, utils.SeverityWarning, "azure_limitations", "Azure can´t handle more than 300 parallel requests. Limiting to 300.")
+			WorkloadParameters.ParallelRequests = 300
+		}
+
+		lgen, err := newLoadGenerator(i, &WorkloadParameters, fn)
+		if err != nil {
+			return nil, err
+		}
+		if schedules != nil {
+			schedule := schedules[fn.Key()]
+			if len(schedule) == 0 {
+				ep.SendScopedEvent(scope, utils.SeverityWarning, "scheduled_workload", "No scheduled requests")
+			}
+			lgen.SetSchedule(schedule)
+		}
+		if tracer != nil {
+			lgen.SetTracer(tracer)
+		}
+		if live != nil {
+			lgen.SetMetrics(live.Function(fn.Name, fn.Provider, fn.Region, fn.MemSize))
+		}
+		loadGenerators[name] = lgen
+
+	}
+
+	// setup benchmark timeline
+	benchTimeLine := utils.NewTimeline("Benchmark Timeline", utils.RunParallel)
+	for name, e := range loadGenerators {
+		benchTimeLine.NamedStep(name, e.Run)
+	}
+
+	// periodic progress update
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(10 * time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			var progressUpdate string
+			for name, e := range loadGenerators {
+				total, remaining := e.GetQueueState()
+				progressUpdate += fmt.Sprintf("%s: %d/%d tasks started.", name, total-remaining, total)
+			}
+
+			ep.SendEvent(utils.SeverityInfo, "progress_update", progressUpdate)
+		}
+	}()
+
+	// run the benchmark
+	if err := benchTimeLine.Run(ep); err != nil {
+		return nil, err
+	}
+
+	var functions []loadgenerator.FunctionSummary
+	for _, e := range loadGenerators {
+		functions = append(functions, e.Summary())
+	}
+	sort.Slice(functions, func(i, j int) bool {
+		return functions[i].Function < functions[j].Function
+	})
+	return functions, nil
+}
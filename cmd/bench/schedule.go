@@ -0,0 +1,60 @@
+package main
+
+import (
+	"ClassiFaaS/internal/config"
+	"ClassiFaaS/internal/scheduler"
+	"ClassiFaaS/internal/utils"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+)
+
+// runSchedule runs a benchmark campaign until it completes or is interrupted.
+func runSchedule(args []string) {
+	fs := flag.NewFlagSet("schedule", flag.ExitOnError)
+	campaignPath := fs.String("campaign", "configs/campaign.yaml", "Path to the campaign YAML file")
+	metricsAddr := fs.String("metrics-addr", "", "Serve live metrics on this address, e.g. :9090 (disabled if empty)")
+	tui := fs.Bool("tui", false, "Show a live terminal dashboard instead of the event log (only if stdout is a terminal)")
+	logLevel := fs.String("log-level", "info", "Minimum severity of events shown on the console: info, warning or error")
+	backpressure := fs.String("log-backpressure", "block", "What to do with events while the event buffer is full: block, drop or spill (to a temporary file)")
+	fs.Parse(args)
+
+	ep, live, stop := setupOutput(*tui, *logLevel, *backpressure, *metricsAddr, "ClassiFaaS campaign "+*campaignPath)
+	defer stop()
+
+	cfg, err := config.LoadCampaignConfig(*campaignPath)
+	if err != nil {
+		ep.SendEvent(utils.SeverityError, "load_campaign", fmt.Sprintf("Failed to load campaign: %v", err))
+		return
+	}
+
+	indexPath := cfg.Index
+	if indexPath == "" {
+		loadPlugins(ep, cfg.Config)
+		benchCfg, err := config.LoadBenchmarkConfig(cfg.Config)
+		if err != nil {
+			ep.SendEvent(utils.SeverityError, "load_config", fmt.Sprintf("Failed to load config: %v", err))
+			return
+		}
+		indexPath = filepath.Join(benchCfg.WorkloadParameters.ResultFolder, fmt.Sprintf("campaign-%s.jsonl", cfg.Name))
+	}
+
+	campaign, err := scheduler.NewCampaign(*cfg, indexPath, func(ep utils.EventPublisher) (string, error) {
+		return runBenchmark(cfg.Config, ep, live)
+	})
+	if err != nil {
+		ep.SendEvent(utils.SeverityError, "init_campaign", fmt.Sprintf("Failed to initialize campaign: %v", err))
+		return
+	}
+
+	ctx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	if err := campaign.Run(ctx, ep); err != nil {
+		ep.SendEvent(utils.SeverityError, "campaign", err.Error())
+	}
+}
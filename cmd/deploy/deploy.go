@@ -4,9 +4,32 @@ import (
 	"ClassiFaaS/internal/config"
 	"ClassiFaaS/internal/deployment"
 	"ClassiFaaS/internal/utils"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
 )
 
-func runDeploy() {
+func runDeploy(args []string) {
+	fs := flag.NewFlagSet("deploy", flag.ExitOnError)
+	plan := fs.Bool("plan", false, "Print the functions deploy would create and update without deploying")
+	refresh := fs.Bool("refresh", false, "With --plan, list the deployed functions from the providers instead of the state file")
+	skipReady := fs.Bool("skip-ready", false, "Do not wait for the deployed functions to respond")
+	selector := selectorFlags(fs)
+	fs.Parse(args)
+
+	sel, err := selector()
+	if err != nil {
+		panic(err)
+	}
+
+	ep := utils.NewEventLogger()
+	defer ep.Close()
+
+	loadPlugins(ep)
 	cfg, err := config.LoadDeployConfig(configPath)
 	if err != nil {
 		panic(err)
@@ -17,13 +40,59 @@ func runDeploy() {
 		panic(err)
 	}
 
-	ep := utils.NewEventLogger()
-	defer ep.Close()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	err = dplOrchClient.DeployAll(ep)
+	if *plan {
+		p, err := dplOrchClient.Plan(ctx, ep, *refresh, sel)
+		if err != nil {
+			panic(err)
+		}
+		// flush the events of --refresh before printing the plan
+		ep.Close()
+		printPlan(p)
+		return
+	}
+
+	err = dplOrchClient.DeployAll(ctx, ep, sel)
 	if err != nil {
 		panic(err)
 	}
 
 	ep.SendEvent(utils.SeverityInfo, "deploy", "✅ Deployments finished successfully.")
+
+	if *skipReady || cfg.Readiness.Skip {
+		return
+	}
+	if !checkReadiness(ctx, ep, dplOrchClient, sel) {
+		os.Exit(1)
+	}
+}
+
+// printPlan prints the changes of a plan as a table followed by their counts.
+// Orphaned functions are listed, but left to remove.
+func printPlan(p *deployment.Plan) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ACTION\tPROVIDER\tREGION\tBENCHMARK\tMEMORY")
+	for _, c := range p.Changes {
+		if c.Action == deployment.PlanUnchanged {
+			continue
+		}
+		action := string(c.Action)
+		if c.Action == deployment.PlanOrphaned {
+			action += " (run remove)"
+		}
+		memory := "all"
+		if c.Memory > 0 {
+			memory = fmt.Sprintf("%d", c.Memory)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", action, c.Provider, c.Region, c.Benchmark, memory)
+	}
+	w.Flush()
+
+	fmt.Printf("\nPlan: %d to create, %d to update, %d unchanged.\n",
+		p.Count(deployment.PlanCreate), p.Count(deployment.PlanUpdate), p.Count(deployment.PlanUnchanged))
+	if orphaned := p.Count(deployment.PlanOrphaned); orphaned > 0 {
+		fmt.Printf("%d deployed functions are no longer configured and are kept; run remove to delete them.\n", orphaned)
+	}
 }
@@ -5,13 +5,26 @@ import (
 	"ClassiFaaS/internal/deployment"
 	"ClassiFaaS/internal/globals"
 	"ClassiFaaS/internal/utils"
+	"context"
+	"flag"
 	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
 )
 
-func runGenerate() {
+func runGenerate(args []string) {
+	fs := flag.NewFlagSet("generate", flag.ExitOnError)
+	offline := fs.Bool("offline", false, "Generate from the recorded deployment state instead of querying the providers")
+	fs.Parse(args)
+
 	ep := utils.NewEventLogger()
 	defer ep.Close()
 
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	loadPlugins(ep)
 	cfg, err := config.LoadDeployConfig(configPath)
 	if err != nil {
 		ep.SendEvent(utils.SeverityError, "load_config", fmt.Sprintf("Failed to load config: %v", err))
@@ -58,7 +71,12 @@ func runGenerate() {
 	}
 
 	// Generate benchmark functions based on deployed functions
-	benchmarkFunctions, err := dplOrchClient.GenerateBenchmarkFunctions(ep, filterFunction, transformFunction)
+	var benchmarkFunctions []config.BenchmarkFunctionConfig
+	if *offline {
+		benchmarkFunctions, err = dplOrchClient.GenerateBenchmarkFunctionsFromState(ep, filterFunction, transformFunction)
+	} else {
+		benchmarkFunctions, err = dplOrchClient.GenerateBenchmarkFunctions(ctx, ep, filterFunction, transformFunction)
+	}
 	if err != nil {
 		ep.SendEvent(utils.SeverityError, "generate_benchmark_functions", fmt.Sprintf("Failed to generate benchmark functions: %v", err))
 		return
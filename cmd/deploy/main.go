@@ -1,6 +1,8 @@
 package main
 
 import (
+	"ClassiFaaS/internal/deployment"
+	"ClassiFaaS/internal/utils"
 	"fmt"
 	"os"
 )
@@ -9,7 +11,7 @@ const configPath = "configs/deployment.yaml"
 
 func main() {
 	if len(os.Args) < 2 {
-		fmt.Println("Usage: classifaas [deploy|generate]")
+		fmt.Println("Usage: classifaas [deploy [--plan [--refresh]|--skip-ready] [selectors]|ready [selectors]|generate [--offline]|remove [selectors]]")
 		os.Exit(1)
 	}
 
@@ -17,13 +19,22 @@ func main() {
 
 	switch cmd {
 	case "deploy":
-		runDeploy()
+		runDeploy(os.Args[2:])
+	case "ready":
+		runReady(os.Args[2:])
 	case "generate":
-		runGenerate()
+		runGenerate(os.Args[2:])
 	case "remove":
-		runRemove()
+		runRemove(os.Args[2:])
 	default:
 		fmt.Println("Unknown command:", cmd)
 		os.Exit(1)
 	}
 }
+
+// loadPlugins loads the deployer plugins if the config uses a provider that
+// is not built in. It must be called before loading the config, whose
+// validation only knows the providers of loaded plugins.
+func loadPlugins(ep utils.EventPublisher) {
+	deployment.LoadPlugins(ep, deployment.ConfiguredProviders(configPath))
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"ClassiFaaS/internal/config"
+	"ClassiFaaS/internal/deployment"
+	"ClassiFaaS/internal/utils"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+)
+
+func runReady(args []string) {
+	fs := flag.NewFlagSet("ready", flag.ExitOnError)
+	selector := selectorFlags(fs)
+	fs.Parse(args)
+
+	sel, err := selector()
+	if err != nil {
+		panic(err)
+	}
+
+	ep := utils.NewEventLogger()
+	defer ep.Close()
+
+	loadPlugins(ep)
+	cfg, err := config.LoadDeployConfig(configPath)
+	if err != nil {
+		panic(err)
+	}
+
+	dplOrchClient, err := deployment.NewDeployOrchestratorClient(*cfg)
+	if err != nil {
+		panic(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if !checkReadiness(ctx, ep, dplOrchClient, sel) {
+		os.Exit(1)
+	}
+}
+
+// checkReadiness waits for the selected functions to respond, closes ep and
+// prints their readiness. It reports whether all functions are ready.
+func checkReadiness(ctx context.Context, ep utils.EventPublisher, dplOrchClient *deployment.DeployOrechestratorClient, sel deployment.Selector) bool {
+	readiness, err := dplOrchClient.CheckReadiness(ctx, ep, sel)
+	if err != nil {
+		ep.SendEvent(utils.SeverityError, "readiness", fmt.Sprintf("Readiness check failed: %v", err))
+	}
+	// flush the events before printing the table
+	ep.Close()
+	if readiness != nil {
+		printReadiness(readiness)
+	}
+	return err == nil
+}
+
+// printReadiness prints the readiness of every checked function as a table
+// followed by the number of ready functions.
+func printReadiness(r *deployment.Readiness) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROVIDER\tREGION\tBENCHMARK\tMEMORY\tSTATUS\tATTEMPTS\tTIME\tERROR")
+	for _, result := range r.Results {
+		status := "ready"
+		if !result.Ready {
+			status = "not ready"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%d\t%s\t%s\n", result.Provider, result.Region, result.Benchmark, result.Memory,
+			status, result.Attempts, result.Elapsed.Round(time.Second), result.Error)
+	}
+	w.Flush()
+
+	fmt.Printf("\nReady: %d of %d functions.\n", r.ReadyCount(), len(r.Results))
+}
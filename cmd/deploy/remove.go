@@ -4,10 +4,19 @@ import (
 	"ClassiFaaS/internal/config"
 	"ClassiFaaS/internal/deployment"
 	"ClassiFaaS/internal/utils"
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
 )
 
-func runRemove() {
-	cfg, err := config.LoadDeployConfig(configPath)
+func runRemove(args []string) {
+	fs := flag.NewFlagSet("remove", flag.ExitOnError)
+	selector := selectorFlags(fs)
+	fs.Parse(args)
+
+	sel, err := selector()
 	if err != nil {
 		panic(err)
 	}
@@ -15,12 +24,21 @@ func runRemove() {
 	ep := utils.NewEventLogger()
 	defer ep.Close()
 
+	loadPlugins(ep)
+	cfg, err := config.LoadDeployConfig(configPath)
+	if err != nil {
+		panic(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	dplOrchClient, err := deployment.NewDeployOrchestratorClient(*cfg)
 	if err != nil {
 		panic(err)
 	}
 
-	err = dplOrchClient.RemoveAll(ep)
+	err = dplOrchClient.RemoveAll(ctx, ep, sel)
 	if err != nil {
 		panic(err)
 	}
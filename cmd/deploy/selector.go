@@ -0,0 +1,46 @@
+package main
+
+import (
+	"ClassiFaaS/internal/deployment"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// selectorFlags defines the flags restricting deploy and remove to some
+// deployments and functions. The returned function parses them after
+// fs.Parse.
+func selectorFlags(fs *flag.FlagSet) func() (deployment.Selector, error) {
+	providers := fs.String("provider", "", "Comma-separated providers to act on (default: all)")
+	regions := fs.String("region", "", "Comma-separated regions to act on (default: all)")
+	benchmarks := fs.String("benchmark", "", "Comma-separated benchmarks to act on (default: all)")
+	memorySizes := fs.String("memory", "", "Comma-separated memory sizes in MB to act on (default: all)")
+
+	return func() (deployment.Selector, error) {
+		sel := deployment.Selector{
+			Providers: splitList(*providers),
+			Regions:   splitList(*regions),
+		}
+		sel.Benchmarks = splitList(*benchmarks)
+		for _, s := range splitList(*memorySizes) {
+			memory, err := strconv.Atoi(s)
+			if err != nil || memory <= 0 {
+				return deployment.Selector{}, fmt.Errorf("invalid memory size %q", s)
+			}
+			sel.MemorySizes = append(sel.MemorySizes, memory)
+		}
+		return sel, nil
+	}
+}
+
+// splitList splits a comma-separated list, returning nil for an empty list.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
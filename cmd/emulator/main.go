@@ -0,0 +1,29 @@
+package main
+
+import (
+	"ClassiFaaS/internal/emulator"
+	"ClassiFaaS/internal/utils"
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// The emulator hosts the functions deployed to the local provider until it
+// is interrupted. Deployed functions are kept in memory only, so deploy
+// again after restarting it.
+func main() {
+	listen := flag.String("listen", "127.0.0.1:8090", "Address the emulator listens on")
+	flag.Parse()
+
+	ep := utils.NewEventLogger()
+	defer ep.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := emulator.NewServer(ep).Run(ctx, *listen); err != nil {
+		ep.SendEvent(utils.SeverityError, "emulator", err.Error())
+	}
+}
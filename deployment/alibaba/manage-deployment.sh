@@ -1,21 +1,42 @@
 #!/bin/bash
 
+microBenchmarks=("gemm" "sha256" "aesCtr" "gzip" "json")
+instanceMemoryOptions=(128 512 2048)
+
+# the deployment script protocol helpers
+source "$(dirname "${BASH_SOURCE[0]}")/../lib/protocol.sh"
+
+# selected_resources lists the resources of s.yaml matching the selection.
+selected_resources() {
+    for memory in "${instanceMemoryOptions[@]}"; do
+        for benchmark in "${microBenchmarks[@]}"; do
+            selected "$benchmark" "$memory" && echo "b-${benchmark}-${memory}"
+        done
+    done
+}
+
 deploy() {
     local location="$1"
     if [ -z "$location" ]; then
-        echo "Error: region argument is required."
-        exit 1
+        fail "region argument is required."
     fi
 
-    echo "🚀 Deploying functions to Alibaba Cloud region=$location"
+    emit progress "Deploying functions to Alibaba Cloud region=$location"
     rm -rf ./code/shared
     cp -rf ../shared ./code/shared
 
     export REGION="$location"
-    s deploy -y -o raw
+    if [ -n "$BENCHMARKS" ] || [ -n "$MEMORY_SIZES" ]; then
+        for resource in $(selected_resources); do
+            emit progress "Deploying $resource"
+            s "$resource" deploy -y -o raw || { rm -rf ./code/shared; fail "Failed to deploy $resource."; }
+        done
+    else
+        s deploy -y -o raw || { rm -rf ./code/shared; fail "s deploy failed."; }
+    fi
 
     rm -rf ./code/shared
-    echo "✅ Deployment completed for $location."
+    emit result "Deployment completed for $location." ok
 }
 
 get_urls() {
@@ -24,8 +45,7 @@ get_urls() {
 
     info_output=$(s info --silent -o raw 2>/dev/null)
     if [ -z "$info_output" ]; then
-        echo "❌ Could not retrieve function info."
-        exit 1
+        fail "Could not retrieve function info."
     fi
 
 
@@ -40,6 +60,7 @@ get_urls() {
         }
         | select(.url != null)
         | if .benchmark == "sha" then .benchmark = "sha256" else . end
+        | {version: 1, type: "function-deployed", function: .}
         | @json
     ' <<< "$info_output"
 }
@@ -48,14 +69,19 @@ get_urls() {
 remove() {
     local location="$1"
     if [ -z "$location" ]; then
-        echo "Error: region argument is required."
-        exit 1
+        fail "region argument is required."
     fi
 
-    echo "🧹 Removing functions from region=$location ..."
+    emit progress "Removing functions from region=$location"
     export REGION="$location"
-    s remove -y --silent
-    echo "✅ Removal completed for $location."
+    if [ -n "$BENCHMARKS" ] || [ -n "$MEMORY_SIZES" ]; then
+        for resource in $(selected_resources); do
+            s "$resource" remove -y --silent || fail "Failed to remove $resource."
+        done
+    else
+        s remove -y --silent || fail "s remove failed."
+    fi
+    emit result "Removal completed for $location." ok
 
     rm -rf ./code/shared
 }
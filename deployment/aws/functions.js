@@ -0,0 +1,37 @@
+"use strict";
+
+// Generates the functions of serverless.yml. FUNCTIONS lists the names of the
+// functions to deploy; without it, BENCHMARKS and MEMORY_SIZES select them
+// (space-separated, empty means all).
+const benchmarks = { gemm: "gemm", aesCtr: "aesCtr", sha256: "sha", gzip: "gzip", json: "json" };
+const memorySizes = [128, 512, 2048];
+
+const list = (value) => (value || "").split(" ").filter((item) => item !== "");
+
+module.exports = () => {
+  const names = list(process.env.FUNCTIONS);
+  const selectedBenchmarks = list(process.env.BENCHMARKS);
+  const selectedMemorySizes = list(process.env.MEMORY_SIZES).map(Number);
+
+  const functions = {};
+  for (const memory of memorySizes) {
+    for (const [benchmark, prefix] of Object.entries(benchmarks)) {
+      const name = `${prefix}${memory}`;
+      if (names.length > 0) {
+        if (!names.includes(name)) continue;
+      } else if (
+        (selectedBenchmarks.length > 0 && !selectedBenchmarks.includes(benchmark)) ||
+        (selectedMemorySizes.length > 0 && !selectedMemorySizes.includes(memory))
+      ) {
+        continue;
+      }
+
+      functions[name] = {
+        handler: `handler.${benchmark}`,
+        memorySize: memory,
+        events: [{ http: { path: name, method: "get", private: true } }],
+      };
+    }
+  }
+  return functions;
+};
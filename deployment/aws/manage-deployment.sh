@@ -1,15 +1,68 @@
 #!/bin/bash
+
+microBenchmarks=("gemm" "sha256" "aesCtr" "gzip" "json")
+instanceMemoryOptions=(128 512 2048)
+
+# the deployment script protocol helpers
+source "$(dirname "${BASH_SOURCE[0]}")/../lib/protocol.sh"
+
+# selected_functions lists the serverless.yml functions matching the selection.
+selected_functions() {
+    for memory in "${instanceMemoryOptions[@]}"; do
+        for benchmark in "${microBenchmarks[@]}"; do
+            if selected "$benchmark" "$memory"; then
+                if [[ "$benchmark" == "sha256" ]]; then
+                    echo "sha${memory}"
+                else
+                    echo "${benchmark}${memory}"
+                fi
+            fi
+        done
+    done
+}
+
+# deployed_functions lists the functions of the deployed stack, if any. It
+# fails if sls info fails for another reason than a missing stack, so that a
+# failed listing is not mistaken for an empty stack.
+deployed_functions() {
+    local region=$1
+    local output errors status
+    errors=$(mktemp)
+    output=$(sls info --param="region=$region" 2>"$errors")
+    status=$?
+    if [ $status -ne 0 ] && grep -q "does not exist" "$errors" <(echo "$output"); then
+        output=""
+        status=0
+    fi
+    if [ $status -ne 0 ]; then
+        cat "$errors" >&2
+    fi
+    rm -f "$errors"
+    [ $status -eq 0 ] || return $status
+
+    printf '%s' "$output" | grep -Eo "https://[^[:space:]]+" | xargs -r -n1 basename
+}
+
 deploy() {
     local region=$1
 
     if [ -z "$region" ]; then
-        echo "Error: region argument is required."
-        exit 1
+        fail "region argument is required."
     fi
 
-    echo "🚀 Deploying to AWS region=$region..."
-    sls deploy --param="region=$region"
-    echo "✅ Deployment completed for region=$region."
+    local runtime
+    runtime=$(request_field .runtime)
+
+    # the stack only keeps the functions it is deployed with, so previously
+    # deployed functions are deployed again
+    local deployed
+    deployed=$(deployed_functions "$region") || fail "Could not list the deployed functions of region=$region."
+    FUNCTIONS=$( (selected_functions; printf '%s\n' "$deployed") | sed '/^$/d' | sort -u | tr '\n' ' ')
+    export FUNCTIONS
+
+    emit progress "Deploying to AWS region=$region: $FUNCTIONS"
+    sls deploy --param="region=$region" ${runtime:+--param="runtime=$runtime"} || fail "sls deploy failed."
+    emit result "Deployment completed for region=$region." ok
 }
 
 get_urls() {
@@ -17,16 +70,14 @@ get_urls() {
 
     info_output=$(sls info --param="region=$region" 2>/dev/null || true)
     if [ -z "$info_output" ]; then
-        echo "❌ Could not retrieve sls info output."
-        exit 1
+        fail "Could not retrieve sls info output."
     fi
 
     api_key=$(echo "$info_output" | awk '/apiKey:/ {print $2; exit}')
     endpoints=$(echo "$info_output" | grep -Eo "https://[^[:space:]]+")
 
     if [ -z "$endpoints" ]; then
-        echo "⚠️  No endpoints found in sls info output."
-        exit 1
+        fail "No endpoints found in sls info output."
     fi
 
     while IFS= read -r url; do
@@ -37,7 +88,7 @@ get_urls() {
             benchmark="sha256"
         fi
 
-        echo "{\"url\":\"$url\",\"auth\":\"$api_key\",\"memory\":$suffix,\"region\":\"$region\",\"benchmark\":\"$benchmark\"}"
+        emit_function "{\"url\":\"$url\",\"auth\":\"$api_key\",\"memory\":$suffix,\"region\":\"$region\",\"benchmark\":\"$benchmark\"}"
     done <<< "$endpoints"
 }
 
@@ -45,13 +96,29 @@ get_urls() {
 remove() {
     local region=$1
     if [ -z "$region" ]; then
-        echo "Error: region argument is required."
-        exit 1
+        fail "region argument is required."
+    fi
+
+    if [ -n "$BENCHMARKS" ] || [ -n "$MEMORY_SIZES" ]; then
+        # keep the stack with the functions that were not selected; the
+        # stack is only removed once it is known that none are left
+        local deployed
+        deployed=$(deployed_functions "$region") || fail "Could not list the deployed functions of region=$region."
+        FUNCTIONS=$(printf '%s' "$deployed" | grep -vxF -f <(selected_functions) | tr '\n' ' ')
+        export FUNCTIONS
+        if [ -n "$FUNCTIONS" ]; then
+            emit progress "Redeploying AWS region=$region without the selected functions: $FUNCTIONS"
+            local runtime
+            runtime=$(request_field .runtime)
+            sls deploy --param="region=$region" ${runtime:+--param="runtime=$runtime"} || fail "sls deploy failed."
+            emit result "Removal completed for region=$region." ok
+            return
+        fi
     fi
 
-    echo "🧹 Removing AWS deployment for region: $region"
-    sls remove --param="region=$region"
-    echo "✅ Removal completed for region=$region."
+    emit progress "Removing AWS deployment for region: $region"
+    sls remove --param="region=$region" || fail "sls remove failed."
+    emit result "Removal completed for region=$region." ok
 }
 
 # Entrypoint
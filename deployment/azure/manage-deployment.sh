@@ -3,6 +3,9 @@ microBenchmarks=("gemm" "sha256" "aesCtr" "gzip" "json")
 instanceMemoryOptions=(512 2048)
 functionRoot="src/functions"
 
+# the deployment script protocol helpers
+source "$(dirname "${BASH_SOURCE[0]}")/../lib/protocol.sh"
+
 deploy_function_app() {
     local appName=$1
     local instanceMemory=$2
@@ -47,8 +50,7 @@ deploy() {
 
 
     if ! az functionapp list-flexconsumption-locations --query "[].name" -o tsv | grep -qx "$location"; then
-        echo "❌ '$location' is not a valid Azure Functions location."
-        exit 1
+        fail "'$location' is not a valid Azure Functions location."
     fi
 
     local resourceGroup="b-rg-${location}"
@@ -56,10 +58,16 @@ deploy() {
     local randomIdentifier=$((RANDOM))$((RANDOM))
     local storage="storage${randomIdentifier}"
 
-    echo "🛠️ Creating resource group '$resourceGroup'..."
-    az group create --name "$resourceGroup" --location "$location" --output none
+    local tags tagArgs=()
+    mapfile -t tags < <(request_field '.tags | to_entries? | .[] | "\(.key)=\(.value)"')
+    if [ "${#tags[@]}" -gt 0 ]; then
+        tagArgs=(--tags "${tags[@]}")
+    fi
+
+    emit progress "Creating resource group '$resourceGroup'"
+    az group create --name "$resourceGroup" --location "$location" "${tagArgs[@]}" --output none
 
-    echo "📦 Creating storage account '$storage'..."
+    emit progress "Creating storage account '$storage'"
     az storage account create \
         --name "$storage" \
         --location "$location" \
@@ -79,9 +87,10 @@ deploy() {
     for benchmark in "${microBenchmarks[@]}"; do
         jq '.main = "src/functions/'"$benchmark"'.js"' package.json > package.tmp && mv package.tmp package.json
 
-        echo "🚀 Deploying $benchmark ..."
+        emit progress "Deploying $benchmark"
         pids=()
         for memory in "${instanceMemoryOptions[@]}"; do
+            selected "$benchmark" "$memory" || continue
             appName="${functionAppPrefix}-${benchmark}-${memory}mb"
             (
                 deploy_function_app "$appName" "$memory" "$location" "$storage"
@@ -101,19 +110,17 @@ deploy() {
     rm -rf ./src/shared
 
     if [ "$failed" -ne 0 ]; then
-        echo "❌ One or more functions failed to deploy."
-        exit 1
+        fail "One or more functions failed to deploy."
     fi
 
-    echo "✅ Deployment completed for region=$location and memories: ${memories[*]}"
+    emit result "Deployment completed for region=$location" ok
 }
 
 get_urls() {
     local location=$1
 
     if ! az functionapp list-flexconsumption-locations --query "[].name" -o tsv | grep -qx "$location"; then
-        echo "❌ '$location' is not a valid Azure Functions location."
-        exit 1
+        fail "'$location' is not a valid Azure Functions location."
     fi
 
 
@@ -132,7 +139,7 @@ get_urls() {
                 --output tsv 2>/dev/null)
 
             if [ -z "$funcUrl" ]; then
-                echo "⚠️  Could not retrieve URL for '$benchmark' in '$appName'"
+                emit warning "Could not retrieve URL for '$benchmark' in '$appName'"
                 continue
             fi
 
@@ -143,7 +150,7 @@ get_urls() {
                 --query "default" \
                 --output tsv 2>/dev/null)
 
-            echo "{\"benchmark\":\"$benchmark\",\"url\":\"$funcUrl\",\"auth\":\"$code\",\"memory\":$memory,\"region\":\"$location\"}"
+            emit_function "{\"benchmark\":\"$benchmark\",\"url\":\"$funcUrl\",\"auth\":\"$code\",\"memory\":$memory,\"region\":\"$location\"}"
         done
     done
 }
@@ -151,10 +158,28 @@ get_urls() {
 remove() {
     local location=$1
     local resourceGroup="b-rg-${location}"
+    local functionAppPrefix="b-fa-${location}"
+
+    if [ -n "$BENCHMARKS" ] || [ -n "$MEMORY_SIZES" ]; then
+        local failed=0
+        for memory in "${instanceMemoryOptions[@]}"; do
+            for benchmark in "${microBenchmarks[@]}"; do
+                selected "$benchmark" "$memory" || continue
+                appName="${functionAppPrefix}-${benchmark}-${memory}mb"
+                emit progress "Deleting function app '$appName'"
+                az functionapp delete --name "$appName" --resource-group "$resourceGroup" --output none || failed=1
+            done
+        done
+        if [ "$failed" -ne 0 ]; then
+            fail "One or more function apps failed to delete."
+        fi
+        emit result "Selected function apps deleted from '$resourceGroup'" ok
+        return
+    fi
 
-    echo "🧹 Deleting resource group '$resourceGroup'..."
-    az group delete --name "$resourceGroup" -y
-    echo "✅ Resource group '$resourceGroup' deleted."
+    emit progress "Deleting resource group '$resourceGroup'"
+    az group delete --name "$resourceGroup" -y || fail "Failed to delete resource group '$resourceGroup'."
+    emit result "Resource group '$resourceGroup' deleted." ok
 
     rm -rf ./src/shared
 }
@@ -0,0 +1,84 @@
+"use strict";
+
+// HTTP server running one benchmark per container, for the platforms
+// deploying container images (OpenFaaS, Knative). The benchmark is selected
+// by the BENCHMARK environment variable.
+
+const http = require("http");
+const fs = require("fs");
+const { terminateInstanceAfter } = require("./shared/utils/terminator");
+const Inspector = require("./shared/utils/inspector");
+const uuidv4 = require("uuid/v4");
+
+var invocationCount = 0;
+var instanceId = uuidv4();
+
+const benchmarks = {
+  gemm: {
+    benchmarkFn: require("./shared/benchmarks/gemm").runMatrixMultiplicationBenchmark,
+    defaultParam: 100,
+  },
+  sha256: {
+    benchmarkFn: require("./shared/benchmarks/sha256").runSha256Benchmark,
+    defaultParam: 2,
+  },
+  aesCtr: {
+    benchmarkFn: require("./shared/benchmarks/aesCtr").runAesCtrBenchmark,
+    defaultParam: 2,
+  },
+  gzip: {
+    benchmarkFn: require("./shared/benchmarks/gzip").runGzipBenchmark,
+    defaultParam: 2,
+  },
+  json: {
+    benchmarkFn: require("./shared/benchmarks/json").runJsonBenchmark,
+    defaultParam: 500,
+  },
+};
+
+const benchmark = benchmarks[process.env.BENCHMARK];
+if (!benchmark) {
+  console.error(`Unknown BENCHMARK '${process.env.BENCHMARK}'`);
+  process.exit(1);
+}
+
+function extractParameter(url, defaultValue) {
+  const parameter = url.searchParams.get("parameter");
+  if (parameter) return parseInt(parameter, 10);
+  return defaultValue;
+}
+
+const server = http.createServer((req, res) => {
+  const url = new URL(req.url, "http://localhost");
+
+  // health check of the OpenFaaS watchdog and Kubernetes probes
+  if (url.pathname === "/_/health") {
+    res.writeHead(200);
+    res.end("OK");
+    return;
+  }
+
+  const inspector = new Inspector();
+  inspector.inspectAll();
+
+  invocationCount++;
+  terminateInstanceAfter(invocationCount, 4);
+
+  inspector.addAttribute("provider", process.env.PROVIDER || "container");
+  inspector.addAttribute("instanceId", instanceId);
+  inspector.addAttribute("invocationCount", invocationCount);
+
+  const parameter = extractParameter(url, benchmark.defaultParam);
+  const benchMetrics = benchmark.benchmarkFn(parameter);
+
+  inspector.addAttribute("benchmark", benchMetrics);
+  inspector.inspectAllDeltas();
+
+  res.writeHead(200, { "Content-Type": "application/json" });
+  res.end(JSON.stringify(inspector.finish()));
+});
+
+server.listen(parseInt(process.env.PORT || "8080", 10), () => {
+  // readiness marker of the OpenFaaS exec probe
+  fs.writeFileSync("/tmp/.lock", "");
+});
@@ -3,26 +3,35 @@
 microBenchmarks=("gemm" "sha256" "aesCtr" "gzip" "json")
 instanceMemoryOptions=(128 512 2048)
 
+# the deployment script protocol helpers
+source "$(dirname "${BASH_SOURCE[0]}")/../lib/protocol.sh"
+
 deploy() {
     local location=$1
     if ! gcloud functions regions list --format="value(locationId)" | grep -qx "$location"; then
-        echo "Error: '$location' is not a valid GCP Functions region."
-        exit 1
+        fail "'$location' is not a valid GCP Functions region."
     fi
 
 
+    local runtime
+    runtime=$(request_field .runtime)
+    local labels
+    labels=$(request_field '.tags | to_entries? | map("\(.key)=\(.value)") | join(",")')
+
     rm -rf ./shared
     cp -r ../shared ./shared
 
     pids=()
     for memory in "${instanceMemoryOptions[@]}"; do
         for benchmark in "${microBenchmarks[@]}"; do
+            selected "$benchmark" "$memory" || continue
             functionName="b-${benchmark}-${location}-${memory}"
-            echo "→ Deploying $functionName ..."
+            emit progress "Deploying $functionName"
             (
                 gcloud functions deploy "$functionName" \
                     --region="$location" \
-                    --runtime="nodejs20" \
+                    --runtime="${runtime:-nodejs20}" \
+                    ${labels:+--update-labels="$labels"} \
                     --memory="$memory" \
                     --source="." \
                     --entry-point="$benchmark" \
@@ -43,18 +52,16 @@ deploy() {
     rm -rf ./shared
 
     if [ "$failed" -ne 0 ]; then
-        echo "❌ One or more functions failed to deploy."
-        exit 1
+        fail "One or more functions failed to deploy."
     fi
 
-    echo "✅ Deployment completed for region=$location and memories: ${memories[*]}"
+    emit result "Deployment completed for region=$location" ok
 }
 
 get_urls() {
     local location=$1
     if ! gcloud functions regions list --format="value(locationId)" | grep -qx "$location"; then
-        echo "Error: '$location' is not a valid GCP Functions region."
-        exit 1
+        fail "'$location' is not a valid GCP Functions region."
     fi
 
     for memory in "${instanceMemoryOptions[@]}"; do
@@ -62,10 +69,10 @@ get_urls() {
             functionName="b-${benchmark}-${location}-${memory}"
             url=$(gcloud functions describe "$functionName" --region="$location" --format="value(httpsTrigger.url)")
             if [ -z "$url" ]; then
-                echo "⚠️  Could not retrieve URL for function '$functionName'."
+                emit warning "Could not retrieve URL for function '$functionName'."
                 continue
             fi
-            echo "{\"benchmark\":\"$benchmark\",\"url\":\"$url\",\"memory\":$memory,\"region\":\"$location\"}"
+            emit_function "{\"benchmark\":\"$benchmark\",\"url\":\"$url\",\"memory\":$memory,\"region\":\"$location\"}"
         done
     done
 }
@@ -76,8 +83,9 @@ remove() {
     pids=()
     for memory in "${instanceMemoryOptions[@]}"; do
         for benchmark in "${microBenchmarks[@]}"; do
+            selected "$benchmark" "$memory" || continue
             functionName="b-${benchmark}-${location}-${memory}"
-            echo "→ Deleting $functionName ..."
+            emit progress "Deleting $functionName"
             (
                 gcloud functions delete "$functionName" \
                     --region="$location" \
@@ -93,11 +101,10 @@ remove() {
     done
 
     if [ "$failed" -ne 0 ]; then
-        echo "❌ One or more functions failed to delete."
-        exit 1
+        fail "One or more functions failed to delete."
     fi
 
-    echo "✅ Deletion completed for region=$location and memories: ${memories[*]}"
+    emit result "Deletion completed for region=$location" ok
 
     rm -rf ./shared
 }
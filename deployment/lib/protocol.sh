@@ -0,0 +1,46 @@
+# Helpers for manage-deployment.sh scripts speaking the deployment script
+# protocol (see internal/deployment/protocol.go). Source this file, it is
+# not run on its own.
+
+# BENCHMARKS and MEMORY_SIZES restrict deploy and delete to the given
+# space-separated benchmarks and memory sizes; empty means all.
+selected() {
+    local benchmark=$1
+    local memory=$2
+    if [ -n "$BENCHMARKS" ] && [[ " $BENCHMARKS " != *" $benchmark "* ]]; then
+        return 1
+    fi
+    if [ -n "$MEMORY_SIZES" ] && [[ " $MEMORY_SIZES " != *" $memory "* ]]; then
+        return 1
+    fi
+    return 0
+}
+
+# emit prints an event of the deployment script protocol (see
+# internal/deployment/protocol.go): emit <type> <message> [status]
+emit() {
+    local message=${2//\\/\\\\}
+    message=${message//\"/\\\"}
+    local status=""
+    if [ -n "$3" ]; then
+        status=",\"status\":\"$3\""
+    fi
+    echo "{\"version\":1,\"type\":\"$1\"${status},\"message\":\"$message\"}"
+}
+
+# emit_function reports a deployed function given as a JSON object.
+emit_function() {
+    echo "{\"version\":1,\"type\":\"function-deployed\",\"function\":$1}"
+}
+
+# fail reports a failed result and exits.
+fail() {
+    emit result "$1" error
+    exit 1
+}
+
+# request_field reads a field of the JSON request in CLASSIFAAS_REQUEST.
+request_field() {
+    [ -n "$CLASSIFAAS_REQUEST" ] || return 0
+    jq -r "$1 // empty" <<< "$CLASSIFAAS_REQUEST"
+}
@@ -0,0 +1,59 @@
+"use strict";
+
+// OpenWhisk web action running the benchmark given by the action's final
+// "benchmark" parameter. OpenWhisk recycles the action containers itself.
+
+const Inspector = require("./shared/utils/inspector");
+const uuidv4 = require("uuid/v4");
+
+var invocationCount = 0;
+var instanceId = uuidv4();
+
+const benchmarks = {
+  gemm: {
+    benchmarkFn: require("./shared/benchmarks/gemm").runMatrixMultiplicationBenchmark,
+    defaultParam: 100,
+  },
+  sha256: {
+    benchmarkFn: require("./shared/benchmarks/sha256").runSha256Benchmark,
+    defaultParam: 2,
+  },
+  aesCtr: {
+    benchmarkFn: require("./shared/benchmarks/aesCtr").runAesCtrBenchmark,
+    defaultParam: 2,
+  },
+  gzip: {
+    benchmarkFn: require("./shared/benchmarks/gzip").runGzipBenchmark,
+    defaultParam: 2,
+  },
+  json: {
+    benchmarkFn: require("./shared/benchmarks/json").runJsonBenchmark,
+    defaultParam: 500,
+  },
+};
+
+function main(params) {
+  const benchmark = benchmarks[params.benchmark];
+  if (!benchmark) {
+    return { error: `Unknown benchmark '${params.benchmark}'` };
+  }
+
+  const inspector = new Inspector();
+  inspector.inspectAll();
+
+  invocationCount++;
+
+  inspector.addAttribute("provider", "openwhisk");
+  inspector.addAttribute("instanceId", instanceId);
+  inspector.addAttribute("invocationCount", invocationCount);
+
+  const parameter = params.parameter ? parseInt(params.parameter, 10) : benchmark.defaultParam;
+  const benchMetrics = benchmark.benchmarkFn(parameter);
+
+  inspector.addAttribute("benchmark", benchMetrics);
+  inspector.inspectAllDeltas();
+
+  return inspector.finish();
+}
+
+exports.main = main;
@@ -0,0 +1,154 @@
+package analysis
+
+import (
+	"ClassiFaaS/internal/results"
+	"ClassiFaaS/internal/stats"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// CompareOptions configures the statistical comparison of runs.
+type CompareOptions struct {
+	// Metric is MetricLatency, MetricBenchmark or a dotted body path.
+	Metric string
+	// Alpha is the significance level of the tests.
+	Alpha float64
+	// MinEffect is the minimum relative change of the median flagged as a regression.
+	MinEffect float64
+	// Resamples is the number of bootstrap resamples.
+	Resamples int
+	// AgainstFirst compares every run against the first instead of its predecessor.
+	AgainstFirst bool
+}
+
+// Comparison is the result of comparing one function between a baseline and
+// a candidate run.
+type Comparison struct {
+	FunctionKey
+	Baseline  string `json:"baseline"`
+	Candidate string `json:"candidate"`
+
+	BaselineSamples  int     `json:"baselineSamples"`
+	CandidateSamples int     `json:"candidateSamples"`
+	BaselineMedian   float64 `json:"baselineMedian"`
+	CandidateMedian  float64 `json:"candidateMedian"`
+	// MedianDiff is candidate minus baseline median with its bootstrap CI.
+	MedianDiff      stats.Interval `json:"medianDiff"`
+	RelativeChange  float64        `json:"relativeChange"`
+	MannWhitneyP    float64        `json:"mannWhitneyP"`
+	Significant     bool           `json:"significant"`
+	Regression      bool           `json:"regression"`
+	CPUMixChi2      float64        `json:"cpuMixChi2"`
+	CPUMixP         float64        `json:"cpuMixP"`
+	CPUMixShift     bool           `json:"cpuMixShift"`
+	BaselineCPUMix  map[string]int `json:"baselineCpuMix"`
+	CandidateCPUMix map[string]int `json:"candidateCpuMix"`
+}
+
+// Compare aligns the functions of the given runs, which must be ordered
+// chronologically, and compares each run against its predecessor (or the
+// first run). Metrics are assumed to be lower-is-better: a significant
+// increase of the median by at least MinEffect is a regression. The
+// bootstrap is seeded per function, so results are reproducible. It fails if
+// a run holds several archives of the same function.
+func Compare(runs []*results.Run, opts CompareOptions) ([]Comparison, error) {
+	var comparisons []Comparison
+
+	for i := 1; i < len(runs); i++ {
+		baseline := runs[i-1]
+		if opts.AgainstFirst {
+			baseline = runs[0]
+		}
+		candidate := runs[i]
+
+		baselineArchives, err := archivesByKey(baseline)
+		if err != nil {
+			return nil, err
+		}
+		candidateArchives, err := archivesByKey(candidate)
+		if err != nil {
+			return nil, err
+		}
+		for key, candidateArchive := range candidateArchives {
+			baselineArchive, ok := baselineArchives[key]
+			if !ok {
+				continue
+			}
+			comparisons = append(comparisons, compareArchives(key, baseline, candidate, baselineArchive, candidateArchive, opts, keyRand(key)))
+		}
+	}
+
+	sort.SliceStable(comparisons, func(i, j int) bool {
+		a, b := comparisons[i], comparisons[j]
+		if a.Candidate != b.Candidate {
+			return a.Candidate < b.Candidate
+		}
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		if a.Region != b.Region {
+			return a.Region < b.Region
+		}
+		if a.Benchmark != b.Benchmark {
+			return a.Benchmark < b.Benchmark
+		}
+		return a.MemorySize < b.MemorySize
+	})
+	return comparisons, nil
+}
+
+func archivesByKey(run *results.Run) (map[FunctionKey]*results.Archive, error) {
+	archives := make(map[FunctionKey]*results.Archive)
+	for _, archive := range run.Archives {
+		key := KeyOf(archive)
+		if other, exists := archives[key]; exists {
+			return nil, fmt.Errorf("run %s: archives %s and %s hold the same function", run.Folder, other.Path, archive.Path)
+		}
+		archives[key] = archive
+	}
+	return archives, nil
+}
+
+// keyRand returns a random source seeded with the function key, which makes
+// the bootstrap of a function independent of the other functions.
+func keyRand(key FunctionKey) *rand.Rand {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s/%s/%s/%d", key.Provider, key.Region, key.Benchmark, key.MemorySize)
+	return rand.New(rand.NewSource(int64(h.Sum64())))
+}
+
+func compareArchives(key FunctionKey, baseline, candidate *results.Run, a, b *results.Archive, opts CompareOptions, rng *rand.Rand) Comparison {
+	valuesA := MetricValues(a, opts.Metric)
+	valuesB := MetricValues(b, opts.Metric)
+
+	c := Comparison{
+		FunctionKey:      key,
+		Baseline:         baseline.Folder,
+		Candidate:        candidate.Folder,
+		BaselineSamples:  len(valuesA),
+		CandidateSamples: len(valuesB),
+		BaselineCPUMix:   CPUModels(a),
+		CandidateCPUMix:  CPUModels(b),
+	}
+
+	if len(valuesA) > 0 && len(valuesB) > 0 {
+		c.BaselineMedian = stats.Median(valuesA)
+		c.CandidateMedian = stats.Median(valuesB)
+		c.MedianDiff = stats.BootstrapMedianDifference(valuesA, valuesB, 1-opts.Alpha, opts.Resamples, rng)
+		if c.BaselineMedian != 0 {
+			c.RelativeChange = c.MedianDiff.Estimate / math.Abs(c.BaselineMedian)
+		}
+		_, c.MannWhitneyP = stats.MannWhitneyU(valuesA, valuesB)
+
+		ciExcludesZero := c.MedianDiff.Lower > 0 || c.MedianDiff.Upper < 0
+		c.Significant = c.MannWhitneyP < opts.Alpha && ciExcludesZero && math.Abs(c.RelativeChange) >= opts.MinEffect
+		c.Regression = c.Significant && c.RelativeChange > 0
+	}
+
+	c.CPUMixChi2, _, c.CPUMixP = stats.ChiSquareHomogeneity(c.BaselineCPUMix, c.CandidateCPUMix)
+	c.CPUMixShift = c.CPUMixP < opts.Alpha
+	return c
+}
@@ -0,0 +1,182 @@
+package analysis
+
+import (
+	"ClassiFaaS/internal/results"
+	"ClassiFaaS/internal/stats"
+	"ClassiFaaS/internal/utils"
+	"sort"
+	"time"
+)
+
+// LifecycleReport describes instance reuse and container lifecycles of all
+// functions sharing a provider and memory size.
+type LifecycleReport struct {
+	Provider    string `json:"provider"`
+	MemorySize  int    `json:"memorySize"`
+	Functions   int    `json:"functions"`
+	Invocations int    `json:"invocations"`
+	// Instances is the number of distinct containers, identified by the SAAF uuid.
+	Instances              int               `json:"instances"`
+	InvocationsPerInstance stats.Description `json:"invocationsPerInstance"`
+	// InstanceLifetimeSec is the time between the first and last invocation seen per instance.
+	InstanceLifetimeSec stats.Description `json:"instanceLifetimeSec"`
+	// Hosts is the number of distinct host boot times (vmuptime); instances
+	// sharing one are considered co-located.
+	Hosts            int               `json:"hosts"`
+	InstancesPerHost stats.Description `json:"instancesPerHost"`
+	SharedHosts      int               `json:"sharedHosts"`
+	ColdStarts       int               `json:"coldStarts"`
+	ColdLatencyMs    stats.Description `json:"coldLatencyMs"`
+	WarmLatencyMs    stats.Description `json:"warmLatencyMs"`
+}
+
+type lifecycleKey struct {
+	provider   string
+	memorySize int
+}
+
+// instance is the reconstructed timeline of a single container.
+type instance struct {
+	first, last time.Time
+	invocations int
+}
+
+type lifecycleGroup struct {
+	functions   int
+	invocations int
+	coldStarts  int
+	instances   map[string]*instance
+	hosts       map[int64]map[string]bool
+	cold, warm  []float64
+}
+
+// Lifecycle reconstructs per-instance timelines from the archived records of
+// the given runs and reports them per provider and memory size.
+func Lifecycle(runs []*results.Run) []LifecycleReport {
+	groups := make(map[lifecycleKey]*lifecycleGroup)
+
+	for _, run := range runs {
+		for _, archive := range run.Archives {
+			key := lifecycleKey{archive.Provider(), archive.MemorySize()}
+			group, ok := groups[key]
+			if !ok {
+				group = &lifecycleGroup{
+					instances: make(map[string]*instance),
+					hosts:     make(map[int64]map[string]bool),
+				}
+				groups[key] = group
+			}
+			group.functions++
+
+			for i := range archive.Records {
+				group.add(archive.Path, &archive.Records[i])
+			}
+		}
+	}
+
+	var reports []LifecycleReport
+	for key, group := range groups {
+		reports = append(reports, group.report(key))
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		if reports[i].Provider != reports[j].Provider {
+			return reports[i].Provider < reports[j].Provider
+		}
+		return reports[i].MemorySize < reports[j].MemorySize
+	})
+	return reports
+}
+
+func (g *lifecycleGroup) add(archivePath string, record *utils.BenchmarkResponse) {
+	g.invocations++
+
+	cold := false
+	if newContainer, ok := record.LookupFloat("newcontainer"); ok && newContainer == 1 {
+		cold = true
+		g.coldStarts++
+	}
+	if record.Client != nil {
+		if cold {
+			g.cold = append(g.cold, record.Client.LatencyMs)
+		} else {
+			g.warm = append(g.warm, record.Client.LatencyMs)
+		}
+	}
+
+	uuid, ok := record.LookupString("uuid")
+	if !ok {
+		if uuid, ok = record.LookupString("instanceId"); !ok {
+			return
+		}
+	}
+	// container ids are scoped to the function they were observed in
+	id := archivePath + "/" + uuid
+
+	at, ok := invocationTime(record)
+	inst, exists := g.instances[id]
+	if !exists {
+		inst = &instance{first: at, last: at}
+		g.instances[id] = inst
+	}
+	inst.invocations++
+	if ok {
+		if inst.first.IsZero() || at.Before(inst.first) {
+			inst.first = at
+		}
+		if at.After(inst.last) {
+			inst.last = at
+		}
+	}
+
+	if bootTime, ok := record.LookupFloat("vmuptime"); ok {
+		host := int64(bootTime)
+		if g.hosts[host] == nil {
+			g.hosts[host] = make(map[string]bool)
+		}
+		g.hosts[host][id] = true
+	}
+}
+
+// invocationTime returns the client-side start of an invocation, falling back
+// to the function-side start time reported by SAAF.
+func invocationTime(record *utils.BenchmarkResponse) (time.Time, bool) {
+	if record.Client != nil {
+		return record.Client.Start, true
+	}
+	if startMs, ok := record.LookupFloat("startTime"); ok {
+		return time.UnixMilli(int64(startMs)), true
+	}
+	return time.Time{}, false
+}
+
+func (g *lifecycleGroup) report(key lifecycleKey) LifecycleReport {
+	var invocations, lifetimes, perHost []float64
+	for _, inst := range g.instances {
+		invocations = append(invocations, float64(inst.invocations))
+		lifetimes = append(lifetimes, inst.last.Sub(inst.first).Seconds())
+	}
+
+	shared := 0
+	for _, instances := range g.hosts {
+		perHost = append(perHost, float64(len(instances)))
+		if len(instances) > 1 {
+			shared++
+		}
+	}
+
+	return LifecycleReport{
+		Provider:               key.provider,
+		MemorySize:             key.memorySize,
+		Functions:              g.functions,
+		Invocations:            g.invocations,
+		Instances:              len(g.instances),
+		InvocationsPerInstance: stats.Describe(invocations),
+		InstanceLifetimeSec:    stats.Describe(lifetimes),
+		Hosts:                  len(g.hosts),
+		InstancesPerHost:       stats.Describe(perHost),
+		SharedHosts:            shared,
+		ColdStarts:             g.coldStarts,
+		ColdLatencyMs:          stats.Describe(g.cold),
+		WarmLatencyMs:          stats.Describe(g.warm),
+	}
+}
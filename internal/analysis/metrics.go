@@ -0,0 +1,97 @@
+package analysis
+
+import (
+	"ClassiFaaS/internal/results"
+	"ClassiFaaS/internal/utils"
+)
+
+// BenchmarkMetrics maps each benchmark type to the body field holding its
+// primary runtime metric.
+var BenchmarkMetrics = map[string]string{
+	"gemm":   "benchmark.multiplicationTimeMs",
+	"sha256": "benchmark.hashTimeMs",
+	"aesCtr": "benchmark.encryptTimeMs",
+	"gzip":   "benchmark.compressTimeMS",
+	"json":   "benchmark.jsonTimeMs",
+}
+
+// MetricLatency selects the client-side latency instead of a body field.
+const MetricLatency = "latency"
+
+// MetricBenchmark selects the primary metric of each record's benchmark type.
+const MetricBenchmark = "benchmark"
+
+// FunctionKey identifies a benchmarked function across runs.
+type FunctionKey struct {
+	Provider   string `json:"provider"`
+	Region     string `json:"region"`
+	Benchmark  string `json:"benchmark"`
+	MemorySize int    `json:"memorySize"`
+}
+
+// BenchmarkType returns the benchmark type of an archive, taken from its
+// records or, if none carries one, from the function name.
+func BenchmarkType(archive *results.Archive) string {
+	for i := range archive.Records {
+		if benchmark, ok := archive.Records[i].LookupString("benchmark.type"); ok {
+			return benchmark
+		}
+	}
+	return archive.Function()
+}
+
+// KeyOf returns the key aligning an archive with the same function in other runs.
+func KeyOf(archive *results.Archive) FunctionKey {
+	return FunctionKey{
+		Provider:   archive.Provider(),
+		Region:     archive.Region(),
+		Benchmark:  BenchmarkType(archive),
+		MemorySize: archive.MemorySize(),
+	}
+}
+
+// MetricValue extracts a metric from a record: MetricLatency, MetricBenchmark
+// or a dotted body path.
+func MetricValue(record *utils.BenchmarkResponse, metric string) (float64, bool) {
+	switch metric {
+	case MetricLatency:
+		if record.Client == nil {
+			return 0, false
+		}
+		return record.Client.LatencyMs, true
+	case MetricBenchmark:
+		benchmark, ok := record.LookupString("benchmark.type")
+		if !ok {
+			return 0, false
+		}
+		path, ok := BenchmarkMetrics[benchmark]
+		if !ok {
+			return 0, false
+		}
+		return record.LookupFloat(path)
+	default:
+		return record.LookupFloat(metric)
+	}
+}
+
+// MetricValues extracts a metric from all records of an archive.
+func MetricValues(archive *results.Archive, metric string) []float64 {
+	var values []float64
+	for i := range archive.Records {
+		if v, ok := MetricValue(&archive.Records[i], metric); ok {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// CPUModels counts the CPU models (SAAF cpuType) observed in an archive.
+func CPUModels(archive *results.Archive) map[string]int {
+	models := make(map[string]int)
+	for i := range archive.Records {
+		if cpu, ok := archive.Records[i].LookupString("cpuType"); ok {
+			models[cpu]++
+		}
+	}
+	return models
+}
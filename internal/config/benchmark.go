@@ -4,6 +4,7 @@ import (
 	"ClassiFaaS/internal/globals"
 	"fmt"
 	"os"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -18,6 +19,107 @@ type WorkloadParameters struct {
 	TotalRequests     int    `yaml:"totalRequests"`
 	RetriesPerRequest int    `yaml:"retriesPerRequest"`
 	ResultFolder      string `yaml:"resultFolder"`
+
+	// Store optionally names a SQLite database every finished run is ingested into.
+	Store string `yaml:"store,omitempty"`
+
+	// Tracing optionally exports an OpenTelemetry span per invocation.
+	Tracing *TracingConfig `yaml:"tracing,omitempty"`
+
+	// Logging configures the event log sinks of a run.
+	Logging *LoggingConfig `yaml:"logging,omitempty"`
+
+	// StopCriterion optionally ends sampling of a function before TotalRequests
+	// once the chosen metric has been estimated precisely enough.
+	StopCriterion *StopCriterion `yaml:"stopCriterion,omitempty"`
+
+	// Trace optionally replays recorded invocations instead of sending
+	// TotalRequests requests per function.
+	Trace *TraceConfig `yaml:"trace,omitempty"`
+
+	// Pattern optionally fires requests following a burst or periodic load
+	// pattern instead of sending TotalRequests requests per function.
+	Pattern *PatternConfig `yaml:"pattern,omitempty"`
+}
+
+// TracingConfig describes the OTLP/HTTP export of invocation spans.
+type TracingConfig struct {
+	// Endpoint is the collector as host:port or URL. If empty, the standard
+	// OTEL_EXPORTER_OTLP_ENDPOINT environment variables apply.
+	Endpoint string `yaml:"endpoint,omitempty"`
+	// Insecure disables TLS for host:port endpoints.
+	Insecure bool              `yaml:"insecure,omitempty"`
+	Headers  map[string]string `yaml:"headers,omitempty"`
+	// ServiceName defaults to "classifaas-bench".
+	ServiceName string `yaml:"serviceName,omitempty"`
+	// SampleRatio is the fraction of invocations traced. Defaults to 1.
+	SampleRatio *float64 `yaml:"sampleRatio,omitempty"`
+}
+
+// LoggingConfig sets the minimum severity ("info", "warning", "error" or
+// "off") of the event log sinks written per run.
+type LoggingConfig struct {
+	// File is the level of events.jsonl in the run folder. Defaults to "info".
+	File string `yaml:"file,omitempty"`
+	// Syslog is the level of the local syslog daemon. Defaults to "off".
+	Syslog string `yaml:"syslog,omitempty"`
+}
+
+// PatternConfig describes a synthetic open-loop load pattern applied to every
+// function. Exactly one of Burst and Periodic must be set.
+type PatternConfig struct {
+	// Duration is the total length of the pattern.
+	Duration time.Duration    `yaml:"duration"`
+	Burst    *BurstPattern    `yaml:"burst,omitempty"`
+	Periodic *PeriodicPattern `yaml:"periodic,omitempty"`
+}
+
+// BurstPattern fires Size requests spread over Window, every Interval.
+type BurstPattern struct {
+	Size     int           `yaml:"size"`
+	Window   time.Duration `yaml:"window"`
+	Interval time.Duration `yaml:"interval"`
+}
+
+// PeriodicPattern varies the request rate sinusoidally between MinRate and
+// MaxRate requests per second with the given Period, starting at MinRate.
+type PeriodicPattern struct {
+	Period  time.Duration `yaml:"period"`
+	MinRate float64       `yaml:"minRate"`
+	MaxRate float64       `yaml:"maxRate"`
+}
+
+// TraceConfig describes an invocation trace replayed against the configured functions.
+type TraceConfig struct {
+	Path string `yaml:"path"`
+	// Format is "csv" (offset in seconds, function) or "azure" (Azure
+	// Functions public dataset, invocations per function and minute).
+	Format string `yaml:"format"`
+	// TimeScale multiplies all trace offsets, e.g. 0.5 replays twice as fast. Defaults to 1.
+	TimeScale float64 `yaml:"timeScale,omitempty"`
+	// Duration truncates the trace to invocations within this offset (before scaling).
+	Duration time.Duration `yaml:"duration,omitempty"`
+	// Functions maps trace function names onto configured function names,
+	// qualified as name:region if the name is used in several regions.
+	// Without a mapping, trace functions named like a configured function are
+	// replayed against it and all others are assigned round-robin.
+	Functions map[string]string `yaml:"functions,omitempty"`
+}
+
+// StopCriterion describes adaptive sampling: a function is benchmarked until
+// the confidence interval half-width of the metric's statistic falls below
+// RelativeError times the estimate, bounded by MinSamples and MaxSamples.
+type StopCriterion struct {
+	// Metric is either "latency" (client-side latency in ms) or a dotted path
+	// into the response body, e.g. "benchmark.hashTimeMs".
+	Metric string `yaml:"metric"`
+	// Statistic is "mean" or "median".
+	Statistic     string  `yaml:"statistic"`
+	Confidence    float64 `yaml:"confidence"`
+	RelativeError float64 `yaml:"relativeError"`
+	MinSamples    int     `yaml:"minSamples"`
+	// MaxSamples defaults to totalRequests when unset.
+	MaxSamples int `yaml:"maxSamples,omitempty"`
 }
 
 type BenchmarkFunctionConfig struct {
@@ -30,6 +132,11 @@ type BenchmarkFunctionConfig struct {
 	Auth AuthConfig `yaml:"auth"`
 }
 
+// Key identifies the function, whose name is only unique per region.
+func (fn BenchmarkFunctionConfig) Key() string {
+	return fmt.Sprintf("%s:%s", fn.Name, fn.Region)
+}
+
 type AuthConfig struct {
 	Key   string `yaml:"key,omitempty"`
 	Value string `yaml:"value,omitempty"`
@@ -55,7 +162,10 @@ func LoadBenchmarkConfig(path string) (*BenchmarkConfig, error) {
 // Validate checks if the config is valid.
 func (c *BenchmarkConfig) validate() error {
 
-	fmt.Println("Validating functions...")
+	if err := validateWorkloadParameters(c.WorkloadParameters); err != nil {
+		return err
+	}
+
 	if len(c.Functions) == 0 {
 		return fmt.Errorf("at least one function must be specified")
 	}
@@ -72,7 +182,7 @@ func (c *BenchmarkConfig) validate() error {
 		if fn.URL == "" {
 			return fmt.Errorf("function[%d]: URL must not be empty", i)
 		}
-		if fn.Auth.Key == "" && fn.Provider != "alibaba" {
+		if fn.Auth.Key == "" && fn.Provider != "alibaba" && globals.AuthKeys[fn.Provider] != "" {
 			return fmt.Errorf("function[%d]: auth.key must not be empty", i)
 		}
 		if err := validateAuthKeys(fn.Auth.Key, fn.Provider); err != nil {
@@ -83,10 +193,10 @@ func (c *BenchmarkConfig) validate() error {
 	// all functions should have a unique name including alphabetic and numeric characters, dashes and underscores
 	functionNames := make(map[string]bool)
 	for i, fn := range c.Functions {
-		if _, exists := functionNames[fn.Name]; exists {
+		if _, exists := functionNames[fn.Key()]; exists {
 			return fmt.Errorf("function[%d]: duplicate function name '%s' with region '%s'", i, fn.Name, fn.Region)
 		}
-		functionNames[fmt.Sprintf("%s:%s", fn.Name, fn.Region)] = true
+		functionNames[fn.Key()] = true
 	}
 	return nil
 }
@@ -98,15 +208,157 @@ func validateWorkloadParameters(param WorkloadParameters) error {
 	if param.TotalRequests <= 0 {
 		return fmt.Errorf("workload.totalRequests must be greater than 0")
 	}
-	if param.RetriesPerRequest <= 0 {
-		return fmt.Errorf("workload.retriesPerRequest must be greater than 0")
+	// 0 disables retries
+	if param.RetriesPerRequest < 0 {
+		return fmt.Errorf("workload.retriesPerRequest must not be negative")
 	}
 	if param.ResultFolder == "" {
 		return fmt.Errorf("workload.resultFolder must not be empty")
 	}
+	if param.StopCriterion != nil {
+		if err := param.StopCriterion.validate(param.TotalRequests); err != nil {
+			return fmt.Errorf("workload.stopCriterion: %v", err)
+		}
+	}
+	if param.Trace != nil {
+		if param.StopCriterion != nil {
+			return fmt.Errorf("workload.trace cannot be combined with workload.stopCriterion")
+		}
+		if err := param.Trace.validate(); err != nil {
+			return fmt.Errorf("workload.trace: %v", err)
+		}
+	}
+	if param.Tracing != nil {
+		if err := param.Tracing.validate(); err != nil {
+			return fmt.Errorf("workload.tracing: %v", err)
+		}
+	}
+	if param.Logging != nil {
+		if err := param.Logging.validate(); err != nil {
+			return fmt.Errorf("workload.logging: %v", err)
+		}
+	}
+	if param.Pattern != nil {
+		if param.StopCriterion != nil || param.Trace != nil {
+			return fmt.Errorf("workload.pattern cannot be combined with workload.stopCriterion or workload.trace")
+		}
+		if err := param.Pattern.validate(); err != nil {
+			return fmt.Errorf("workload.pattern: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// validate checks the stop criterion and fills in defaults derived from totalRequests.
+func (s *StopCriterion) validate(totalRequests int) error {
+	if s.Metric == "" {
+		return fmt.Errorf("metric must not be empty")
+	}
+	if s.Statistic != "mean" && s.Statistic != "median" {
+		return fmt.Errorf("statistic must be 'mean' or 'median', got '%s'", s.Statistic)
+	}
+	if s.Confidence <= 0 || s.Confidence >= 1 {
+		return fmt.Errorf("confidence must be between 0 and 1")
+	}
+	if s.RelativeError <= 0 {
+		return fmt.Errorf("relativeError must be greater than 0")
+	}
+	if s.MaxSamples == 0 {
+		s.MaxSamples = totalRequests
+	}
+	if s.MinSamples < 2 {
+		return fmt.Errorf("minSamples must be at least 2")
+	}
+	if s.MaxSamples < s.MinSamples {
+		return fmt.Errorf("maxSamples must not be smaller than minSamples")
+	}
+	return nil
+}
+
+// validate checks the tracing config and fills in defaults.
+func (t *TracingConfig) validate() error {
+	if t.ServiceName == "" {
+		t.ServiceName = "classifaas-bench"
+	}
+	if t.SampleRatio == nil {
+		ratio := 1.0
+		t.SampleRatio = &ratio
+	}
+	if *t.SampleRatio < 0 || *t.SampleRatio > 1 {
+		return fmt.Errorf("sampleRatio must be between 0 and 1")
+	}
+	return nil
+}
+
+// validate checks the logging levels and fills in defaults.
+func (l *LoggingConfig) validate() error {
+	if l.File == "" {
+		l.File = "info"
+	}
+	if l.Syslog == "" {
+		l.Syslog = "off"
+	}
+	if err := validateLogLevel("file", l.File); err != nil {
+		return err
+	}
+	return validateLogLevel("syslog", l.Syslog)
+}
+
+func validateLogLevel(name, level string) error {
+	switch level {
+	case "info", "warning", "error", "off":
+		return nil
+	}
+	return fmt.Errorf("%s must be 'info', 'warning', 'error' or 'off', got '%s'", name, level)
+}
+
+// validate checks the trace config and fills in defaults.
+func (t *TraceConfig) validate() error {
+	if t.Path == "" {
+		return fmt.Errorf("path must not be empty")
+	}
+	if t.Format != "csv" && t.Format != "azure" {
+		return fmt.Errorf("format must be 'csv' or 'azure', got '%s'", t.Format)
+	}
+	if t.TimeScale < 0 || t.Duration < 0 {
+		return fmt.Errorf("timeScale and duration must not be negative")
+	}
+	if t.TimeScale == 0 {
+		t.TimeScale = 1
+	}
+	return nil
+}
 
+func (p *PatternConfig) validate() error {
+	if p.Duration <= 0 {
+		return fmt.Errorf("duration must be greater than 0")
+	}
+	if (p.Burst == nil) == (p.Periodic == nil) {
+		return fmt.Errorf("exactly one of burst and periodic must be set")
+	}
+	if b := p.Burst; b != nil {
+		if b.Size <= 0 {
+			return fmt.Errorf("burst.size must be greater than 0")
+		}
+		if b.Window < 0 || b.Interval <= 0 {
+			return fmt.Errorf("burst.window must not be negative and burst.interval must be greater than 0")
+		}
+		if b.Window > b.Interval {
+			return fmt.Errorf("burst.window must not exceed burst.interval")
+		}
+	}
+	if pp := p.Periodic; pp != nil {
+		if pp.Period <= 0 {
+			return fmt.Errorf("periodic.period must be greater than 0")
+		}
+		if pp.MinRate < 0 || pp.MaxRate <= 0 || pp.MinRate > pp.MaxRate {
+			return fmt.Errorf("periodic rates must satisfy 0 <= minRate <= maxRate and maxRate > 0")
+		}
+	}
 	return nil
 }
+
 func validateAuthKeys(key, provider string) error {
 	expectedKey, ok := globals.AuthKeys[provider]
 	if !ok {
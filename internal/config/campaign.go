@@ -0,0 +1,131 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CampaignConfig describes a long-running series of benchmark runs executed by `bench schedule`.
+type CampaignConfig struct {
+	Name string `yaml:"name"`
+	// Config is the path to the benchmark config used for every run.
+	Config   string           `yaml:"config"`
+	Schedule ScheduleConfig   `yaml:"schedule"`
+	Windows  []WindowConfig   `yaml:"windows,omitempty"`
+	MaxRuns  int              `yaml:"maxRuns,omitempty"`
+	Index    string           `yaml:"index,omitempty"`
+	Refresh  []RefreshCommand `yaml:"refresh,omitempty"`
+}
+
+// ScheduleConfig defines when runs start: either a cron expression or a fixed
+// interval, optionally delayed by a random jitter.
+type ScheduleConfig struct {
+	Cron     string        `yaml:"cron,omitempty"`
+	Interval time.Duration `yaml:"interval,omitempty"`
+	Jitter   time.Duration `yaml:"jitter,omitempty"`
+}
+
+// WindowConfig restricts run starts to a daily time window. End may be
+// earlier than Start for windows spanning midnight.
+type WindowConfig struct {
+	Start string   `yaml:"start"`
+	End   string   `yaml:"end"`
+	Days  []string `yaml:"days,omitempty"`
+}
+
+// RefreshCommand is executed before every run to refresh provider credentials,
+// e.g. `az account get-access-token`.
+type RefreshCommand struct {
+	Name    string `yaml:"name"`
+	Command string `yaml:"command"`
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// LoadCampaignConfig loads and validates a campaign file.
+func LoadCampaignConfig(path string) (*CampaignConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg CampaignConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse campaign file: %v", err)
+	}
+
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("invalid campaign: %v", err)
+	}
+	return &cfg, nil
+}
+
+func (c *CampaignConfig) validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("name must not be empty")
+	}
+	if c.Config == "" {
+		return fmt.Errorf("config must not be empty")
+	}
+	if (c.Schedule.Cron == "") == (c.Schedule.Interval == 0) {
+		return fmt.Errorf("exactly one of schedule.cron and schedule.interval must be set")
+	}
+	if c.Schedule.Interval < 0 || c.Schedule.Jitter < 0 {
+		return fmt.Errorf("schedule.interval and schedule.jitter must not be negative")
+	}
+	if c.MaxRuns < 0 {
+		return fmt.Errorf("maxRuns must not be negative")
+	}
+	for i, w := range c.Windows {
+		if _, err := w.Bounds(); err != nil {
+			return fmt.Errorf("windows[%d]: %v", i, err)
+		}
+		if _, err := w.Weekdays(); err != nil {
+			return fmt.Errorf("windows[%d]: %v", i, err)
+		}
+	}
+	for i, r := range c.Refresh {
+		if r.Command == "" {
+			return fmt.Errorf("refresh[%d]: command must not be empty", i)
+		}
+	}
+	return nil
+}
+
+// Bounds returns the start and end of the window as offsets from midnight.
+func (w WindowConfig) Bounds() ([2]time.Duration, error) {
+	var bounds [2]time.Duration
+	for i, s := range []string{w.Start, w.End} {
+		t, err := time.Parse("15:04", s)
+		if err != nil {
+			return bounds, fmt.Errorf("invalid time of day '%s', expected HH:MM", s)
+		}
+		bounds[i] = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	}
+	return bounds, nil
+}
+
+// Weekdays returns the days the window applies to; an empty result means every day.
+func (w WindowConfig) Weekdays() (map[time.Weekday]bool, error) {
+	days := make(map[time.Weekday]bool)
+	for _, d := range w.Days {
+		day, ok := weekdays[strings.ToLower(d)[:min(3, len(d))]]
+		if !ok {
+			return nil, fmt.Errorf("invalid day '%s'", d)
+		}
+		days[day] = true
+	}
+	return days, nil
+}
@@ -4,6 +4,8 @@ import (
 	"ClassiFaaS/internal/globals"
 	"fmt"
 	"os"
+	"slices"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -11,13 +13,75 @@ import (
 type DeploymentConfig struct {
 	Provider string `yaml:"provider"`
 	Region   string `yaml:"region"`
+	// Runtime overrides the runtime the deployment script deploys, in the
+	// provider's format, e.g. nodejs20 on GCP.
+	Runtime string `yaml:"runtime,omitempty"`
+	// Tags label the deployed resources where the provider supports it.
+	Tags map[string]string `yaml:"tags,omitempty"`
+	// Endpoint is the URL of a self-hosted platform: the OpenFaaS gateway,
+	// the Kubernetes API server running Knative or the OpenWhisk API host.
+	// The local provider's emulator is expected on http://127.0.0.1:8090 by
+	// default.
+	Endpoint string `yaml:"endpoint,omitempty"`
+	// Namespace deploys to a namespace of a self-hosted platform instead of
+	// its default one.
+	Namespace string `yaml:"namespace,omitempty"`
+	// Image is the container image deployed to OpenFaaS and Knative, built
+	// from deployment/container.
+	Image string `yaml:"image,omitempty"`
+	// CAFile is a PEM file with the CA certificates of a self-hosted
+	// platform's endpoint, if not signed by a public CA.
+	CAFile string `yaml:"caFile,omitempty"`
+	// Emulator configures how the local provider runs the functions.
+	Emulator *EmulatorConfig `yaml:"emulator,omitempty"`
+}
+
+// EmulatorConfig configures the functions deployed to the emulator of the
+// local provider.
+type EmulatorConfig struct {
+	// ColdStart delays the first request of every new instance.
+	ColdStart time.Duration `yaml:"coldStart,omitempty"`
+	// IdleTimeout stops instances idle for longer. Zero keeps them.
+	IdleTimeout time.Duration `yaml:"idleTimeout,omitempty"`
+	// Concurrency limits the instances per function; requests beyond it are
+	// throttled. Zero means no limit.
+	Concurrency int `yaml:"concurrency,omitempty"`
+	// MaxInvocations stops an instance after that many invocations.
+	// Defaults to 4, as the real handlers; -1 keeps instances.
+	MaxInvocations int `yaml:"maxInvocations,omitempty"`
+	// CPUModels are the CPU models reported by the instances, one picked at
+	// random per instance.
+	CPUModels []string `yaml:"cpuModels,omitempty"`
+	// FailureRate is the probability of an invocation failing.
+	FailureRate float64 `yaml:"failureRate,omitempty"`
 }
 
 var allowedProviders = map[string]struct{}{
-	"gcp":     {},
-	"aws":     {},
-	"azure":   {},
-	"alibaba": {},
+	"gcp":       {},
+	"aws":       {},
+	"azure":     {},
+	"alibaba":   {},
+	"local":     {},
+	"openfaas":  {},
+	"knative":   {},
+	"openwhisk": {},
+}
+
+// pluginRegions holds the valid regions of the providers registered with
+// RegisterProvider; an empty list allows any region.
+var pluginRegions = map[string][]string{}
+
+// RegisterProvider allows a provider added by a deployer plugin in
+// configurations. Its functions are authenticated with the authKey header,
+// or not at all if authKey is empty.
+func RegisterProvider(name string, regions []string, authKey string) error {
+	if _, ok := allowedProviders[name]; ok {
+		return fmt.Errorf("provider %q is already registered", name)
+	}
+	allowedProviders[name] = struct{}{}
+	pluginRegions[name] = regions
+	globals.AuthKeys[name] = authKey
+	return nil
 }
 
 type DeployConfig struct {
@@ -27,6 +91,40 @@ type DeployConfig struct {
 	Deployments        []struct {
 		DeploymentConfig `yaml:",inline"`
 	} `yaml:"deployments"`
+
+	// Steps limits the deploy, load and remove step of every deployment.
+	Steps StepConfig `yaml:"steps,omitempty"`
+
+	// State is the file recording the deployed functions. Defaults to
+	// .classifaas/state.json.
+	State string `yaml:"state,omitempty"`
+
+	// Readiness configures the check that the deployed functions respond.
+	Readiness ReadinessConfig `yaml:"readiness,omitempty"`
+}
+
+// ReadinessConfig configures how long deploy waits for the deployed
+// functions to respond correctly.
+type ReadinessConfig struct {
+	// Timeout limits how long a function may take to become ready.
+	// Defaults to 5m.
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+	// Interval is the delay between invocations of a function that is not
+	// ready yet. Defaults to 10s.
+	Interval time.Duration `yaml:"interval,omitempty"`
+	// Skip disables the check after deploying.
+	Skip bool `yaml:"skip,omitempty"`
+}
+
+// StepConfig configures how long deployment steps may run and how often
+// they are retried.
+type StepConfig struct {
+	// Timeout limits every attempt of a step. Zero means no limit.
+	Timeout    time.Duration `yaml:"timeout,omitempty"`
+	Retries    int           `yaml:"retries,omitempty"`
+	RetryDelay time.Duration `yaml:"retryDelay,omitempty"`
+	// FailFast cancels all other deployments once one of them failed.
+	FailFast bool `yaml:"failFast,omitempty"`
 }
 
 // LoadDeployConfig loads and validates the deployment configuration from the specified YAML file.
@@ -54,6 +152,24 @@ func LoadDeployConfig(path string) (*DeployConfig, error) {
 		cfg.MemorySizes = nil // nil means “all” later in your logic
 	}
 
+	if cfg.State == "" {
+		cfg.State = globals.DeploymentStateFile
+	}
+
+	if cfg.Steps.Timeout < 0 || cfg.Steps.Retries < 0 || cfg.Steps.RetryDelay < 0 {
+		return nil, fmt.Errorf("steps.timeout, steps.retries and steps.retryDelay must not be negative")
+	}
+
+	if cfg.Readiness.Timeout < 0 || cfg.Readiness.Interval < 0 {
+		return nil, fmt.Errorf("readiness.timeout and readiness.interval must not be negative")
+	}
+	if cfg.Readiness.Timeout == 0 {
+		cfg.Readiness.Timeout = 5 * time.Minute
+	}
+	if cfg.Readiness.Interval == 0 {
+		cfg.Readiness.Interval = 10 * time.Second
+	}
+
 	fmt.Println("Validating deploy-only config...")
 	for _, deploy := range cfg.Deployments {
 		if _, ok := allowedProviders[deploy.Provider]; !ok {
@@ -106,10 +222,48 @@ func (c *DeploymentConfig) validate(provider string) error {
 			}
 		}
 		return fmt.Errorf("invalid Alibaba region: %s", c.Region)
+	case "local":
+		// the emulator accepts any region
+	case "openfaas", "knative", "openwhisk":
+		// the region names the self-hosted cluster
+		if c.Endpoint == "" {
+			return fmt.Errorf("endpoint is required for %s", provider)
+		}
+		if provider != "openwhisk" && c.Image == "" {
+			return fmt.Errorf("image is required for %s", provider)
+		}
+	default:
+		if regions := pluginRegions[provider]; len(regions) > 0 && !slices.Contains(regions, c.Region) {
+			return fmt.Errorf("invalid %s region: %s", provider, c.Region)
+		}
 	}
 RegionValid:
 	if provider == "" {
 		return fmt.Errorf("provider is required for deployment config validation")
 	}
+	if c.Emulator != nil {
+		if provider != "local" {
+			return fmt.Errorf("emulator is only supported by the local provider")
+		}
+		if err := c.Emulator.validate(); err != nil {
+			return fmt.Errorf("invalid emulator config: %v", err)
+		}
+	}
+	return nil
+}
+
+func (c *EmulatorConfig) validate() error {
+	if c.ColdStart < 0 || c.IdleTimeout < 0 {
+		return fmt.Errorf("coldStart and idleTimeout must not be negative")
+	}
+	if c.Concurrency < 0 {
+		return fmt.Errorf("concurrency must not be negative")
+	}
+	if c.MaxInvocations < -1 {
+		return fmt.Errorf("maxInvocations must be positive, or -1 to keep instances")
+	}
+	if c.FailureRate < 0 || c.FailureRate > 1 {
+		return fmt.Errorf("failureRate must be between 0 and 1")
+	}
 	return nil
 }
@@ -0,0 +1,289 @@
+package dashboard
+
+import (
+	"ClassiFaaS/internal/metrics"
+	"ClassiFaaS/internal/utils"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+)
+
+const (
+	refreshInterval = 500 * time.Millisecond
+	// throughputWindow is the period the request rate is averaged over.
+	throughputWindow = 5 * time.Second
+	// maxEvents bounds the events kept for the event pane.
+	maxEvents = 500
+
+	enterAltScreen = "\033[?1049h\033[?25l"
+	leaveAltScreen = "\033[?25h\033[?1049l"
+	home           = "\033[H"
+	clearLine      = "\033[K"
+	clearBelow     = "\033[J"
+	bold           = "\033[1m"
+	reset          = "\033[0m"
+)
+
+// IsTerminal reports whether f is an interactive terminal.
+func IsTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// Dashboard renders the live progress of benchmark runs to a terminal: a
+// line per function and a scrolling pane of the latest events.
+type Dashboard struct {
+	out      *os.File
+	registry *metrics.Registry
+	title    string
+	started  time.Time
+
+	done     chan struct{}
+	wg       sync.WaitGroup
+	stopOnce sync.Once
+
+	mu      sync.Mutex
+	log     []utils.Event
+	samples map[string][]sample
+}
+
+// sample is the number of finished requests of a function at a point in time.
+type sample struct {
+	at       time.Time
+	finished int64
+}
+
+// New creates a dashboard rendering the functions of registry to out.
+func New(out *os.File, registry *metrics.Registry, title string) *Dashboard {
+	return &Dashboard{
+		out:      out,
+		registry: registry,
+		title:    title,
+		done:     make(chan struct{}),
+		samples:  make(map[string][]sample),
+	}
+}
+
+// WriteEvent adds an event to the event pane. The dashboard is a
+// utils.Sink, so events reach it through the event logger.
+func (d *Dashboard) WriteEvent(event utils.Event) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.log) == maxEvents {
+		d.log = d.log[1:]
+	}
+	d.log = append(d.log, event)
+	return nil
+}
+
+// Close implements utils.Sink; the terminal is restored by Stop.
+func (d *Dashboard) Close() error {
+	return nil
+}
+
+// Start switches the terminal to the dashboard and refreshes it periodically.
+func (d *Dashboard) Start() {
+	d.started = time.Now()
+	fmt.Fprint(d.out, enterAltScreen)
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		ticker := time.NewTicker(refreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-d.done:
+				return
+			case <-ticker.C:
+				d.render()
+			}
+		}
+	}()
+}
+
+// Stop restores the terminal and prints the final state of all functions
+// together with the warnings and errors that occurred.
+func (d *Dashboard) Stop() {
+	d.stopOnce.Do(func() {
+		close(d.done)
+		d.wg.Wait()
+
+		fmt.Fprint(d.out, leaveAltScreen)
+		width, _ := d.size()
+		for _, line := range d.functionLines(width) {
+			fmt.Fprintln(d.out, line)
+		}
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		for _, event := range d.log {
+			if event.Severity != utils.SeverityInfo {
+				fmt.Fprintln(d.out, formatEvent(event, width))
+			}
+		}
+	})
+}
+
+func (d *Dashboard) size() (int, int) {
+	width, height, err := term.GetSize(int(d.out.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		return 120, 40
+	}
+	return width, height
+}
+
+func (d *Dashboard) render() {
+	width, height := d.size()
+
+	lines := []string{
+		bold + truncate(fmt.Sprintf("%s  elapsed %s", d.title, time.Since(d.started).Truncate(time.Second)), width) + reset,
+		"",
+	}
+	lines = append(lines, d.functionLines(width)...)
+	lines = append(lines, "", bold+truncate("Events "+strings.Repeat("─", width), width)+reset)
+
+	d.mu.Lock()
+	pane := height - len(lines)
+	if pane < 0 {
+		pane = 0
+	}
+	events := d.log
+	if len(events) > pane {
+		events = events[len(events)-pane:]
+	}
+	for _, event := range events {
+		lines = append(lines, formatEvent(event, width))
+	}
+	d.mu.Unlock()
+
+	var b strings.Builder
+	b.WriteString(home)
+	for i, line := range lines {
+		if i >= height {
+			break
+		}
+		b.WriteString(line)
+		b.WriteString(clearLine)
+		if i < len(lines)-1 && i < height-1 {
+			b.WriteString("\r\n")
+		}
+	}
+	b.WriteString(clearBelow)
+	fmt.Fprint(d.out, b.String())
+}
+
+// functionLines renders the table of functions.
+func (d *Dashboard) functionLines(width int) []string {
+	status := d.registry.Status()
+	if len(status.Functions) == 0 {
+		return []string{"Waiting for load generators..."}
+	}
+
+	nameWidth := len("FUNCTION")
+	for _, f := range status.Functions {
+		nameWidth = max(nameWidth, len(f.Function))
+	}
+	const barWidth = 20
+
+	lines := []string{bold + truncate(fmt.Sprintf("%-*s  %-*s  %13s  %7s  %8s  %8s  %6s  %6s  %s",
+		nameWidth, "FUNCTION", barWidth+7, "PROGRESS", "DONE/TOTAL", "REQ/S", "P50 MS", "P99 MS", "FAILED", "RETRY", "CPU MODELS"), width) + reset}
+
+	now := time.Now()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, f := range status.Functions {
+		// requests of the current run that are neither queued nor in flight
+		finished := int64(f.Total-f.Remaining) - f.InFlight
+		finished = max(finished, 0)
+
+		fraction := 0.0
+		if f.Total > 0 {
+			fraction = float64(finished) / float64(f.Total)
+		}
+		filled := int(fraction * barWidth)
+		bar := "[" + strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled) + "]"
+
+		key := f.Provider + "/" + f.Region + "/" + f.Function
+		total := f.Completed + f.Failed
+		samples := append(d.samples[key], sample{at: now, finished: total})
+		for len(samples) > 1 && now.Sub(samples[0].at) > throughputWindow {
+			samples = samples[1:]
+		}
+		d.samples[key] = samples
+		rate := 0.0
+		if elapsed := now.Sub(samples[0].at).Seconds(); elapsed > 0 {
+			rate = float64(total-samples[0].finished) / elapsed
+		}
+
+		lines = append(lines, truncate(fmt.Sprintf("%-*s  %s %4.0f%%  %13s  %7.1f  %8s  %8s  %6d  %6d  %s",
+			nameWidth, f.Function, bar, fraction*100,
+			fmt.Sprintf("%d/%d", finished, f.Total), rate,
+			optional(f.P50LatencyMs), optional(f.P99LatencyMs),
+			f.Failed, f.Retries, cpuMix(f.CPUs)), width))
+	}
+	return lines
+}
+
+func optional(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f", *v)
+}
+
+// cpuMix lists the observed CPU models by frequency with their share.
+func cpuMix(cpus map[string]int64) string {
+	var total int64
+	models := make([]string, 0, len(cpus))
+	for model, count := range cpus {
+		models = append(models, model)
+		total += count
+	}
+	if total == 0 {
+		return "-"
+	}
+	sort.Slice(models, func(i, j int) bool {
+		if cpus[models[i]] != cpus[models[j]] {
+			return cpus[models[i]] > cpus[models[j]]
+		}
+		return models[i] < models[j]
+	})
+
+	parts := make([]string, len(models))
+	for i, model := range models {
+		parts[i] = fmt.Sprintf("%s %.0f%%", model, float64(cpus[model])*100/float64(total))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func formatEvent(event utils.Event, width int) string {
+	color := "\033[36m"
+	switch event.Severity {
+	case utils.SeverityWarning:
+		color = "\033[33m"
+	case utils.SeverityError:
+		color = "\033[31m"
+	}
+	eventType := event.Type
+	for _, part := range []string{event.Provider, event.Region, event.Function} {
+		if part != "" {
+			eventType += " " + part
+		}
+	}
+	line := truncate(fmt.Sprintf("%s %-7s [%s] %s", event.Time.Format("15:04:05"), event.Severity, eventType, event.Message), width)
+	return color + line + reset
+}
+
+// truncate cuts s to width runes, replacing line breaks.
+func truncate(s string, width int) string {
+	s = strings.ReplaceAll(s, "\n", " ")
+	runes := []rune(s)
+	if len(runes) > width {
+		return string(runes[:width])
+	}
+	return s
+}
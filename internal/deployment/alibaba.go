@@ -15,6 +15,8 @@ func newAlibabaDeployer(cfg config.DeploymentConfig) deployer {
 			provider:  "alibaba",
 			scriptDir: globals.AlibabaDeploymentScriptFolder,
 			region:    cfg.Region,
+			runtime:   cfg.Runtime,
+			tags:      cfg.Tags,
 		},
 	}
 }
@@ -0,0 +1,116 @@
+package deployment
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// apiClient calls the REST API of a self-hosted FaaS platform.
+type apiClient struct {
+	endpoint string
+	client   *http.Client
+	// authorize adds the platform's credentials to a request
+	authorize func(req *http.Request)
+	// err is returned by every call if the client could not be set up
+	err error
+}
+
+// apiError is a response of the platform with an error status.
+type apiError struct {
+	status  int
+	message string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.status, http.StatusText(e.status), e.message)
+}
+
+// isStatus reports whether err is or wraps an apiError with the given
+// status.
+func isStatus(err error, status int) bool {
+	var apiErr *apiError
+	return errors.As(err, &apiErr) && apiErr.status == status
+}
+
+func newAPIClient(endpoint, caFile string, authorize func(req *http.Request)) *apiClient {
+	c := &apiClient{
+		endpoint:  strings.TrimRight(endpoint, "/"),
+		client:    &http.Client{Timeout: 60 * time.Second},
+		authorize: authorize,
+	}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			c.err = fmt.Errorf("failed to read CA file: %v", err)
+			return c
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			c.err = fmt.Errorf("no certificates found in CA file %s", caFile)
+			return c
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+		c.client.Transport = transport
+	}
+	return c
+}
+
+// do sends body as JSON with the given content type, application/json if
+// empty, and decodes the response into result unless it is nil.
+func (c *apiClient) do(ctx context.Context, method, path, contentType string, body, result any) error {
+	if c.err != nil {
+		return c.err
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.endpoint+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.authorize != nil {
+		c.authorize(req)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(resp.Body)
+		return &apiError{status: resp.StatusCode, message: strings.TrimSpace(string(msg))}
+	}
+	if result != nil {
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return fmt.Errorf("failed to decode response of %s %s: %v", method, path, err)
+		}
+	}
+	return nil
+}
@@ -15,6 +15,8 @@ func newAwsDeployer(cfg config.DeploymentConfig) deployer {
 			provider:  "aws",
 			scriptDir: globals.AWSDeploymentScriptFolder,
 			region:    cfg.Region,
+			runtime:   cfg.Runtime,
+			tags:      cfg.Tags,
 		},
 	}
 }
@@ -15,6 +15,8 @@ func newAzureDeployer(cfg config.DeploymentConfig) deployer {
 			provider:  "azure",
 			scriptDir: globals.AzureDeploymentScriptFolder,
 			region:    cfg.Region,
+			runtime:   cfg.Runtime,
+			tags:      cfg.Tags,
 		},
 	}
 }
@@ -2,8 +2,14 @@ package deployment
 
 import (
 	"ClassiFaaS/internal/utils"
+	"context"
+	"errors"
 )
 
+// errNoFunctions is returned by LoadDeployedFunctions if the target has no
+// deployed functions, e.g. because it was never deployed.
+var errNoFunctions = errors.New("no functions found")
+
 type DeployedFunction struct {
 	Provider  string `json:"provider"`
 	URL       string `json:"url"`
@@ -11,14 +17,22 @@ type DeployedFunction struct {
 	Memory    int    `json:"memory"`
 	Benchmark string `json:"benchmark"`
 	Region    string `json:"region"`
+	// CodeHash identifies the deployed function code, see codeHash. It is
+	// only known for functions recorded in the state file.
+	CodeHash string `json:"codeHash,omitempty"`
 }
+
+// deployer manages the functions of one provider and region. Its methods
+// stop when ctx is cancelled, e.g. because the step timed out.
 type deployer interface {
-	// Deploy the functions to the target cloud provider
-	Deploy(event utils.EventPublisher) error
+	// Deploy the selected functions to the target cloud provider
+	Deploy(ctx context.Context, event utils.EventPublisher, functions FunctionSelector) error
 	// Load the deployed functions from the target cloud provider (including the auth details)
-	LoadDeployedFunctions(event utils.EventPublisher) ([]DeployedFunction, error)
-	// Remove the deployed functions from the target cloud provider
-	Remove(event utils.EventPublisher) error
+	LoadDeployedFunctions(ctx context.Context, event utils.EventPublisher) ([]DeployedFunction, error)
+	// Remove the selected functions from the target cloud provider
+	Remove(ctx context.Context, event utils.EventPublisher, functions FunctionSelector) error
+	// CodeHash identifies the function code that Deploy would deploy
+	CodeHash() (string, error)
 	// GetProvider returns the name of the cloud provider
 	GetProvider() string
 }
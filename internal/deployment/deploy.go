@@ -3,11 +3,23 @@ package deployment
 import (
 	"ClassiFaaS/internal/config"
 	"ClassiFaaS/internal/utils"
+	"context"
 	"fmt"
+	"sort"
+	"sync"
+	"time"
 )
 
 type DeployOrechestratorClient struct {
 	deployments map[string]DeployTargetClient
+	steps       config.StepConfig
+	benchmarks  map[string]int
+	memorySizes []int
+	readiness   config.ReadinessConfig
+
+	statePath string
+	stateMu   sync.Mutex
+	state     *State
 }
 
 type DeployTargetClient struct {
@@ -39,77 +51,214 @@ func NewDeployOrchestratorClient(cfg config.DeployConfig) (*DeployOrechestratorC
 			return nil, err
 		}
 
-		targets[fmt.Sprintf("%s:%s", deploymentTarget.Provider, deploymentTarget.Region)] = *targetClient
+		targets[targetKey(deploymentTarget.Provider, deploymentTarget.Region)] = *targetClient
+	}
+
+	state, err := LoadState(cfg.State)
+	if err != nil {
+		return nil, err
 	}
 
 	return &DeployOrechestratorClient{
 		deployments: targets,
+		steps:       cfg.Steps,
+		benchmarks:  cfg.Benchmarks,
+		memorySizes: cfg.MemorySizes,
+		readiness:   cfg.Readiness,
+		statePath:   cfg.State,
+		state:       state,
 	}, nil
 }
 
-func (tc *DeployTargetClient) Deploy(ep utils.EventPublisher) error {
-	ep.SendEvent(utils.SeverityInfo, fmt.Sprintf("deploy_%s", tc.provider), fmt.Sprintf("Starting deployment in %s region", tc.region))
-
-	if err := tc.deployer.Deploy(ep); err != nil {
-		ep.SendEvent(utils.SeverityError, fmt.Sprintf("deploy_%s", tc.provider), fmt.Sprintf("Deployment failed in %s region: %v", tc.region, err))
-		return err
+func memorySizesString(memorySizes []int) string {
+	if memorySizes == nil {
+		return "all"
 	}
+	return fmt.Sprint(memorySizes)
+}
 
-	ep.SendEvent(utils.SeverityInfo, fmt.Sprintf("deploy_%s", tc.provider), fmt.Sprintf("Deployment succeeded in %s region", tc.region))
-	return nil
+func targetKey(provider, region string) string {
+	return fmt.Sprintf("%s:%s", provider, region)
 }
 
-func (oc *DeployOrechestratorClient) DeployAll(ep utils.EventPublisher) error {
-	deployTimeLine := utils.NewTimeline("Deploy All Targets", utils.RunParallel)
+func (tc *DeployTargetClient) scope() utils.Scope {
+	return utils.Scope{Provider: tc.provider, Region: tc.region}
+}
+
+// timeline creates a timeline running a step per target with the configured
+// timeout and retries.
+func (oc *DeployOrechestratorClient) timeline(description string, targets map[string]DeployTargetClient, step func(tc DeployTargetClient) utils.StepContextFunc) *utils.Timeline {
+	tl := utils.NewTimeline(description, utils.RunParallel)
+	tl.FailFast = oc.steps.FailFast
 
-	for _, deployer := range oc.deployments {
-		deployTimeLine.Step(deployer.Deploy)
+	names := make([]string, 0, len(targets))
+	for name := range targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		tl.AddStep(&utils.Step{
+			Name:       name,
+			RunContext: step(targets[name]),
+			Timeout:    oc.steps.Timeout,
+			Retries:    oc.steps.Retries,
+			RetryDelay: oc.steps.RetryDelay,
+		})
 	}
+	return tl
+}
+
+func (tc *DeployTargetClient) Deploy(ctx context.Context, ep utils.EventPublisher, functions FunctionSelector) error {
+	ep.SendScopedEvent(tc.scope(), utils.SeverityInfo, "deploy", fmt.Sprintf("Starting deployment of benchmarks %v at memory sizes %s", functions.Benchmarks, memorySizesString(functions.MemorySizes)))
 
-	if err := deployTimeLine.Run(ep); err != nil {
+	if err := tc.deployer.Deploy(ctx, ep, functions); err != nil {
+		ep.SendScopedEvent(tc.scope(), utils.SeverityError, "deploy", fmt.Sprintf("Deployment failed: %v", err))
 		return err
 	}
 
+	ep.SendScopedEvent(tc.scope(), utils.SeverityInfo, "deploy", "Deployment succeeded")
 	return nil
 }
 
-func (oc *DeployOrechestratorClient) GenerateBenchmarkFunctions(ep utils.EventPublisher, filter func(DeployedFunction) bool, transformer func(DeployedFunction) config.BenchmarkFunctionConfig) ([]config.BenchmarkFunctionConfig, error) {
-
-	var functions []config.BenchmarkFunctionConfig
+// DeployAll deploys the configured benchmarks and memory sizes to the
+// configured targets, restricted by sel, and records the deployed functions
+// in the state file.
+func (oc *DeployOrechestratorClient) DeployAll(ctx context.Context, ep utils.EventPublisher, sel Selector) error {
+	targets, err := sel.targets(oc.deployments)
+	if err != nil {
+		return err
+	}
+	functions, err := oc.deployFunctions(sel.FunctionSelector)
+	if err != nil {
+		return err
+	}
 
-	GetDeployedFunctionsTimeline := utils.NewTimeline("Get Deployed Functions", utils.RunParallel)
-	for _, deployer := range oc.deployments {
-		GetDeployedFunctionsTimeline.Step(func(ep utils.EventPublisher) error {
-			funcs, err := deployer.deployer.LoadDeployedFunctions(ep)
+	deployTimeLine := oc.timeline("Deploy All Targets", targets, func(tc DeployTargetClient) utils.StepContextFunc {
+		return func(ctx context.Context, ep utils.EventPublisher) error {
+			hash, err := tc.deployer.CodeHash()
 			if err != nil {
-				ep.SendEvent(utils.SeverityError, fmt.Sprintf("get_functions_%s", deployer.provider), fmt.Sprintf("Failed to load functions for %s in %s: %v", deployer.provider, deployer.region, err))
+				return err
+			}
+			if err := tc.Deploy(ctx, ep, functions); err != nil {
 				return err
 			}
 
-			var filteredFuncs []DeployedFunction
-			for _, f := range funcs {
-				if filter(f) {
-					filteredFuncs = append(filteredFuncs, f)
-				}
+			// a deployment whose functions cannot be listed is still recorded,
+			// so that it can be removed
+			funcs, err := tc.deployer.LoadDeployedFunctions(ctx, ep)
+			if err != nil {
+				ep.SendScopedEvent(tc.scope(), utils.SeverityWarning, "deploy", fmt.Sprintf("Failed to record deployed functions: %v", err))
 			}
+			return oc.updateState(func(state *State) {
+				key := targetKey(tc.provider, tc.region)
+				target := &TargetState{
+					Provider:    tc.provider,
+					Region:      tc.region,
+					DeployedAt:  time.Now(),
+					Benchmarks:  make(map[string]int),
+					MemorySizes: functions.MemorySizes,
+					Functions:   funcs,
+				}
+				for _, benchmark := range functions.Benchmarks {
+					target.Benchmarks[benchmark] = oc.benchmarks[benchmark]
+				}
+				if previous, ok := state.Targets[key]; ok {
+					target.merge(previous)
+				}
+				target.setCodeHash(functions, hash)
+				state.Targets[key] = target
+			})
+		}
+	})
 
-			ep.SendEvent(utils.SeverityInfo, fmt.Sprintf("get_functions_%s", deployer.provider), fmt.Sprintf("Loaded %d functions for %s in %s, %d passed filtering", len(funcs), deployer.provider, deployer.region, len(filteredFuncs)))
+	if err := deployTimeLine.RunContext(ctx, ep); err != nil {
+		return err
+	}
 
-			var transformedFuncs []config.BenchmarkFunctionConfig
-			for _, f := range filteredFuncs {
-				transformedFuncs = append(transformedFuncs, transformer(f))
+	return nil
+}
+
+// updateState applies update to the state and saves it.
+func (oc *DeployOrechestratorClient) updateState(update func(state *State)) error {
+	oc.stateMu.Lock()
+	defer oc.stateMu.Unlock()
+	update(oc.state)
+	if err := oc.state.Save(oc.statePath); err != nil {
+		return fmt.Errorf("failed to save deployment state: %v", err)
+	}
+	return nil
+}
+
+// GenerateBenchmarkFunctions lists the deployed functions of all targets and
+// transforms those passing filter into benchmark functions.
+func (oc *DeployOrechestratorClient) GenerateBenchmarkFunctions(ctx context.Context, ep utils.EventPublisher, filter func(DeployedFunction) bool, transformer func(DeployedFunction) config.BenchmarkFunctionConfig) ([]config.BenchmarkFunctionConfig, error) {
+
+	var functions []config.BenchmarkFunctionConfig
+	var mu sync.Mutex
+
+	GetDeployedFunctionsTimeline := oc.timeline("Get Deployed Functions", oc.deployments, func(deployer DeployTargetClient) utils.StepContextFunc {
+		return func(ctx context.Context, ep utils.EventPublisher) error {
+			funcs, err := deployer.deployer.LoadDeployedFunctions(ctx, ep)
+			if err != nil {
+				ep.SendScopedEvent(deployer.scope(), utils.SeverityError, "get_functions", fmt.Sprintf("Failed to load functions: %v", err))
+				return err
 			}
 
+			transformedFuncs := filterFunctions(deployer.scope(), ep, funcs, filter, transformer)
+
+			mu.Lock()
 			functions = append(functions, transformedFuncs...)
+			mu.Unlock()
 
-			ep.SendEvent(utils.SeverityInfo, fmt.Sprintf("get_functions_%s", deployer.provider), fmt.Sprintf("Successfully loaded %d functions for %s in %s", len(funcs), deployer.provider, deployer.region))
+			ep.SendScopedEvent(deployer.scope(), utils.SeverityInfo, "get_functions", fmt.Sprintf("Successfully loaded %d functions", len(funcs)))
 			return nil
-		})
-	}
+		}
+	})
 
-	if err := GetDeployedFunctionsTimeline.Run(ep); err != nil {
+	if err := GetDeployedFunctionsTimeline.RunContext(ctx, ep); err != nil {
 		return functions, err
 	}
 
 	return functions, nil
 }
+
+// GenerateBenchmarkFunctionsFromState is GenerateBenchmarkFunctions for the
+// functions recorded in the state file, without contacting the providers.
+func (oc *DeployOrechestratorClient) GenerateBenchmarkFunctionsFromState(ep utils.EventPublisher, filter func(DeployedFunction) bool, transformer func(DeployedFunction) config.BenchmarkFunctionConfig) ([]config.BenchmarkFunctionConfig, error) {
+	oc.stateMu.Lock()
+	defer oc.stateMu.Unlock()
+
+	names := make([]string, 0, len(oc.deployments))
+	for name := range oc.deployments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var functions []config.BenchmarkFunctionConfig
+	for _, name := range names {
+		target, ok := oc.state.Targets[name]
+		if !ok {
+			return nil, fmt.Errorf("no deployment of %s recorded in %s", name, oc.statePath)
+		}
+		tc := oc.deployments[name]
+		functions = append(functions, filterFunctions(tc.scope(), ep, target.Functions, filter, transformer)...)
+	}
+	return functions, nil
+}
+
+func filterFunctions(scope utils.Scope, ep utils.EventPublisher, funcs []DeployedFunction, filter func(DeployedFunction) bool, transformer func(DeployedFunction) config.BenchmarkFunctionConfig) []config.BenchmarkFunctionConfig {
+	var filteredFuncs []DeployedFunction
+	for _, f := range funcs {
+		if filter(f) {
+			filteredFuncs = append(filteredFuncs, f)
+		}
+	}
+
+	ep.SendScopedEvent(scope, utils.SeverityInfo, "get_functions", fmt.Sprintf("Loaded %d functions, %d passed filtering", len(funcs), len(filteredFuncs)))
+
+	var transformedFuncs []config.BenchmarkFunctionConfig
+	for _, f := range filteredFuncs {
+		transformedFuncs = append(transformedFuncs, transformer(f))
+	}
+	return transformedFuncs
+}
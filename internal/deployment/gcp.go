@@ -15,6 +15,8 @@ func newGCPDeployer(cfg config.DeploymentConfig) deployer {
 			provider:  "gcp",
 			scriptDir: globals.GCPDeploymentScriptFolder,
 			region:    cfg.Region,
+			runtime:   cfg.Runtime,
+			tags:      cfg.Tags,
 		},
 	}
 }
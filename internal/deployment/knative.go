@@ -0,0 +1,134 @@
+package deployment
+
+import (
+	"ClassiFaaS/internal/config"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// knativeTokenEnv holds the bearer token authenticating with the Kubernetes
+// API server. Without it, requests are sent unauthenticated, e.g. to the
+// endpoint of kubectl proxy.
+const knativeTokenEnv = "KNATIVE_TOKEN"
+
+// knativePlatform deploys the container image as Knative Services through
+// the Kubernetes API. The functions are invoked on the URLs assigned by
+// Knative Serving, without auth.
+type knativePlatform struct {
+	api       *apiClient
+	image     string
+	namespace string
+}
+
+func newKnativeDeployer(cfg config.DeploymentConfig) deployer {
+	authorize := func(req *http.Request) {
+		if token := os.Getenv(knativeTokenEnv); token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+
+	namespace := cfg.Namespace
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	return &selfHostedDeployer{
+		provider: "knative",
+		region:   cfg.Region,
+		tags:     cfg.Tags,
+		platform: &knativePlatform{
+			api:       newAPIClient(cfg.Endpoint, cfg.CAFile, authorize),
+			image:     cfg.Image,
+			namespace: namespace,
+		},
+	}
+}
+
+// knativeService is the part of a Knative Service read by the deployer.
+type knativeService struct {
+	Metadata struct {
+		Name   string            `json:"name"`
+		Labels map[string]string `json:"labels,omitempty"`
+	} `json:"metadata"`
+	Status struct {
+		URL string `json:"url,omitempty"`
+	} `json:"status"`
+}
+
+func (p *knativePlatform) servicesPath() string {
+	return fmt.Sprintf("/apis/serving.knative.dev/v1/namespaces/%s/services", url.PathEscape(p.namespace))
+}
+
+func (p *knativePlatform) deployFunction(ctx context.Context, name string, f DeployedFunction, labels map[string]string) error {
+	memory := map[string]string{"memory": kubernetesMemory(f.Memory)}
+	service := map[string]any{
+		"apiVersion": "serving.knative.dev/v1",
+		"kind":       "Service",
+		"metadata": map[string]any{
+			"name":      name,
+			"namespace": p.namespace,
+			"labels":    labels,
+		},
+		"spec": map[string]any{
+			"template": map[string]any{
+				"metadata": map[string]any{"labels": labels},
+				"spec": map[string]any{
+					// one request per instance, as on the public clouds
+					"containerConcurrency": 1,
+					"containers": []any{map[string]any{
+						"image": p.image,
+						"env": []any{
+							map[string]string{"name": "BENCHMARK", "value": f.Benchmark},
+							map[string]string{"name": "PROVIDER", "value": "knative"},
+						},
+						"resources": map[string]any{"limits": memory, "requests": memory},
+						"readinessProbe": map[string]any{
+							"httpGet": map[string]string{"path": "/_/health"},
+						},
+					}},
+				},
+			},
+		},
+	}
+
+	// server-side apply creates or updates the service
+	query := url.Values{"fieldManager": {"classifaas"}, "force": {"true"}}
+	path := p.servicesPath() + "/" + url.PathEscape(name) + "?" + query.Encode()
+	return p.api.do(ctx, http.MethodPatch, path, "application/apply-patch+yaml", service, nil)
+}
+
+func (p *knativePlatform) listFunctions(ctx context.Context, region string) ([]platformFunction, error) {
+	query := url.Values{"labelSelector": {labelRegion + "=" + region}}
+	var list struct {
+		Items []knativeService `json:"items"`
+	}
+	if err := p.api.do(ctx, http.MethodGet, p.servicesPath()+"?"+query.Encode(), "", nil, &list); err != nil {
+		return nil, err
+	}
+
+	functions := make([]platformFunction, 0, len(list.Items))
+	for _, service := range list.Items {
+		functions = append(functions, platformFunction{
+			name:   service.Metadata.Name,
+			labels: service.Metadata.Labels,
+			url:    service.Status.URL,
+		})
+	}
+	return functions, nil
+}
+
+func (p *knativePlatform) deleteFunction(ctx context.Context, name string) error {
+	return p.api.do(ctx, http.MethodDelete, p.servicesPath()+"/"+url.PathEscape(name), "", nil, nil)
+}
+
+func (p *knativePlatform) codeHash() (string, error) {
+	return imageHash(p.image)
+}
+
+// kubernetesMemory formats a memory size in MB as a Kubernetes quantity.
+func kubernetesMemory(memory int) string {
+	return fmt.Sprintf("%dMi", memory)
+}
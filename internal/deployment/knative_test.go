@@ -0,0 +1,151 @@
+package deployment
+
+import (
+	"ClassiFaaS/internal/config"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeKnative is a stubbed Kubernetes API server serving the Knative
+// Services of one namespace from memory.
+type fakeKnative struct {
+	mu       sync.Mutex
+	services map[string]map[string]any
+}
+
+func newFakeKnative(t *testing.T, namespace string) (*fakeKnative, *httptest.Server) {
+	f := &fakeKnative{services: map[string]map[string]any{
+		// a service not deployed by ClassiFaaS
+		"hello": {"metadata": map[string]any{"name": "hello", "labels": map[string]any{"app": "hello"}}},
+	}}
+	prefix := "/apis/serving.knative.dev/v1/namespaces/" + namespace + "/services"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		name, ok := strings.CutPrefix(r.URL.Path, prefix)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		name = strings.TrimPrefix(name, "/")
+
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		switch {
+		case r.Method == http.MethodGet && name == "":
+			key, value, _ := strings.Cut(r.URL.Query().Get("labelSelector"), "=")
+			items := []map[string]any{}
+			for _, service := range f.services {
+				labels, _ := service["metadata"].(map[string]any)["labels"].(map[string]any)
+				if labels[key] == value {
+					items = append(items, service)
+				}
+			}
+			json.NewEncoder(w).Encode(map[string]any{"items": items})
+		case r.Method == http.MethodPatch && name != "":
+			if r.Header.Get("Content-Type") != "application/apply-patch+yaml" || r.URL.Query().Get("fieldManager") != "classifaas" {
+				http.Error(w, "not a server-side apply", http.StatusUnsupportedMediaType)
+				return
+			}
+			// JSON is YAML, so the apply patch can be decoded as JSON
+			var service map[string]any
+			if err := json.NewDecoder(r.Body).Decode(&service); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			service["status"] = map[string]any{"url": "http://" + name + "." + namespace + ".example.com"}
+			f.services[name] = service
+			json.NewEncoder(w).Encode(service)
+		case r.Method == http.MethodDelete && name != "":
+			if _, exists := f.services[name]; !exists {
+				http.Error(w, "not found", http.StatusNotFound)
+				return
+			}
+			delete(f.services, name)
+			w.Write([]byte("{}"))
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return f, server
+}
+
+func TestKnativeDeployer(t *testing.T) {
+	t.Setenv(knativeTokenEnv, "token")
+	fake, server := newFakeKnative(t, "bench")
+
+	d := newKnativeDeployer(config.DeploymentConfig{
+		Provider:  "knative",
+		Region:    "lab",
+		Endpoint:  server.URL,
+		Namespace: "bench",
+		Image:     "registry.example.com/bench:1",
+	})
+
+	testLifecycle(t, d, func(t *testing.T, functions map[string]DeployedFunction) {
+		sha, ok := functions["sha256-128"]
+		if !ok {
+			t.Fatalf("sha256-128 not listed: %+v", functions)
+		}
+		if want := "http://b-sha256-lab-128.bench.example.com"; sha.URL != want {
+			t.Errorf("URL = %q, want %q", sha.URL, want)
+		}
+
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		spec := fake.services["b-sha256-lab-128"]["spec"].(map[string]any)["template"].(map[string]any)["spec"].(map[string]any)
+		if spec["containerConcurrency"] != float64(1) {
+			t.Errorf("containerConcurrency = %v, want 1", spec["containerConcurrency"])
+		}
+		container := spec["containers"].([]any)[0].(map[string]any)
+		if container["image"] != "registry.example.com/bench:1" {
+			t.Errorf("image = %v", container["image"])
+		}
+		limits := container["resources"].(map[string]any)["limits"].(map[string]any)
+		if limits["memory"] != "128Mi" {
+			t.Errorf("memory limit = %v, want 128Mi", limits["memory"])
+		}
+	})
+
+	if _, exists := fake.services["hello"]; !exists {
+		t.Error("removed a service not deployed by ClassiFaaS")
+	}
+}
+
+func TestKnativeDeployerOtherRegion(t *testing.T) {
+	t.Setenv(knativeTokenEnv, "token")
+	_, server := newFakeKnative(t, "default")
+	ep := testPublisher(t)
+
+	lab := newKnativeDeployer(config.DeploymentConfig{Provider: "knative", Region: "lab", Endpoint: server.URL, Image: "bench"})
+	edge := newKnativeDeployer(config.DeploymentConfig{Provider: "knative", Region: "edge", Endpoint: server.URL, Image: "bench"})
+	sel := FunctionSelector{Benchmarks: []string{"gzip"}, MemorySizes: []int{512}}
+	if err := lab.Deploy(context.Background(), ep, sel); err != nil {
+		t.Fatal(err)
+	}
+	if err := edge.Deploy(context.Background(), ep, sel); err != nil {
+		t.Fatal(err)
+	}
+
+	// removing one region keeps the functions of the other
+	if err := edge.Remove(context.Background(), ep, FunctionSelector{}); err != nil {
+		t.Fatal(err)
+	}
+	functions, err := lab.LoadDeployedFunctions(context.Background(), ep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(functions) != 1 || functions[0].Region != "lab" {
+		t.Errorf("listed %+v, want the lab function", functions)
+	}
+}
@@ -0,0 +1,169 @@
+package deployment
+
+import (
+	"ClassiFaaS/internal/config"
+	"ClassiFaaS/internal/emulator"
+	"ClassiFaaS/internal/globals"
+	"ClassiFaaS/internal/utils"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// defaultMaxInvocations recycles emulated instances like the real handlers,
+// which exit after their fourth invocation.
+const defaultMaxInvocations = 4
+
+// LocalDeployer deploys the functions to the emulator of cmd/emulator, so
+// that everything can be exercised without a cloud account.
+type LocalDeployer struct {
+	region  string
+	options emulator.Options
+	client  *emulator.Client
+}
+
+func newLocalDeployer(cfg config.DeploymentConfig) deployer {
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = globals.LocalEmulatorEndpoint
+	}
+
+	options := emulator.Options{MaxInvocations: defaultMaxInvocations}
+	if e := cfg.Emulator; e != nil {
+		options = emulator.Options{
+			ColdStart:      e.ColdStart,
+			IdleTimeout:    e.IdleTimeout,
+			Concurrency:    e.Concurrency,
+			MaxInvocations: e.MaxInvocations,
+			CPUModels:      e.CPUModels,
+			FailureRate:    e.FailureRate,
+		}
+		switch e.MaxInvocations {
+		case 0:
+			options.MaxInvocations = defaultMaxInvocations
+		case -1:
+			options.MaxInvocations = 0
+		}
+	}
+
+	return &LocalDeployer{
+		region:  cfg.Region,
+		options: options,
+		client:  emulator.NewClient(endpoint),
+	}
+}
+
+func (d *LocalDeployer) GetProvider() string {
+	return emulator.Provider
+}
+
+func (d *LocalDeployer) scope() utils.Scope {
+	return utils.Scope{Provider: emulator.Provider, Region: d.region}
+}
+
+// CodeHash covers the emulator's options in addition to its code, so that
+// changing them plans an update.
+func (d *LocalDeployer) CodeHash() (string, error) {
+	code, err := codeHash(globals.EmulatorSourceFolder)
+	if err != nil {
+		return "", err
+	}
+	options, err := json.Marshal(d.options)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(append([]byte(code), options...))
+	return hex.EncodeToString(hash[:]), nil
+}
+
+func (d *LocalDeployer) Deploy(ctx context.Context, ep utils.EventPublisher, functions FunctionSelector) error {
+	ep.SendScopedEvent(d.scope(), utils.SeverityInfo, "deploy", "Starting deployment...")
+
+	var specs []emulator.Function
+	for _, f := range functions.functions() {
+		specs = append(specs, emulator.Function{
+			Name:      emulator.FunctionName(f.Benchmark, d.region, f.Memory),
+			Benchmark: f.Benchmark,
+			Memory:    f.Memory,
+			Region:    d.region,
+			Options:   d.options,
+		})
+	}
+	if err := d.client.Deploy(ctx, specs); err != nil {
+		return fmt.Errorf("failed to deploy (location: %s): %w", d.region, err)
+	}
+
+	for _, spec := range specs {
+		scope := d.scope()
+		scope.Function = fmt.Sprintf("%s-%d", spec.Benchmark, spec.Memory)
+		ep.SendScopedEvent(scope, utils.SeverityInfo, "function_deployed", d.client.URL(spec.Name))
+	}
+
+	ep.SendScopedEvent(d.scope(), utils.SeverityInfo, "deploy", "deployment completed successfully")
+	return nil
+}
+
+// deployed lists the emulator's functions of the deployer's region.
+func (d *LocalDeployer) deployed(ctx context.Context) ([]emulator.Function, error) {
+	all, err := d.client.Functions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var functions []emulator.Function
+	for _, f := range all {
+		if f.Region == d.region {
+			functions = append(functions, f)
+		}
+	}
+	return functions, nil
+}
+
+func (d *LocalDeployer) LoadDeployedFunctions(ctx context.Context, ep utils.EventPublisher) ([]DeployedFunction, error) {
+	ep.SendScopedEvent(d.scope(), utils.SeverityInfo, "load_functions", "Fetching function URLs from the emulator...")
+
+	functions, err := d.deployed(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get local function URLs: %w", err)
+	}
+	if len(functions) == 0 {
+		return nil, fmt.Errorf("%w on the emulator in region %s", errNoFunctions, d.region)
+	}
+
+	deployedFunctions := make([]DeployedFunction, 0, len(functions))
+	for _, f := range functions {
+		deployedFunctions = append(deployedFunctions, DeployedFunction{
+			Provider:  emulator.Provider,
+			URL:       d.client.URL(f.Name),
+			Memory:    f.Memory,
+			Benchmark: f.Benchmark,
+			Region:    f.Region,
+		})
+	}
+
+	ep.SendScopedEvent(d.scope(), utils.SeverityInfo, "load_functions", fmt.Sprintf("parsed %d functions", len(deployedFunctions)))
+	return deployedFunctions, nil
+}
+
+func (d *LocalDeployer) Remove(ctx context.Context, ep utils.EventPublisher, functions FunctionSelector) error {
+	ep.SendScopedEvent(d.scope(), utils.SeverityInfo, "remove", "Starting removal of deployment...")
+
+	deployed, err := d.deployed(ctx)
+	if err == nil {
+		var names []string
+		for _, f := range deployed {
+			if functions.Matches(DeployedFunction{Benchmark: f.Benchmark, Memory: f.Memory}) {
+				names = append(names, f.Name)
+			}
+		}
+		err = d.client.Remove(ctx, names)
+	}
+	if err != nil {
+		ep.SendScopedEvent(d.scope(), utils.SeverityError, "remove", fmt.Sprintf("failed to remove deployment: %v", err))
+		return fmt.Errorf("failed to delete (location: %s): %w", d.region, err)
+	}
+
+	ep.SendScopedEvent(d.scope(), utils.SeverityInfo, "remove", "removal completed successfully")
+	return nil
+}
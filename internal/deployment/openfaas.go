@@ -0,0 +1,132 @@
+package deployment
+
+import (
+	"ClassiFaaS/internal/config"
+	"context"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// Credentials of the OpenFaaS gateway's basic auth. The user defaults to
+// admin; without a password, requests are sent unauthenticated.
+const (
+	openFaaSUserEnv     = "OPENFAAS_USERNAME"
+	openFaaSPasswordEnv = "OPENFAAS_PASSWORD"
+)
+
+// openFaaSPlatform deploys the container image through the REST API of an
+// OpenFaaS gateway. The functions are invoked through the gateway without
+// auth.
+type openFaaSPlatform struct {
+	api       *apiClient
+	image     string
+	namespace string
+}
+
+func newOpenFaaSDeployer(cfg config.DeploymentConfig) deployer {
+	authorize := func(req *http.Request) {
+		user := os.Getenv(openFaaSUserEnv)
+		if user == "" {
+			user = "admin"
+		}
+		if password := os.Getenv(openFaaSPasswordEnv); password != "" {
+			req.SetBasicAuth(user, password)
+		}
+	}
+
+	return &selfHostedDeployer{
+		provider: "openfaas",
+		region:   cfg.Region,
+		tags:     cfg.Tags,
+		platform: &openFaaSPlatform{
+			api:       newAPIClient(cfg.Endpoint, cfg.CAFile, authorize),
+			image:     cfg.Image,
+			namespace: cfg.Namespace,
+		},
+	}
+}
+
+type openFaaSFunction struct {
+	Service     string            `json:"service"`
+	Image       string            `json:"image"`
+	Namespace   string            `json:"namespace,omitempty"`
+	EnvVars     map[string]string `json:"envVars,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Limits      *openFaaSLimits   `json:"limits,omitempty"`
+	Requests    *openFaaSLimits   `json:"requests,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type openFaaSLimits struct {
+	Memory string `json:"memory,omitempty"`
+}
+
+// openFaaSStatus is a function as listed by the gateway.
+type openFaaSStatus struct {
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+}
+
+func (p *openFaaSPlatform) deployFunction(ctx context.Context, name string, f DeployedFunction, labels map[string]string) error {
+	memory := &openFaaSLimits{Memory: kubernetesMemory(f.Memory)}
+	function := openFaaSFunction{
+		Service:   name,
+		Image:     p.image,
+		Namespace: p.namespace,
+		EnvVars:   map[string]string{"BENCHMARK": f.Benchmark, "PROVIDER": "openfaas"},
+		Labels:    labels,
+		Limits:    memory,
+		Requests:  memory,
+	}
+
+	// update the function, or create it if it does not exist yet
+	err := p.api.do(ctx, http.MethodPut, "/system/functions", "", function, nil)
+	if isStatus(err, http.StatusNotFound) {
+		err = p.api.do(ctx, http.MethodPost, "/system/functions", "", function, nil)
+	}
+	return err
+}
+
+func (p *openFaaSPlatform) listFunctions(ctx context.Context, region string) ([]platformFunction, error) {
+	path := "/system/functions"
+	if p.namespace != "" {
+		path += "?" + url.Values{"namespace": {p.namespace}}.Encode()
+	}
+	var statuses []openFaaSStatus
+	if err := p.api.do(ctx, http.MethodGet, path, "", nil, &statuses); err != nil {
+		return nil, err
+	}
+
+	var functions []platformFunction
+	for _, status := range statuses {
+		if status.Labels[labelRegion] != region {
+			continue
+		}
+		// functions outside the gateway's default namespace are invoked
+		// as <name>.<namespace>
+		target := status.Name
+		if p.namespace != "" {
+			target += "." + p.namespace
+		}
+		functions = append(functions, platformFunction{
+			name:   status.Name,
+			labels: status.Labels,
+			url:    p.api.endpoint + "/function/" + target,
+		})
+	}
+	return functions, nil
+}
+
+func (p *openFaaSPlatform) deleteFunction(ctx context.Context, name string) error {
+	body := map[string]string{"functionName": name}
+	if p.namespace != "" {
+		body["namespace"] = p.namespace
+	}
+	return p.api.do(ctx, http.MethodDelete, "/system/functions", "", body, nil)
+}
+
+func (p *openFaaSPlatform) codeHash() (string, error) {
+	return imageHash(p.image)
+}
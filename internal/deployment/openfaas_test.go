@@ -0,0 +1,154 @@
+package deployment
+
+import (
+	"ClassiFaaS/internal/config"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// fakeOpenFaaS is a stubbed OpenFaaS gateway keeping its functions in memory.
+type fakeOpenFaaS struct {
+	mu        sync.Mutex
+	functions map[string]openFaaSFunction
+	// calls lists the method of every /system/functions request
+	calls []string
+}
+
+func newFakeOpenFaaS(t *testing.T) (*fakeOpenFaaS, *httptest.Server) {
+	f := &fakeOpenFaaS{functions: map[string]openFaaSFunction{
+		// a function not deployed by ClassiFaaS
+		"nodeinfo": {Service: "nodeinfo", Image: "ghcr.io/openfaas/nodeinfo"},
+	}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != "admin" || password != "secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/system/functions" {
+			http.NotFound(w, r)
+			return
+		}
+
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.calls = append(f.calls, r.Method)
+
+		switch r.Method {
+		case http.MethodGet:
+			statuses := []openFaaSStatus{}
+			for _, function := range f.functions {
+				statuses = append(statuses, openFaaSStatus{Name: function.Service, Namespace: function.Namespace, Labels: function.Labels})
+			}
+			json.NewEncoder(w).Encode(statuses)
+		case http.MethodPut, http.MethodPost:
+			var function openFaaSFunction
+			if err := json.NewDecoder(r.Body).Decode(&function); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			_, exists := f.functions[function.Service]
+			if r.Method == http.MethodPut && !exists {
+				http.Error(w, "function not found", http.StatusNotFound)
+				return
+			}
+			f.functions[function.Service] = function
+			w.WriteHeader(http.StatusAccepted)
+		case http.MethodDelete:
+			var body struct {
+				FunctionName string `json:"functionName"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			if _, exists := f.functions[body.FunctionName]; !exists {
+				http.Error(w, "function not found", http.StatusNotFound)
+				return
+			}
+			delete(f.functions, body.FunctionName)
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return f, server
+}
+
+func TestOpenFaaSDeployer(t *testing.T) {
+	t.Setenv(openFaaSPasswordEnv, "secret")
+	fake, server := newFakeOpenFaaS(t)
+
+	d := newOpenFaaSDeployer(config.DeploymentConfig{
+		Provider: "openfaas",
+		Region:   "lab",
+		Endpoint: server.URL,
+		Image:    "registry.example.com/bench:1",
+	})
+
+	testLifecycle(t, d, func(t *testing.T, functions map[string]DeployedFunction) {
+		gemm, ok := functions["gemm-128"]
+		if !ok {
+			t.Fatalf("gemm-128 not listed: %+v", functions)
+		}
+		if want := server.URL + "/function/b-gemm-lab-128"; gemm.URL != want {
+			t.Errorf("URL = %q, want %q", gemm.URL, want)
+		}
+
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		deployed := fake.functions["b-gemm-lab-128"]
+		if deployed.Image != "registry.example.com/bench:1" || deployed.EnvVars["BENCHMARK"] != "gemm" {
+			t.Errorf("deployed %+v", deployed)
+		}
+		if deployed.Limits == nil || deployed.Limits.Memory != "128Mi" {
+			t.Errorf("memory limit %+v, want 128Mi", deployed.Limits)
+		}
+		// the first deploy creates the functions after the update failed
+		// with 404, the second one updates them
+		if want := []string{"GET", "PUT", "POST", "PUT", "POST", "PUT", "PUT"}; !slices.Equal(fake.calls[:len(want)], want) {
+			t.Errorf("calls = %v, want %v first", fake.calls, want)
+		}
+	})
+
+	if _, exists := fake.functions["nodeinfo"]; !exists {
+		t.Error("removed a function not deployed by ClassiFaaS")
+	}
+}
+
+func TestOpenFaaSDeployerNamespace(t *testing.T) {
+	t.Setenv(openFaaSPasswordEnv, "secret")
+	_, server := newFakeOpenFaaS(t)
+
+	d := newOpenFaaSDeployer(config.DeploymentConfig{
+		Provider:  "openfaas",
+		Region:    "lab",
+		Endpoint:  server.URL,
+		Namespace: "bench",
+		Image:     "registry.example.com/bench:1",
+	})
+	ep := testPublisher(t)
+	if err := d.Deploy(context.Background(), ep, FunctionSelector{Benchmarks: []string{"json"}, MemorySizes: []int{512}}); err != nil {
+		t.Fatal(err)
+	}
+	functions, err := d.LoadDeployedFunctions(context.Background(), ep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := server.URL + "/function/b-json-lab-512.bench"; len(functions) != 1 || functions[0].URL != want {
+		t.Errorf("listed %+v, want URL %q", functions, want)
+	}
+}
+
+func TestOpenFaaSDeployerUnauthorized(t *testing.T) {
+	t.Setenv(openFaaSPasswordEnv, "wrong")
+	_, server := newFakeOpenFaaS(t)
+
+	d := newOpenFaaSDeployer(config.DeploymentConfig{Provider: "openfaas", Region: "lab", Endpoint: server.URL, Image: "bench"})
+	err := d.Deploy(context.Background(), testPublisher(t), FunctionSelector{Benchmarks: []string{"gemm"}, MemorySizes: []int{128}})
+	if !isStatus(err, http.StatusUnauthorized) {
+		t.Fatalf("got %v, want 401", err)
+	}
+}
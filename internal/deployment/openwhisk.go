@@ -0,0 +1,263 @@
+package deployment
+
+import (
+	"ClassiFaaS/internal/config"
+	"ClassiFaaS/internal/globals"
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// openWhiskAuthEnv holds the <uuid>:<key> auth of the OpenWhisk API, as in
+// the AUTH property of wsk.
+const openWhiskAuthEnv = "OPENWHISK_AUTH"
+
+// openWhiskPageSize is the number of actions listed per request, the maximum
+// of the OpenWhisk API.
+const openWhiskPageSize = 200
+
+// openWhiskAuthAnnotation makes OpenWhisk check the X-Require-Whisk-Auth
+// header of web action requests against its value.
+const openWhiskAuthAnnotation = "require-whisk-auth"
+
+// openWhiskPlatform deploys deployment/openwhisk and the shared benchmarks
+// as web actions through the OpenWhisk REST API. Every action gets a secret
+// that is sent in the X-Require-Whisk-Auth header.
+type openWhiskPlatform struct {
+	api       *apiClient
+	namespace string
+	kind      string
+
+	// code is the zipped action code, built once per deployer
+	codeOnce sync.Once
+	code     string
+	codeErr  error
+}
+
+func newOpenWhiskDeployer(cfg config.DeploymentConfig) deployer {
+	authorize := func(req *http.Request) {
+		if user, key, ok := strings.Cut(os.Getenv(openWhiskAuthEnv), ":"); ok {
+			req.SetBasicAuth(user, key)
+		}
+	}
+
+	namespace := cfg.Namespace
+	if namespace == "" {
+		namespace = "_"
+	}
+	kind := cfg.Runtime
+	if kind == "" {
+		kind = "nodejs:20"
+	}
+
+	return &selfHostedDeployer{
+		provider: "openwhisk",
+		region:   cfg.Region,
+		tags:     cfg.Tags,
+		platform: &openWhiskPlatform{
+			api:       newAPIClient(cfg.Endpoint, cfg.CAFile, authorize),
+			namespace: namespace,
+			kind:      kind,
+		},
+	}
+}
+
+type openWhiskKeyValue struct {
+	Key   string `json:"key"`
+	Value any    `json:"value"`
+}
+
+type openWhiskAction struct {
+	Name        string              `json:"name"`
+	Namespace   string              `json:"namespace,omitempty"`
+	Exec        *openWhiskExec      `json:"exec,omitempty"`
+	Parameters  []openWhiskKeyValue `json:"parameters,omitempty"`
+	Annotations []openWhiskKeyValue `json:"annotations,omitempty"`
+	Limits      *openWhiskLimits    `json:"limits,omitempty"`
+}
+
+type openWhiskExec struct {
+	Kind   string `json:"kind"`
+	Code   string `json:"code"`
+	Binary bool   `json:"binary"`
+	Main   string `json:"main,omitempty"`
+}
+
+type openWhiskLimits struct {
+	Memory int `json:"memory,omitempty"`
+}
+
+func (p *openWhiskPlatform) actionsPath() string {
+	return fmt.Sprintf("/api/v1/namespaces/%s/actions", url.PathEscape(p.namespace))
+}
+
+func (p *openWhiskPlatform) deployFunction(ctx context.Context, name string, f DeployedFunction, labels map[string]string) error {
+	code, err := p.actionCode()
+	if err != nil {
+		return err
+	}
+
+	secret := make([]byte, 16)
+	if _, err := rand.Read(secret); err != nil {
+		return err
+	}
+
+	annotations := []openWhiskKeyValue{
+		{Key: "web-export", Value: true},
+		// the benchmark parameter cannot be overridden by requests
+		{Key: "final", Value: true},
+		{Key: openWhiskAuthAnnotation, Value: hex.EncodeToString(secret)},
+	}
+	for key, value := range labels {
+		annotations = append(annotations, openWhiskKeyValue{Key: key, Value: value})
+	}
+
+	action := openWhiskAction{
+		Name:        name,
+		Exec:        &openWhiskExec{Kind: p.kind, Code: code, Binary: true, Main: "main"},
+		Parameters:  []openWhiskKeyValue{{Key: "benchmark", Value: f.Benchmark}},
+		Annotations: annotations,
+		Limits:      &openWhiskLimits{Memory: f.Memory},
+	}
+	return p.api.do(ctx, http.MethodPut, p.actionsPath()+"/"+url.PathEscape(name)+"?overwrite=true", "", action, nil)
+}
+
+func (p *openWhiskPlatform) listFunctions(ctx context.Context, region string) ([]platformFunction, error) {
+	// the namespace may hold more actions than fit on a page
+	var actions []openWhiskAction
+	for skip := 0; ; skip += openWhiskPageSize {
+		query := url.Values{"limit": {strconv.Itoa(openWhiskPageSize)}, "skip": {strconv.Itoa(skip)}}
+		var page []openWhiskAction
+		if err := p.api.do(ctx, http.MethodGet, p.actionsPath()+"?"+query.Encode(), "", nil, &page); err != nil {
+			return nil, err
+		}
+		actions = append(actions, page...)
+		if len(page) < openWhiskPageSize {
+			break
+		}
+	}
+
+	var functions []platformFunction
+	for _, brief := range actions {
+		if annotationString(brief.Annotations, labelRegion) != region {
+			continue
+		}
+		// the listing may omit the auth secret, so get the whole action
+		var action openWhiskAction
+		if err := p.api.do(ctx, http.MethodGet, p.actionsPath()+"/"+url.PathEscape(brief.Name), "", nil, &action); err != nil {
+			return nil, err
+		}
+
+		labels := make(map[string]string)
+		for _, label := range []string{labelBenchmark, labelMemory, labelRegion} {
+			labels[label] = annotationString(action.Annotations, label)
+		}
+		// web actions are addressed by the resolved namespace, not "_"
+		namespace := action.Namespace
+		if namespace == "" {
+			namespace = p.namespace
+		}
+		functions = append(functions, platformFunction{
+			name:   action.Name,
+			labels: labels,
+			url:    fmt.Sprintf("%s/api/v1/web/%s/default/%s.json", p.api.endpoint, namespace, url.PathEscape(action.Name)),
+			auth:   annotationString(action.Annotations, openWhiskAuthAnnotation),
+		})
+	}
+	return functions, nil
+}
+
+func annotationString(annotations []openWhiskKeyValue, key string) string {
+	for _, annotation := range annotations {
+		if annotation.Key == key {
+			if value, ok := annotation.Value.(string); ok {
+				return value
+			}
+			return fmt.Sprint(annotation.Value)
+		}
+	}
+	return ""
+}
+
+func (p *openWhiskPlatform) deleteFunction(ctx context.Context, name string) error {
+	return p.api.do(ctx, http.MethodDelete, p.actionsPath()+"/"+url.PathEscape(name), "", nil, nil)
+}
+
+func (p *openWhiskPlatform) codeHash() (string, error) {
+	return codeHash(globals.OpenWhiskDeploymentFolder, globals.SharedDeploymentFolder)
+}
+
+// actionCode zips the action folder, including its node_modules, with the
+// shared benchmarks below shared/.
+func (p *openWhiskPlatform) actionCode() (string, error) {
+	p.codeOnce.Do(func() {
+		var buf bytes.Buffer
+		archive := zip.NewWriter(&buf)
+		if err := zipFolder(archive, globals.OpenWhiskDeploymentFolder, ""); err != nil {
+			p.codeErr = err
+			return
+		}
+		if err := zipFolder(archive, globals.SharedDeploymentFolder, "shared"); err != nil {
+			p.codeErr = err
+			return
+		}
+		if err := archive.Close(); err != nil {
+			p.codeErr = err
+			return
+		}
+		p.code = base64.StdEncoding.EncodeToString(buf.Bytes())
+	})
+	if p.codeErr != nil {
+		return "", fmt.Errorf("failed to package action code: %v", p.codeErr)
+	}
+	return p.code, nil
+}
+
+// zipFolder adds the files below dir to the archive, below prefix. Hidden
+// folders and a stale shared copy are skipped.
+func zipFolder(archive *zip.Writer, dir, prefix string) error {
+	return filepath.WalkDir(dir, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if file != dir && (strings.HasPrefix(d.Name(), ".") || (prefix == "" && rel == "shared")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		w, err := archive.Create(path.Join(prefix, filepath.ToSlash(rel)))
+		if err != nil {
+			return err
+		}
+		src, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		_, err = io.Copy(w, src)
+		return err
+	})
+}
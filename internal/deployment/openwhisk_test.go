@@ -0,0 +1,195 @@
+package deployment
+
+import (
+	"ClassiFaaS/internal/config"
+	"ClassiFaaS/internal/globals"
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeOpenWhisk is a stubbed OpenWhisk API keeping the actions of the guest
+// namespace in memory.
+type fakeOpenWhisk struct {
+	mu      sync.Mutex
+	actions map[string]openWhiskAction
+	// pages counts the list requests
+	pages int
+}
+
+func newFakeOpenWhisk(t *testing.T, unrelated int) (*fakeOpenWhisk, *httptest.Server) {
+	f := &fakeOpenWhisk{actions: make(map[string]openWhiskAction)}
+	// actions not deployed by ClassiFaaS, listed before the benchmark ones
+	for i := 0; i < unrelated; i++ {
+		name := fmt.Sprintf("a-other-%03d", i)
+		f.actions[name] = openWhiskAction{Name: name, Namespace: "guest"}
+	}
+	const prefix = "/api/v1/namespaces/_/actions"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, key, ok := r.BasicAuth(); !ok || user != "uuid" || key != "key" {
+			http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+			return
+		}
+		name, ok := strings.CutPrefix(r.URL.Path, prefix)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		name = strings.TrimPrefix(name, "/")
+
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		switch {
+		case r.Method == http.MethodGet && name == "":
+			f.pages++
+			limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+			skip, _ := strconv.Atoi(r.URL.Query().Get("skip"))
+			if limit <= 0 || limit > 200 {
+				http.Error(w, `{"error":"invalid limit"}`, http.StatusBadRequest)
+				return
+			}
+			names := make([]string, 0, len(f.actions))
+			for name := range f.actions {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			// the listing omits the parameters, like the real one
+			page := []openWhiskAction{}
+			for i := skip; i < len(names) && i < skip+limit; i++ {
+				action := f.actions[names[i]]
+				page = append(page, openWhiskAction{Name: action.Name, Namespace: action.Namespace, Annotations: action.Annotations})
+			}
+			json.NewEncoder(w).Encode(page)
+		case r.Method == http.MethodGet:
+			action, exists := f.actions[name]
+			if !exists {
+				http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+				return
+			}
+			json.NewEncoder(w).Encode(action)
+		case r.Method == http.MethodPut:
+			if _, exists := f.actions[name]; exists && r.URL.Query().Get("overwrite") != "true" {
+				http.Error(w, `{"error":"exists"}`, http.StatusConflict)
+				return
+			}
+			var action openWhiskAction
+			if err := json.NewDecoder(r.Body).Decode(&action); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			action.Namespace = "guest"
+			f.actions[name] = action
+			json.NewEncoder(w).Encode(action)
+		case r.Method == http.MethodDelete:
+			if _, exists := f.actions[name]; !exists {
+				http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+				return
+			}
+			delete(f.actions, name)
+			w.Write([]byte("{}"))
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return f, server
+}
+
+func newTestOpenWhiskDeployer(t *testing.T, endpoint string) deployer {
+	t.Setenv(openWhiskAuthEnv, "uuid:key")
+	setFolder(t, &globals.OpenWhiskDeploymentFolder, map[string]string{"index.js": "exports.main = () => ({})"})
+	setFolder(t, &globals.SharedDeploymentFolder, map[string]string{"gemm.js": "module.exports = {}"})
+	return newOpenWhiskDeployer(config.DeploymentConfig{Provider: "openwhisk", Region: "lab", Endpoint: endpoint})
+}
+
+func TestOpenWhiskDeployer(t *testing.T) {
+	// more unrelated actions than fit on a page
+	fake, server := newFakeOpenWhisk(t, 250)
+	d := newTestOpenWhiskDeployer(t, server.URL)
+
+	testLifecycle(t, d, func(t *testing.T, functions map[string]DeployedFunction) {
+		gemm, ok := functions["gemm-128"]
+		if !ok {
+			t.Fatalf("gemm-128 not listed: %+v", functions)
+		}
+		if want := server.URL + "/api/v1/web/guest/default/b-gemm-lab-128.json"; gemm.URL != want {
+			t.Errorf("URL = %q, want %q", gemm.URL, want)
+		}
+
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		action := fake.actions["b-gemm-lab-128"]
+		if secret := annotationString(action.Annotations, openWhiskAuthAnnotation); secret == "" || gemm.Auth != secret {
+			t.Errorf("auth = %q, want the action's secret %q", gemm.Auth, secret)
+		}
+		if annotationString(action.Annotations, "web-export") != "true" {
+			t.Error("action is not web-exported")
+		}
+		if action.Limits == nil || action.Limits.Memory != 128 {
+			t.Errorf("limits = %+v, want 128 MB", action.Limits)
+		}
+		if action.Exec == nil || action.Exec.Kind != "nodejs:20" {
+			t.Fatalf("exec = %+v, want kind nodejs:20", action.Exec)
+		}
+		if files := zipFiles(t, action.Exec.Code); !files["index.js"] || !files["shared/gemm.js"] {
+			t.Errorf("action code holds %v, want index.js and shared/gemm.js", files)
+		}
+		if fake.pages < 2 {
+			t.Errorf("listed %d pages, want at least 2", fake.pages)
+		}
+	})
+
+	if len(fake.actions) != 250 {
+		t.Errorf("%d actions left, want the 250 unrelated ones", len(fake.actions))
+	}
+}
+
+func TestOpenWhiskDeployerPageBoundary(t *testing.T) {
+	// the benchmark actions complete a full page, so another, empty page
+	// must be requested
+	fake, server := newFakeOpenWhisk(t, 199)
+	d := newTestOpenWhiskDeployer(t, server.URL)
+	ep := testPublisher(t)
+
+	if err := d.Deploy(context.Background(), ep, FunctionSelector{Benchmarks: []string{"json"}, MemorySizes: []int{128}}); err != nil {
+		t.Fatal(err)
+	}
+	fake.pages = 0
+	functions, err := d.LoadDeployedFunctions(context.Background(), ep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(functions) != 1 || fake.pages != 2 {
+		t.Errorf("listed %d functions in %d pages, want 1 in 2", len(functions), fake.pages)
+	}
+}
+
+// zipFiles lists the files of base64-encoded zipped action code.
+func zipFiles(t *testing.T, code string) map[string]bool {
+	t.Helper()
+	data, err := base64.StdEncoding.DecodeString(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]bool)
+	for _, file := range archive.File {
+		files[file.Name] = true
+	}
+	return files
+}
@@ -0,0 +1,202 @@
+package deployment
+
+import (
+	"ClassiFaaS/internal/utils"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+)
+
+type PlanAction string
+
+const (
+	PlanCreate    PlanAction = "create"
+	PlanUpdate    PlanAction = "update"
+	PlanUnchanged PlanAction = "unchanged"
+	// PlanOrphaned marks a deployed function that is no longer configured.
+	// Deploying leaves it in place, only remove deletes it.
+	PlanOrphaned PlanAction = "orphaned"
+)
+
+// PlannedChange is what deploying would do to one function. Memory is 0 for
+// a benchmark deployed at every memory size of the provider.
+type PlannedChange struct {
+	Action    PlanAction
+	Provider  string
+	Region    string
+	Benchmark string
+	Memory    int
+}
+
+// Plan lists the changes of a deployment, ordered by target.
+type Plan struct {
+	Changes []PlannedChange
+}
+
+// Count returns the number of changes with the given action.
+func (p *Plan) Count(action PlanAction) int {
+	n := 0
+	for _, c := range p.Changes {
+		if c.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// Plan compares the configured deployments with the functions recorded in
+// the state file, without running any provider script. With refresh, the
+// deployed functions are listed by the providers instead. A recorded function
+// is updated if the function code changed since it was deployed. Only
+// changes selected by sel are planned.
+func (oc *DeployOrechestratorClient) Plan(ctx context.Context, ep utils.EventPublisher, refresh bool, sel Selector) (*Plan, error) {
+	targets, err := oc.allTargets()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(targets))
+	for name, tc := range targets {
+		if sel.matchesTarget(tc) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	plan := &Plan{}
+	for _, name := range names {
+		tc := targets[name]
+
+		oc.stateMu.Lock()
+		var deployed []DeployedFunction
+		if target, ok := oc.state.Targets[name]; ok {
+			deployed = target.Functions
+		}
+		oc.stateMu.Unlock()
+
+		if refresh {
+			// the providers do not know the code hashes, so they are
+			// taken from the state. A target without functions was not
+			// deployed yet.
+			listed, err := tc.deployer.LoadDeployedFunctions(ctx, ep)
+			if errors.Is(err, errNoFunctions) {
+				listed, err = nil, nil
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to list functions of %s: %v", name, err)
+			}
+			deployed = withCodeHashes(listed, deployed)
+		}
+
+		var changes []PlannedChange
+		if _, ok := oc.deployments[name]; ok {
+			hash, err := tc.deployer.CodeHash()
+			if err != nil {
+				return nil, err
+			}
+			changes = oc.targetChanges(tc, deployed, hash)
+		} else {
+			changes = orphanedChanges(tc, deployed)
+		}
+
+		for _, c := range changes {
+			if c.selectedBy(sel.FunctionSelector) {
+				plan.Changes = append(plan.Changes, c)
+			}
+		}
+	}
+	return plan, nil
+}
+
+// selectedBy reports whether s selects the change. Changes to all benchmarks
+// or memory sizes are always selected.
+func (c PlannedChange) selectedBy(s FunctionSelector) bool {
+	if c.Benchmark != "" && s.Benchmarks != nil && !slices.Contains(s.Benchmarks, c.Benchmark) {
+		return false
+	}
+	return c.Memory == 0 || s.MemorySizes == nil || slices.Contains(s.MemorySizes, c.Memory)
+}
+
+// targetChanges compares the configured benchmarks and memory sizes with the
+// deployed functions of a configured target. Deployed functions whose code
+// hash differs from hash are updated, those no longer configured are
+// orphaned.
+func (oc *DeployOrechestratorClient) targetChanges(tc DeployTargetClient, deployed []DeployedFunction, hash string) []PlannedChange {
+	existing := func(f DeployedFunction) PlanAction {
+		if f.CodeHash != hash {
+			return PlanUpdate
+		}
+		return PlanUnchanged
+	}
+
+	var benchmarks []string
+	for benchmark, parameter := range oc.benchmarks {
+		if parameter > 0 {
+			benchmarks = append(benchmarks, benchmark)
+		}
+	}
+	sort.Strings(benchmarks)
+
+	change := func(action PlanAction, benchmark string, memory int) PlannedChange {
+		return PlannedChange{Action: action, Provider: tc.provider, Region: tc.region, Benchmark: benchmark, Memory: memory}
+	}
+
+	var changes []PlannedChange
+	wanted := make(map[DeployedFunction]bool)
+	for _, benchmark := range benchmarks {
+		if oc.memorySizes == nil {
+			found := false
+			for _, f := range sortedFunctions(deployed) {
+				if f.Benchmark == benchmark {
+					changes = append(changes, change(existing(f), benchmark, f.Memory))
+					wanted[f] = true
+					found = true
+				}
+			}
+			if !found {
+				changes = append(changes, change(PlanCreate, benchmark, 0))
+			}
+			continue
+		}
+
+		for _, memory := range oc.memorySizes {
+			action := PlanCreate
+			for _, f := range deployed {
+				if f.Benchmark == benchmark && f.Memory == memory {
+					action = existing(f)
+					wanted[f] = true
+				}
+			}
+			changes = append(changes, change(action, benchmark, memory))
+		}
+	}
+
+	for _, f := range sortedFunctions(deployed) {
+		if !wanted[f] {
+			changes = append(changes, change(PlanOrphaned, f.Benchmark, f.Memory))
+		}
+	}
+	return changes
+}
+
+// orphanedChanges lists the deployed functions of a target that is no longer
+// configured.
+func orphanedChanges(tc DeployTargetClient, deployed []DeployedFunction) []PlannedChange {
+	var changes []PlannedChange
+	for _, f := range sortedFunctions(deployed) {
+		changes = append(changes, PlannedChange{Action: PlanOrphaned, Provider: tc.provider, Region: tc.region, Benchmark: f.Benchmark, Memory: f.Memory})
+	}
+	return changes
+}
+
+func sortedFunctions(funcs []DeployedFunction) []DeployedFunction {
+	sorted := append([]DeployedFunction(nil), funcs...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Benchmark != sorted[j].Benchmark {
+			return sorted[i].Benchmark < sorted[j].Benchmark
+		}
+		return sorted[i].Memory < sorted[j].Memory
+	})
+	return sorted
+}
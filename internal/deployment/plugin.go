@@ -0,0 +1,274 @@
+package deployment
+
+import (
+	"ClassiFaaS/internal/config"
+	"ClassiFaaS/internal/globals"
+	"ClassiFaaS/internal/utils"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// pluginManifestTimeout bounds how long a plugin executable may take to
+// print its manifest.
+const pluginManifestTimeout = 10 * time.Second
+
+// PluginManifest declares a provider added by a deployer plugin. Plugin
+// folders contain it as manifest.json; plugin executables print it when
+// called with the "manifest" action.
+type PluginManifest struct {
+	// Version is the script protocol version spoken by the plugin.
+	Version int    `json:"version"`
+	Name    string `json:"name"`
+	// Regions lists the valid regions; empty allows any region.
+	Regions []string `json:"regions,omitempty"`
+	Auth    struct {
+		// Key is the header carrying the functions' auth value; empty if
+		// the functions need no auth.
+		Key string `json:"key,omitempty"`
+	} `json:"auth"`
+	// Capabilities lists the supported actions out of deploy, get-urls and
+	// delete; empty means all.
+	Capabilities []string `json:"capabilities,omitempty"`
+	// Command is the executable of a plugin folder, relative to it.
+	// Defaults to manage-deployment.sh, which is run with bash.
+	Command string `json:"command,omitempty"`
+
+	// source is the plugin folder or executable.
+	source string
+}
+
+var pluginActions = []string{"deploy", "get-urls", "delete"}
+
+// LoadPlugins registers the deployer plugins found in the plugin folder and
+// the classifaas-deployer-<name> executables on the PATH, so that their
+// providers can be deployed and benchmarked like the built-in ones. Plugins
+// are only looked for if one of providers is not registered yet, so that
+// configs using built-in providers only do not depend on the installed
+// plugins. Plugins that fail to load are skipped with a warning, as are
+// plugins for a provider that is already registered, built in or by an
+// earlier plugin.
+func LoadPlugins(ep utils.EventPublisher, providers []string) []PluginManifest {
+	missing := false
+	for _, provider := range providers {
+		if _, exists := deployers[provider]; !exists {
+			missing = true
+		}
+	}
+	if !missing {
+		return nil
+	}
+
+	warn := func(err error) {
+		ep.SendEvent(utils.SeverityWarning, "load_plugins", fmt.Sprintf("Skipping deployer plugin: %v", err))
+	}
+
+	var loaded []PluginManifest
+	for _, manifest := range append(pluginFolders(globals.DeploymentPluginFolder, warn), pluginExecutables(warn)...) {
+		if _, exists := deployers[manifest.Name]; exists {
+			continue
+		}
+		if err := registerPlugin(manifest); err != nil {
+			warn(fmt.Errorf("failed to load deployer plugin %s: %v", manifest.source, err))
+			continue
+		}
+		loaded = append(loaded, manifest)
+	}
+	return loaded
+}
+
+// ConfiguredProviders returns the providers named by the deployment or
+// benchmark config at path, without validating it, and those recorded in the
+// deployment state of a deployment config. It returns nil if the config
+// cannot be read, leaving the error to loading the config.
+func ConfiguredProviders(path string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var cfg struct {
+		Deployments []struct {
+			Provider string `yaml:"provider"`
+		} `yaml:"deployments"`
+		Functions []struct {
+			Provider string `yaml:"provider"`
+		} `yaml:"functions"`
+		State string `yaml:"state"`
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil
+	}
+
+	var providers []string
+	for _, d := range cfg.Deployments {
+		providers = append(providers, d.Provider)
+	}
+	for _, fn := range cfg.Functions {
+		providers = append(providers, fn.Provider)
+	}
+
+	// removing a deployment no longer configured needs its provider too
+	if len(cfg.Deployments) > 0 {
+		if cfg.State == "" {
+			cfg.State = globals.DeploymentStateFile
+		}
+		if state, err := LoadState(cfg.State); err == nil {
+			for _, target := range state.Targets {
+				providers = append(providers, target.Provider)
+			}
+		}
+	}
+	return providers
+}
+
+// pluginFolders reads the manifests of the plugin folders below dir. Folders
+// whose manifest cannot be read are passed to warn and skipped.
+func pluginFolders(dir string, warn func(error)) []PluginManifest {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		warn(err)
+		return nil
+	}
+
+	var manifests []PluginManifest
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		folder := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(filepath.Join(folder, "manifest.json"))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			warn(err)
+			continue
+		}
+
+		var manifest PluginManifest
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			warn(fmt.Errorf("failed to parse manifest of deployer plugin %s: %v", folder, err))
+			continue
+		}
+		manifest.source = folder
+		manifests = append(manifests, manifest)
+	}
+	return manifests
+}
+
+// pluginExecutables asks the plugin executables on the PATH for their
+// manifests. An executable hidden by an earlier one of the same name is
+// skipped, as by the shell, as are executables whose manifest cannot be
+// read after passing the error to warn.
+func pluginExecutables(warn func(error)) []PluginManifest {
+	seen := make(map[string]bool)
+	var manifests []PluginManifest
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		matches, _ := filepath.Glob(filepath.Join(dir, globals.DeployerPluginPrefix+"*"))
+		for _, path := range matches {
+			name := filepath.Base(path)
+			info, err := os.Stat(path)
+			if err != nil || info.IsDir() || info.Mode()&0111 == 0 || seen[name] {
+				continue
+			}
+			seen[name] = true
+
+			manifest, err := executableManifest(path)
+			if err != nil {
+				warn(err)
+				continue
+			}
+			if manifest.Name == "" {
+				manifest.Name = strings.TrimPrefix(name, globals.DeployerPluginPrefix)
+			}
+			manifests = append(manifests, manifest)
+		}
+	}
+	return manifests
+}
+
+func executableManifest(path string) (PluginManifest, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), pluginManifestTimeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, path, "manifest").Output()
+	if err != nil {
+		return PluginManifest{}, fmt.Errorf("failed to get manifest of deployer plugin %s: %v", path, err)
+	}
+	var manifest PluginManifest
+	if err := json.Unmarshal(output, &manifest); err != nil {
+		return PluginManifest{}, fmt.Errorf("failed to parse manifest of deployer plugin %s: %v", path, err)
+	}
+	manifest.source = path
+	return manifest, nil
+}
+
+// registerPlugin adds the plugin's provider to the deployer registry and the
+// config validation.
+func registerPlugin(manifest PluginManifest) error {
+	if manifest.Name == "" {
+		return fmt.Errorf("manifest declares no provider name")
+	}
+	if manifest.Version > scriptProtocolVersion {
+		return fmt.Errorf("plugin speaks protocol version %d, expected at most %d", manifest.Version, scriptProtocolVersion)
+	}
+	for _, action := range manifest.Capabilities {
+		if !slices.Contains(pluginActions, action) {
+			return fmt.Errorf("unknown capability %q, expected one of %v", action, pluginActions)
+		}
+	}
+
+	var scriptDir string
+	var script []string
+	if info, err := os.Stat(manifest.source); err == nil && info.IsDir() {
+		scriptDir = manifest.source
+		if manifest.Command == "" {
+			script = []string{"bash", "./manage-deployment.sh"}
+		} else {
+			script = []string{"./" + filepath.Clean(manifest.Command)}
+		}
+	} else {
+		script = []string{manifest.source}
+	}
+
+	if err := config.RegisterProvider(manifest.Name, manifest.Regions, manifest.Auth.Key); err != nil {
+		return err
+	}
+	registerDeployer(manifest.Name, func(cfg config.DeploymentConfig) deployer {
+		return &pluginDeployer{
+			scriptDefaultDeployer: scriptDefaultDeployer{
+				provider:  manifest.Name,
+				region:    cfg.Region,
+				scriptDir: scriptDir,
+				runtime:   cfg.Runtime,
+				tags:      cfg.Tags,
+				script:    script,
+				actions:   manifest.Capabilities,
+			},
+			source: manifest.source,
+		}
+	})
+	return nil
+}
+
+// pluginDeployer runs a plugin folder's command or a plugin executable
+// instead of a built-in deployment script.
+type pluginDeployer struct {
+	scriptDefaultDeployer
+	source string
+}
+
+func (d *pluginDeployer) CodeHash() (string, error) {
+	return codeHash(d.source)
+}
@@ -0,0 +1,138 @@
+package deployment
+
+import (
+	"ClassiFaaS/internal/utils"
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// scriptProtocolVersion is the version of the JSON protocol spoken with the
+// deployment scripts.
+//
+// The script receives a scriptRequest as JSON in the CLASSIFAAS_REQUEST
+// environment variable, in addition to the action and region arguments. It
+// reports back by printing scriptEvents as JSON lines on stdout. Any other
+// output is published as a plain log line, so scripts may keep printing the
+// output of the provider CLIs.
+const scriptProtocolVersion = 1
+
+// scriptRequestEnv is the environment variable holding the scriptRequest.
+const scriptRequestEnv = "CLASSIFAAS_REQUEST"
+
+type scriptRequest struct {
+	Version int    `json:"version"`
+	Action  string `json:"action"`
+	Region  string `json:"region"`
+	// Benchmarks and MemorySizes are empty to select all functions.
+	Benchmarks  []string          `json:"benchmarks,omitempty"`
+	MemorySizes []int             `json:"memorySizes,omitempty"`
+	Runtime     string            `json:"runtime,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+}
+
+// Types of the events printed by the scripts.
+const (
+	scriptEventProgress         = "progress"
+	scriptEventWarning          = "warning"
+	scriptEventFunctionDeployed = "function-deployed"
+	scriptEventResult           = "result"
+)
+
+type scriptEvent struct {
+	Version int    `json:"version,omitempty"`
+	Type    string `json:"type"`
+	Message string `json:"message,omitempty"`
+	// Function is set by function-deployed events.
+	Function *DeployedFunction `json:"function,omitempty"`
+	// Status of a result event, "ok" or "error".
+	Status string `json:"status,omitempty"`
+}
+
+// scriptOutput collects what a script reported.
+type scriptOutput struct {
+	functions []DeployedFunction
+	// result is the message of a failed result event
+	result error
+}
+
+// readScriptOutput publishes the events and log lines read from a script's
+// stdout until it is closed.
+func (d *scriptDefaultDeployer) readScriptOutput(pipe io.Reader, ep utils.EventPublisher, logType string) (*scriptOutput, error) {
+	out := &scriptOutput{}
+	scanner := bufio.NewScanner(pipe)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if err := d.handleScriptLine(line, ep, logType, out); err != nil {
+			return out, err
+		}
+	}
+	// a pipe closed by exec.Cmd.Wait ends the output
+	if err := scanner.Err(); err != nil && !errors.Is(err, os.ErrClosed) {
+		return out, fmt.Errorf("error reading script output: %v", err)
+	}
+	return out, nil
+}
+
+func (d *scriptDefaultDeployer) handleScriptLine(line string, ep utils.EventPublisher, logType string, out *scriptOutput) error {
+	var event scriptEvent
+	if !strings.HasPrefix(line, "{") || json.Unmarshal([]byte(line), &event) != nil {
+		ep.SendScopedEvent(d.scope(), utils.SeverityInfo, logType, line)
+		return nil
+	}
+
+	if event.Version > scriptProtocolVersion {
+		return fmt.Errorf("script speaks protocol version %d, expected at most %d", event.Version, scriptProtocolVersion)
+	}
+
+	switch event.Type {
+	case scriptEventProgress:
+		ep.SendScopedEvent(d.scope(), utils.SeverityInfo, "script_progress", event.Message)
+	case scriptEventWarning:
+		ep.SendScopedEvent(d.scope(), utils.SeverityWarning, "script_warning", event.Message)
+	case scriptEventFunctionDeployed:
+		if event.Function == nil {
+			return fmt.Errorf("function-deployed event without function: %s", line)
+		}
+		out.addFunction(d, *event.Function, ep)
+	case scriptEventResult:
+		if event.Status == "error" {
+			out.result = errors.New(event.Message)
+			ep.SendScopedEvent(d.scope(), utils.SeverityError, "script_result", event.Message)
+		} else {
+			ep.SendScopedEvent(d.scope(), utils.SeverityInfo, "script_result", event.Message)
+		}
+	case "":
+		// the function lines printed by get-urls before the protocol existed
+		var f DeployedFunction
+		if err := json.Unmarshal([]byte(line), &f); err != nil || f.URL == "" {
+			ep.SendScopedEvent(d.scope(), utils.SeverityInfo, logType, line)
+			return nil
+		}
+		out.addFunction(d, f, ep)
+	default:
+		ep.SendScopedEvent(d.scope(), utils.SeverityWarning, "script_warning", fmt.Sprintf("unknown script event %q: %s", event.Type, event.Message))
+	}
+	return nil
+}
+
+func (out *scriptOutput) addFunction(d *scriptDefaultDeployer, f DeployedFunction, ep utils.EventPublisher) {
+	f.Provider = d.provider
+	if f.Region == "" {
+		f.Region = d.region
+	}
+	out.functions = append(out.functions, f)
+
+	scope := d.scope()
+	scope.Function = fmt.Sprintf("%s-%d", f.Benchmark, f.Memory)
+	// scripts report their functions when listing them, not when deploying
+	ep.SendScopedEvent(scope, utils.SeverityInfo, "function_discovered", f.URL)
+}
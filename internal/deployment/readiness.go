@@ -0,0 +1,246 @@
+package deployment
+
+import (
+	"ClassiFaaS/internal/auth"
+	"ClassiFaaS/internal/globals"
+	"ClassiFaaS/internal/utils"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// readinessParameter keeps the benchmark of a readiness invocation tiny.
+const readinessParameter = 1
+
+// readinessRequestTimeout bounds a single readiness invocation.
+const readinessRequestTimeout = time.Minute
+
+// ReadinessResult is the outcome of checking one deployed function.
+type ReadinessResult struct {
+	Provider  string
+	Region    string
+	Benchmark string
+	Memory    int
+	Ready     bool
+	Attempts  int
+	// Elapsed is the time until the function was ready or given up on.
+	Elapsed time.Duration
+	// Error is the failure of the last attempt if the function is not ready.
+	Error string
+}
+
+// Readiness lists the checked functions by provider, region, benchmark and
+// memory size.
+type Readiness struct {
+	Results []ReadinessResult
+}
+
+// ReadyCount returns the number of ready functions.
+func (r *Readiness) ReadyCount() int {
+	count := 0
+	for _, result := range r.Results {
+		if result.Ready {
+			count++
+		}
+	}
+	return count
+}
+
+// CheckReadiness invokes every deployed function selected by sel once with a
+// tiny parameter, retrying until it returns a benchmark response of the
+// expected type or the readiness timeout passes. It returns an error if a
+// function did not become ready, together with the results of all checked
+// functions.
+func (oc *DeployOrechestratorClient) CheckReadiness(ctx context.Context, ep utils.EventPublisher, sel Selector) (*Readiness, error) {
+	targets, err := sel.targets(oc.deployments)
+	if err != nil {
+		return nil, err
+	}
+	functions, err := oc.deployFunctions(sel.FunctionSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	readiness := &Readiness{}
+	var mu sync.Mutex
+
+	// functions are retried until the readiness timeout instead of
+	// retrying the whole step
+	tl := utils.NewTimeline("Check Readiness", utils.RunParallel)
+	names := make([]string, 0, len(targets))
+	for name := range targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		tc := targets[name]
+		tl.AddStep(&utils.Step{
+			Name: name,
+			RunContext: func(ctx context.Context, ep utils.EventPublisher) error {
+				results, err := tc.checkReadiness(ctx, ep, functions, oc.readiness.Timeout, oc.readiness.Interval)
+				mu.Lock()
+				readiness.Results = append(readiness.Results, results...)
+				mu.Unlock()
+				return err
+			},
+		})
+	}
+
+	err = tl.RunContext(ctx, ep)
+
+	sort.Slice(readiness.Results, func(i, j int) bool {
+		a, b := readiness.Results[i], readiness.Results[j]
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		if a.Region != b.Region {
+			return a.Region < b.Region
+		}
+		if a.Benchmark != b.Benchmark {
+			return a.Benchmark < b.Benchmark
+		}
+		return a.Memory < b.Memory
+	})
+	return readiness, err
+}
+
+// checkReadiness checks the target's deployed functions selected by
+// functions in parallel.
+func (tc *DeployTargetClient) checkReadiness(ctx context.Context, ep utils.EventPublisher, functions FunctionSelector, timeout, interval time.Duration) ([]ReadinessResult, error) {
+	deployed, err := tc.deployer.LoadDeployedFunctions(ctx, ep)
+	if err != nil {
+		return nil, err
+	}
+
+	var selected []DeployedFunction
+	for _, f := range deployed {
+		if functions.Matches(f) {
+			selected = append(selected, f)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no deployed functions selected")
+	}
+
+	client := &http.Client{Timeout: readinessRequestTimeout}
+	results := make([]ReadinessResult, len(selected))
+	var wg sync.WaitGroup
+	for i, f := range selected {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = tc.waitReady(ctx, ep, client, f, timeout, interval)
+		}()
+	}
+	wg.Wait()
+
+	notReady := 0
+	for _, result := range results {
+		if !result.Ready {
+			notReady++
+		}
+	}
+	if notReady > 0 {
+		return results, fmt.Errorf("%d of %d functions not ready", notReady, len(results))
+	}
+	return results, nil
+}
+
+// waitReady invokes f until it is ready, the timeout passes or ctx is
+// cancelled.
+func (tc *DeployTargetClient) waitReady(ctx context.Context, ep utils.EventPublisher, client *http.Client, f DeployedFunction, timeout, interval time.Duration) ReadinessResult {
+	result := ReadinessResult{
+		Provider:  f.Provider,
+		Region:    f.Region,
+		Benchmark: f.Benchmark,
+		Memory:    f.Memory,
+	}
+	scope := tc.scope()
+	scope.Function = fmt.Sprintf("%s-%d", f.Benchmark, f.Memory)
+
+	start := time.Now()
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	for {
+		result.Attempts++
+		err := invokeForReadiness(ctx, client, f)
+		result.Elapsed = time.Since(start)
+		if err == nil {
+			result.Ready = true
+			result.Error = ""
+			ep.SendScopedEvent(scope, utils.SeverityInfo, "readiness", fmt.Sprintf("Ready after %d attempts (%s)", result.Attempts, result.Elapsed.Round(time.Millisecond)))
+			return result
+		}
+		result.Error = err.Error()
+		if result.Attempts == 1 {
+			ep.SendScopedEvent(scope, utils.SeverityInfo, "readiness", fmt.Sprintf("Not ready yet: %v", err))
+		}
+
+		select {
+		case <-time.After(interval):
+		case <-deadline.C:
+			ep.SendScopedEvent(scope, utils.SeverityError, "readiness", fmt.Sprintf("Not ready after %d attempts: %v", result.Attempts, err))
+			return result
+		case <-ctx.Done():
+			result.Elapsed = time.Since(start)
+			ep.SendScopedEvent(scope, utils.SeverityError, "readiness", fmt.Sprintf("Readiness check interrupted: %v", err))
+			return result
+		}
+	}
+}
+
+// invokeForReadiness invokes f once with the readiness parameter and checks
+// that it returns a benchmark response of its benchmark.
+func invokeForReadiness(ctx context.Context, client *http.Client, f DeployedFunction) error {
+	target, err := url.Parse(f.URL)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %v", err)
+	}
+	query := target.Query()
+	query.Set("parameter", strconv.Itoa(readinessParameter))
+	target.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	authValue := f.Auth
+	if f.Provider == "gcp" {
+		token, err := auth.GetGoogleIdentityToken(f.URL)
+		if err != nil {
+			return err
+		}
+		authValue = "Bearer " + token
+	}
+	if key := globals.AuthKeys[f.Provider]; key != "" && authValue != "" {
+		req.Header.Set(key, authValue)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	response, err := utils.DecodeBenchmarkResponse(resp)
+	if err != nil {
+		return fmt.Errorf("failed to decode benchmark response: %v", err)
+	}
+	benchmarkType, ok := response.LookupString("benchmark.type")
+	if !ok {
+		return fmt.Errorf("response has no benchmark type")
+	}
+	if benchmarkType != f.Benchmark {
+		return fmt.Errorf("response has benchmark type %q, expected %q", benchmarkType, f.Benchmark)
+	}
+	return nil
+}
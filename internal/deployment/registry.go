@@ -33,4 +33,16 @@ func init() {
 	registerDeployer("alibaba", deployerFactory(func(cfg config.DeploymentConfig) deployer {
 		return newAlibabaDeployer(cfg)
 	}))
+	registerDeployer("local", deployerFactory(func(cfg config.DeploymentConfig) deployer {
+		return newLocalDeployer(cfg)
+	}))
+	registerDeployer("openfaas", deployerFactory(func(cfg config.DeploymentConfig) deployer {
+		return newOpenFaaSDeployer(cfg)
+	}))
+	registerDeployer("knative", deployerFactory(func(cfg config.DeploymentConfig) deployer {
+		return newKnativeDeployer(cfg)
+	}))
+	registerDeployer("openwhisk", deployerFactory(func(cfg config.DeploymentConfig) deployer {
+		return newOpenWhiskDeployer(cfg)
+	}))
 }
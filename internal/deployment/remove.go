@@ -1,32 +1,107 @@
 package deployment
 
 import (
+	"ClassiFaaS/internal/config"
 	"ClassiFaaS/internal/utils"
+	"context"
 	"fmt"
 )
 
-func (tc *DeployTargetClient) Remove(ep utils.EventPublisher) error {
-	ep.SendEvent(utils.SeverityInfo, fmt.Sprintf("remove_%s", tc.provider), fmt.Sprintf("Starting removal in %s region", tc.region))
+func (tc *DeployTargetClient) Remove(ctx context.Context, ep utils.EventPublisher, functions FunctionSelector) error {
+	if functions.All() {
+		ep.SendScopedEvent(tc.scope(), utils.SeverityInfo, "remove", "Starting removal")
+	} else {
+		benchmarks := "all"
+		if functions.Benchmarks != nil {
+			benchmarks = fmt.Sprint(functions.Benchmarks)
+		}
+		ep.SendScopedEvent(tc.scope(), utils.SeverityInfo, "remove", fmt.Sprintf("Starting removal of benchmarks %s at memory sizes %s", benchmarks, memorySizesString(functions.MemorySizes)))
+	}
 
-	if err := tc.deployer.Remove(ep); err != nil {
-		ep.SendEvent(utils.SeverityError, fmt.Sprintf("remove_%s", tc.provider), fmt.Sprintf("Removal failed in %s region: %v", tc.region, err))
+	if err := tc.deployer.Remove(ctx, ep, functions); err != nil {
+		ep.SendScopedEvent(tc.scope(), utils.SeverityError, "remove", fmt.Sprintf("Removal failed: %v", err))
 		return err
 	}
 
-	ep.SendEvent(utils.SeverityInfo, fmt.Sprintf("remove_%s", tc.provider), fmt.Sprintf("Removal succeeded in %s region", tc.region))
+	ep.SendScopedEvent(tc.scope(), utils.SeverityInfo, "remove", "Removal succeeded")
 	return nil
 }
 
-func (oc *DeployOrechestratorClient) RemoveAll(ep utils.EventPublisher) error {
-	removeTimeLine := utils.NewTimeline("Remove All Targets", utils.RunParallel)
-
-	for _, deployer := range oc.deployments {
-		removeTimeLine.Step(deployer.Remove)
+// RemoveAll removes the functions selected by sel from the targets recorded
+// in the state file and the configured targets, and updates their state.
+// Configured targets are included even if recorded ones exist, because a
+// failed deployment may have created functions without recording them.
+func (oc *DeployOrechestratorClient) RemoveAll(ctx context.Context, ep utils.EventPublisher, sel Selector) error {
+	targets, err := oc.allTargets()
+	if err != nil {
+		return err
+	}
+	targets, err = sel.targets(targets)
+	if err != nil {
+		return err
 	}
 
-	if err := removeTimeLine.Run(ep); err != nil {
+	removeTimeLine := oc.timeline("Remove All Targets", targets, func(tc DeployTargetClient) utils.StepContextFunc {
+		return func(ctx context.Context, ep utils.EventPublisher) error {
+			if err := tc.Remove(ctx, ep, sel.FunctionSelector); err != nil {
+				return err
+			}
+			return oc.updateState(func(state *State) {
+				key := targetKey(tc.provider, tc.region)
+				target, ok := state.Targets[key]
+				if !ok {
+					return
+				}
+				target.remove(sel.FunctionSelector)
+				if sel.All() || len(target.Functions) == 0 {
+					delete(state.Targets, key)
+				}
+			})
+		}
+	})
+
+	if err := removeTimeLine.RunContext(ctx, ep); err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// recordedTargets returns a client for every target in the state file,
+// including targets no longer configured. Configured targets keep their
+// runtime and tags.
+func (oc *DeployOrechestratorClient) recordedTargets() (map[string]DeployTargetClient, error) {
+	oc.stateMu.Lock()
+	defer oc.stateMu.Unlock()
+
+	targets := make(map[string]DeployTargetClient, len(oc.state.Targets))
+	for name, target := range oc.state.Targets {
+		if tc, ok := oc.deployments[name]; ok {
+			targets[name] = tc
+			continue
+		}
+		tc, err := NewDeployTargetClient(config.DeploymentConfig{Provider: target.Provider, Region: target.Region})
+		if err != nil {
+			return nil, err
+		}
+		targets[name] = *tc
+	}
+	return targets, nil
+}
+
+// allTargets returns the recorded targets together with the configured ones.
+func (oc *DeployOrechestratorClient) allTargets() (map[string]DeployTargetClient, error) {
+	recorded, err := oc.recordedTargets()
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make(map[string]DeployTargetClient, len(oc.deployments)+len(recorded))
+	for name, tc := range recorded {
+		targets[name] = tc
+	}
+	for name, tc := range oc.deployments {
+		targets[name] = tc
+	}
+	return targets, nil
+}
@@ -1,104 +1,162 @@
 package deployment
 
 import (
+	"ClassiFaaS/internal/globals"
 	"ClassiFaaS/internal/utils"
+	"context"
 	"encoding/json"
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
-	"strings"
+	"slices"
+	"time"
 )
 
+// scriptWaitDelay bounds how long a cancelled script's children may keep
+// its output open before they are abandoned.
+const scriptWaitDelay = 10 * time.Second
+
 type scriptDefaultDeployer struct {
 	provider  string
 	region    string
 	scriptDir string
+	// runtime and tags are passed to the script, which may ignore them
+	runtime string
+	tags    map[string]string
+	// script is the command run in scriptDir, bash ./manage-deployment.sh
+	// by default
+	script []string
+	// actions lists the supported actions if not all are supported
+	actions []string
 }
 
 func (d *scriptDefaultDeployer) GetProvider() string {
 	return d.provider
 }
 
-func (d *scriptDefaultDeployer) Deploy(ep utils.EventPublisher) error {
-	ep.SendEvent(utils.SeverityInfo, "deploy_"+d.provider, "Starting deployment...")
+func (d *scriptDefaultDeployer) scriptCommand() []string {
+	if d.script == nil {
+		return []string{"bash", "./manage-deployment.sh"}
+	}
+	return d.script
+}
+
+// command prepares manage-deployment.sh with the given action for the
+// deployer's region and the selected functions. The script is killed when
+// ctx is cancelled.
+func (d *scriptDefaultDeployer) command(ctx context.Context, action string, functions FunctionSelector) (*exec.Cmd, error) {
+	request, err := json.Marshal(scriptRequest{
+		Version:     scriptProtocolVersion,
+		Action:      action,
+		Region:      d.region,
+		Benchmarks:  functions.Benchmarks,
+		MemorySizes: functions.MemorySizes,
+		Runtime:     d.runtime,
+		Tags:        d.tags,
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	cmd := exec.Command("bash", "./manage-deployment.sh", "deploy", d.region)
+	script := d.scriptCommand()
+	cmd := exec.CommandContext(ctx, script[0], append(script[1:], action, d.region)...)
 	cmd.Dir = d.scriptDir
+	cmd.Env = append(os.Environ(), functions.env()...)
+	cmd.Env = append(cmd.Env, scriptRequestEnv+"="+string(request))
+	cmd.WaitDelay = scriptWaitDelay
+	return cmd, nil
+}
 
-	stdOut, err := cmd.StdoutPipe()
+// run runs the script with the given action, publishing its output, and
+// returns the functions it reported.
+func (d *scriptDefaultDeployer) run(ctx context.Context, ep utils.EventPublisher, action string, functions FunctionSelector) ([]DeployedFunction, error) {
+	if d.actions != nil && !slices.Contains(d.actions, action) {
+		return nil, fmt.Errorf("%s deployer does not support %s", d.provider, action)
+	}
+
+	cmd, err := d.command(ctx, action, functions)
 	if err != nil {
-		ep.SendEvent(utils.SeverityError, "deploy_"+d.provider, fmt.Sprintf("stdout pipe failed: %v", err))
-		return err
+		return nil, err
 	}
 
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("stdout pipe failed: %v", err)
+	}
 	stderr, err := cmd.StderrPipe()
 	if err != nil {
-		ep.SendEvent(utils.SeverityError, "deploy_"+d.provider, fmt.Sprintf("stderr pipe failed: %v", err))
-		return err
+		return nil, fmt.Errorf("stderr pipe failed: %v", err)
 	}
 
-	go ep.StreamToEvents(stdOut, "deploy_"+d.provider+"_stdout")
-	go ep.StreamToEvents(stderr, "deploy_"+d.provider+"_stderr")
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	go ep.StreamToScopedEvents(stderr, d.scope(), "deploy_stderr")
 
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to deploy resources (script: %s, location: %s): %w",
-			filepath.Join(d.scriptDir, "manage-deployment.sh"), d.region, err)
+	out, readErr := d.readScriptOutput(stdout, ep, "deploy_stdout")
+	if readErr != nil {
+		// stop the script instead of blocking it on a full pipe
+		cmd.Process.Kill()
+	}
+	err = cmd.Wait()
+
+	script := d.scriptCommand()
+	scriptPath := filepath.Join(d.scriptDir, script[len(script)-1])
+	switch {
+	case readErr != nil:
+		return nil, fmt.Errorf("failed to %s (script: %s, location: %s): %v", action, scriptPath, d.region, readErr)
+	case out.result != nil:
+		return nil, fmt.Errorf("failed to %s (script: %s, location: %s): %w", action, scriptPath, d.region, out.result)
+	case err != nil:
+		return nil, fmt.Errorf("failed to %s (script: %s, location: %s): %w", action, scriptPath, d.region, err)
 	}
+	return out.functions, nil
+}
 
-	ep.SendEvent(utils.SeverityInfo, "deploy_"+d.provider, "deployment completed successfully")
-	return nil
+func (d *scriptDefaultDeployer) CodeHash() (string, error) {
+	return codeHash(d.scriptDir, globals.SharedDeploymentFolder)
 }
 
-func (d *scriptDefaultDeployer) LoadDeployedFunctions(ep utils.EventPublisher) ([]DeployedFunction, error) {
-	ep.SendEvent(utils.SeverityInfo, "load_functions_"+d.provider, "Fetching function URLs and keys for all function apps...")
+func (d *scriptDefaultDeployer) scope() utils.Scope {
+	return utils.Scope{Provider: d.provider, Region: d.region}
+}
 
-	cmd := exec.Command("bash", "./manage-deployment.sh", "get-urls", d.region)
-	cmd.Dir = d.scriptDir
+func (d *scriptDefaultDeployer) Deploy(ctx context.Context, ep utils.EventPublisher, functions FunctionSelector) error {
+	ep.SendScopedEvent(d.scope(), utils.SeverityInfo, "deploy", "Starting deployment...")
 
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get %s function URLs: %v\nOutput: %s", d.provider, err, string(output))
+	if _, err := d.run(ctx, ep, "deploy", functions); err != nil {
+		return err
 	}
 
-	lines := strings.Split(string(output), "\n")
-	deployedFunctions := []DeployedFunction{}
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-
-		var f DeployedFunction
-		if err := json.Unmarshal([]byte(line), &f); err != nil {
-			return nil, fmt.Errorf("failed to parse line: %s\nerror: %v", line, err)
-		}
+	ep.SendScopedEvent(d.scope(), utils.SeverityInfo, "deploy", "deployment completed successfully")
+	return nil
+}
 
-		f.Provider = d.provider
+func (d *scriptDefaultDeployer) LoadDeployedFunctions(ctx context.Context, ep utils.EventPublisher) ([]DeployedFunction, error) {
+	ep.SendScopedEvent(d.scope(), utils.SeverityInfo, "load_functions", "Fetching function URLs and keys for all function apps...")
 
-		deployedFunctions = append(deployedFunctions, f)
+	deployedFunctions, err := d.run(ctx, ep, "get-urls", FunctionSelector{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s function URLs: %w", d.provider, err)
 	}
 
 	if len(deployedFunctions) == 0 {
-		return nil, fmt.Errorf("no functions parsed from script output")
+		return nil, fmt.Errorf("%w in script output", errNoFunctions)
 	}
 
-	ep.SendEvent(utils.SeverityInfo, "deploy_"+d.provider, fmt.Sprintf("parsed %d functions", len(deployedFunctions)))
+	ep.SendScopedEvent(d.scope(), utils.SeverityInfo, "load_functions", fmt.Sprintf("parsed %d functions", len(deployedFunctions)))
 	return deployedFunctions, nil
 }
 
-func (d *scriptDefaultDeployer) Remove(ep utils.EventPublisher) error {
-	ep.SendEvent(utils.SeverityInfo, "remove_"+d.provider, "Starting removal of deployment...")
+func (d *scriptDefaultDeployer) Remove(ctx context.Context, ep utils.EventPublisher, functions FunctionSelector) error {
+	ep.SendScopedEvent(d.scope(), utils.SeverityInfo, "remove", "Starting removal of deployment...")
 
-	cmd := exec.Command("bash", "./manage-deployment.sh", "delete", d.region)
-	cmd.Dir = d.scriptDir
-
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		ep.SendEvent(utils.SeverityError, "remove_"+d.provider, fmt.Sprintf("failed to remove deployment: %v, output: %s", err, string(output)))
+	if _, err := d.run(ctx, ep, "delete", functions); err != nil {
+		ep.SendScopedEvent(d.scope(), utils.SeverityError, "remove", fmt.Sprintf("failed to remove deployment: %v", err))
 		return err
 	}
 
-	ep.SendEvent(utils.SeverityInfo, "remove_"+d.provider, "removal completed successfully")
+	ep.SendScopedEvent(d.scope(), utils.SeverityInfo, "remove", "removal completed successfully")
 	return nil
 }
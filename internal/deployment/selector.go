@@ -0,0 +1,136 @@
+package deployment
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FunctionSelector selects benchmark functions by benchmark and memory size.
+// A nil field selects all benchmarks or memory sizes.
+type FunctionSelector struct {
+	Benchmarks  []string
+	MemorySizes []int
+}
+
+// All reports whether the selector selects every function.
+func (s FunctionSelector) All() bool {
+	return s.Benchmarks == nil && s.MemorySizes == nil
+}
+
+// Matches reports whether the selector selects f.
+func (s FunctionSelector) Matches(f DeployedFunction) bool {
+	if s.Benchmarks != nil && !slices.Contains(s.Benchmarks, f.Benchmark) {
+		return false
+	}
+	return s.MemorySizes == nil || slices.Contains(s.MemorySizes, f.Memory)
+}
+
+// defaultBenchmarks and defaultMemorySizes are deployed by the deployers
+// implemented in Go if a selector selects all, as by the deployment scripts.
+var (
+	defaultBenchmarks  = []string{"gemm", "sha256", "aesCtr", "gzip", "json"}
+	defaultMemorySizes = []int{128, 512, 2048}
+)
+
+// functions lists the selected benchmark and memory size combinations out of
+// the default ones.
+func (s FunctionSelector) functions() []DeployedFunction {
+	benchmarks := s.Benchmarks
+	if benchmarks == nil {
+		benchmarks = defaultBenchmarks
+	}
+	memorySizes := s.MemorySizes
+	if memorySizes == nil {
+		memorySizes = defaultMemorySizes
+	}
+
+	var functions []DeployedFunction
+	for _, memory := range memorySizes {
+		for _, benchmark := range benchmarks {
+			functions = append(functions, DeployedFunction{Benchmark: benchmark, Memory: memory})
+		}
+	}
+	return functions
+}
+
+// env passes the selection to a deployment script as BENCHMARKS and
+// MEMORY_SIZES, space-separated lists which are empty to select all.
+func (s FunctionSelector) env() []string {
+	memorySizes := make([]string, len(s.MemorySizes))
+	for i, memory := range s.MemorySizes {
+		memorySizes[i] = strconv.Itoa(memory)
+	}
+	return []string{
+		"BENCHMARKS=" + strings.Join(s.Benchmarks, " "),
+		"MEMORY_SIZES=" + strings.Join(memorySizes, " "),
+	}
+}
+
+// Selector restricts deploy and remove to some deployments and functions.
+// The zero value selects everything.
+type Selector struct {
+	Providers []string
+	Regions   []string
+	FunctionSelector
+}
+
+func (s Selector) matchesTarget(tc DeployTargetClient) bool {
+	if s.Providers != nil && !slices.Contains(s.Providers, tc.provider) {
+		return false
+	}
+	return s.Regions == nil || slices.Contains(s.Regions, tc.region)
+}
+
+// targets returns the targets matching the selector.
+func (s Selector) targets(targets map[string]DeployTargetClient) (map[string]DeployTargetClient, error) {
+	selected := make(map[string]DeployTargetClient)
+	for name, tc := range targets {
+		if s.matchesTarget(tc) {
+			selected[name] = tc
+		}
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no deployment matches providers %v and regions %v", s.Providers, s.Regions)
+	}
+	return selected, nil
+}
+
+// deployFunctions combines the configured benchmarks and memory sizes with
+// the selector. Benchmarks with a parameter of 0 are not deployed.
+func (oc *DeployOrechestratorClient) deployFunctions(s FunctionSelector) (FunctionSelector, error) {
+	var benchmarks []string
+	for benchmark, parameter := range oc.benchmarks {
+		if parameter > 0 && (s.Benchmarks == nil || slices.Contains(s.Benchmarks, benchmark)) {
+			benchmarks = append(benchmarks, benchmark)
+		}
+	}
+	for _, benchmark := range s.Benchmarks {
+		if oc.benchmarks[benchmark] <= 0 {
+			return FunctionSelector{}, fmt.Errorf("benchmark %q is not configured", benchmark)
+		}
+	}
+	if len(benchmarks) == 0 {
+		return FunctionSelector{}, fmt.Errorf("no benchmarks configured")
+	}
+	sort.Strings(benchmarks)
+
+	memorySizes := s.MemorySizes
+	if oc.memorySizes != nil {
+		memorySizes = nil
+		for _, memory := range oc.memorySizes {
+			if s.MemorySizes == nil || slices.Contains(s.MemorySizes, memory) {
+				memorySizes = append(memorySizes, memory)
+			}
+		}
+		for _, memory := range s.MemorySizes {
+			if !slices.Contains(oc.memorySizes, memory) {
+				return FunctionSelector{}, fmt.Errorf("memory size %d is not configured", memory)
+			}
+		}
+	}
+
+	return FunctionSelector{Benchmarks: benchmarks, MemorySizes: memorySizes}, nil
+}
@@ -0,0 +1,186 @@
+package deployment
+
+import (
+	"ClassiFaaS/internal/globals"
+	"ClassiFaaS/internal/utils"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Labels identifying the functions deployed to self-hosted platforms, which
+// may host other functions too.
+const (
+	labelBenchmark = "classifaas.benchmark"
+	labelMemory    = "classifaas.memory"
+	labelRegion    = "classifaas.region"
+)
+
+// platform is the API of a self-hosted FaaS platform.
+type platform interface {
+	// deployFunction creates or updates the named function
+	deployFunction(ctx context.Context, name string, f DeployedFunction, labels map[string]string) error
+	// listFunctions lists the functions with the given region label,
+	// including their URLs and auth details once they are known
+	listFunctions(ctx context.Context, region string) ([]platformFunction, error)
+	deleteFunction(ctx context.Context, name string) error
+	codeHash() (string, error)
+}
+
+type platformFunction struct {
+	name   string
+	labels map[string]string
+	url    string
+	auth   string
+}
+
+// selfHostedDeployer deploys the functions to a self-hosted platform through
+// its API instead of a deployment script.
+type selfHostedDeployer struct {
+	provider string
+	region   string
+	tags     map[string]string
+	platform platform
+}
+
+func (d *selfHostedDeployer) GetProvider() string {
+	return d.provider
+}
+
+func (d *selfHostedDeployer) scope() utils.Scope {
+	return utils.Scope{Provider: d.provider, Region: d.region}
+}
+
+func (d *selfHostedDeployer) CodeHash() (string, error) {
+	return d.platform.codeHash()
+}
+
+// imageHash identifies the function code of a container image built from
+// deployment/container. The image reference is covered too, so that
+// deploying another image plans an update.
+func imageHash(image string) (string, error) {
+	code, err := codeHash(globals.ContainerDeploymentFolder, globals.SharedDeploymentFolder)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256([]byte(code + "\x00" + image))
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// functionName names the function of a benchmark and memory size in the
+// lowercase form Kubernetes requires.
+func (d *selfHostedDeployer) functionName(f DeployedFunction) string {
+	return strings.ToLower(fmt.Sprintf("b-%s-%s-%d", f.Benchmark, d.region, f.Memory))
+}
+
+func (d *selfHostedDeployer) labels(f DeployedFunction) map[string]string {
+	labels := make(map[string]string, len(d.tags)+3)
+	for key, value := range d.tags {
+		labels[key] = value
+	}
+	labels[labelBenchmark] = f.Benchmark
+	labels[labelMemory] = strconv.Itoa(f.Memory)
+	labels[labelRegion] = d.region
+	return labels
+}
+
+// deployedFunction reads the benchmark and memory size of a platform
+// function from its labels.
+func (d *selfHostedDeployer) deployedFunction(pf platformFunction) (DeployedFunction, bool) {
+	memory, err := strconv.Atoi(pf.labels[labelMemory])
+	if err != nil || pf.labels[labelBenchmark] == "" {
+		return DeployedFunction{}, false
+	}
+	return DeployedFunction{
+		Provider:  d.provider,
+		URL:       pf.url,
+		Auth:      pf.auth,
+		Memory:    memory,
+		Benchmark: pf.labels[labelBenchmark],
+		Region:    d.region,
+	}, true
+}
+
+func (d *selfHostedDeployer) Deploy(ctx context.Context, ep utils.EventPublisher, functions FunctionSelector) error {
+	ep.SendScopedEvent(d.scope(), utils.SeverityInfo, "deploy", "Starting deployment...")
+
+	for _, f := range functions.functions() {
+		name := d.functionName(f)
+		ep.SendScopedEvent(d.scope(), utils.SeverityInfo, "deploy", "Deploying "+name)
+		if err := d.platform.deployFunction(ctx, name, f, d.labels(f)); err != nil {
+			return fmt.Errorf("failed to deploy %s (location: %s): %w", name, d.region, err)
+		}
+	}
+
+	ep.SendScopedEvent(d.scope(), utils.SeverityInfo, "deploy", "deployment completed successfully")
+	return nil
+}
+
+func (d *selfHostedDeployer) LoadDeployedFunctions(ctx context.Context, ep utils.EventPublisher) ([]DeployedFunction, error) {
+	ep.SendScopedEvent(d.scope(), utils.SeverityInfo, "load_functions", "Fetching function URLs and keys...")
+
+	platformFunctions, err := d.platform.listFunctions(ctx, d.region)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s function URLs: %w", d.provider, err)
+	}
+
+	var deployedFunctions []DeployedFunction
+	for _, pf := range platformFunctions {
+		f, ok := d.deployedFunction(pf)
+		if !ok {
+			continue
+		}
+		if f.URL == "" {
+			ep.SendScopedEvent(d.scope(), utils.SeverityWarning, "load_functions", fmt.Sprintf("Function '%s' has no URL yet.", pf.name))
+			continue
+		}
+		deployedFunctions = append(deployedFunctions, f)
+
+		scope := d.scope()
+		scope.Function = fmt.Sprintf("%s-%d", f.Benchmark, f.Memory)
+		ep.SendScopedEvent(scope, utils.SeverityInfo, "function_discovered", f.URL)
+	}
+
+	if len(deployedFunctions) == 0 {
+		return nil, fmt.Errorf("%w in region %s", errNoFunctions, d.region)
+	}
+
+	ep.SendScopedEvent(d.scope(), utils.SeverityInfo, "load_functions", fmt.Sprintf("parsed %d functions", len(deployedFunctions)))
+	return deployedFunctions, nil
+}
+
+func (d *selfHostedDeployer) Remove(ctx context.Context, ep utils.EventPublisher, functions FunctionSelector) error {
+	ep.SendScopedEvent(d.scope(), utils.SeverityInfo, "remove", "Starting removal of deployment...")
+
+	err := d.remove(ctx, ep, functions)
+	if err != nil {
+		ep.SendScopedEvent(d.scope(), utils.SeverityError, "remove", fmt.Sprintf("failed to remove deployment: %v", err))
+		return err
+	}
+
+	ep.SendScopedEvent(d.scope(), utils.SeverityInfo, "remove", "removal completed successfully")
+	return nil
+}
+
+func (d *selfHostedDeployer) remove(ctx context.Context, ep utils.EventPublisher, functions FunctionSelector) error {
+	platformFunctions, err := d.platform.listFunctions(ctx, d.region)
+	if err != nil {
+		return fmt.Errorf("failed to delete (location: %s): %w", d.region, err)
+	}
+
+	for _, pf := range platformFunctions {
+		f, ok := d.deployedFunction(pf)
+		if !ok || !functions.Matches(f) {
+			continue
+		}
+		ep.SendScopedEvent(d.scope(), utils.SeverityInfo, "remove", "Deleting "+pf.name)
+		if err := d.platform.deleteFunction(ctx, pf.name); err != nil && !isStatus(err, http.StatusNotFound) {
+			return fmt.Errorf("failed to delete %s (location: %s): %w", pf.name, d.region, err)
+		}
+	}
+	return nil
+}
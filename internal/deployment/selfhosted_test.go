@@ -0,0 +1,119 @@
+package deployment
+
+import (
+	"ClassiFaaS/internal/globals"
+	"ClassiFaaS/internal/utils"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testPublisher returns an event publisher without sinks.
+func testPublisher(t *testing.T) utils.EventPublisher {
+	t.Helper()
+	ep, err := utils.NewEventLoggerWithOptions(utils.EventLoggerOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(ep.Close)
+	return ep
+}
+
+// setFolder points a globals folder to a temporary folder holding files.
+func setFolder(t *testing.T, folder *string, files map[string]string) {
+	t.Helper()
+	previous := *folder
+	*folder = t.TempDir()
+	t.Cleanup(func() { *folder = previous })
+	for name, content := range files {
+		writeFile(t, filepath.Join(*folder, name), content)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// deployedByName indexes the deployed functions by benchmark and memory size.
+func deployedByName(functions []DeployedFunction) map[string]DeployedFunction {
+	byName := make(map[string]DeployedFunction)
+	for _, f := range functions {
+		byName[fmt.Sprintf("%s-%d", f.Benchmark, f.Memory)] = f
+	}
+	return byName
+}
+
+// testLifecycle deploys gemm and sha256 at 128 MB, checks the listed
+// functions with check, removes gemm and finally everything.
+func testLifecycle(t *testing.T, d deployer, check func(t *testing.T, functions map[string]DeployedFunction)) {
+	t.Helper()
+	ctx := context.Background()
+	ep := testPublisher(t)
+	sel := FunctionSelector{Benchmarks: []string{"gemm", "sha256"}, MemorySizes: []int{128}}
+
+	if _, err := d.LoadDeployedFunctions(ctx, ep); !errors.Is(err, errNoFunctions) {
+		t.Fatalf("listing before deploying: got %v, want errNoFunctions", err)
+	}
+
+	if err := d.Deploy(ctx, ep, sel); err != nil {
+		t.Fatalf("deploy: %v", err)
+	}
+	// deploying again updates the existing functions
+	if err := d.Deploy(ctx, ep, sel); err != nil {
+		t.Fatalf("redeploy: %v", err)
+	}
+
+	functions, err := d.LoadDeployedFunctions(ctx, ep)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(functions) != 2 {
+		t.Fatalf("listed %d functions, want 2: %+v", len(functions), functions)
+	}
+	check(t, deployedByName(functions))
+
+	if err := d.Remove(ctx, ep, FunctionSelector{Benchmarks: []string{"gemm"}}); err != nil {
+		t.Fatalf("remove gemm: %v", err)
+	}
+	functions, err = d.LoadDeployedFunctions(ctx, ep)
+	if err != nil {
+		t.Fatalf("list after removing gemm: %v", err)
+	}
+	if len(functions) != 1 || functions[0].Benchmark != "sha256" {
+		t.Fatalf("listed %+v after removing gemm, want sha256 only", functions)
+	}
+
+	if err := d.Remove(ctx, ep, FunctionSelector{}); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if _, err := d.LoadDeployedFunctions(ctx, ep); !errors.Is(err, errNoFunctions) {
+		t.Fatalf("listing after removing: got %v, want errNoFunctions", err)
+	}
+}
+
+func TestImageHash(t *testing.T) {
+	setFolder(t, &globals.ContainerDeploymentFolder, map[string]string{"index.js": "v1"})
+	setFolder(t, &globals.SharedDeploymentFolder, map[string]string{"gemm.js": "gemm"})
+
+	hash, err := imageHash("bench:latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other, _ := imageHash("bench:2"); other == hash {
+		t.Error("hash does not change with the image")
+	}
+
+	writeFile(t, filepath.Join(globals.ContainerDeploymentFolder, "index.js"), "v2")
+	if rebuilt, _ := imageHash("bench:latest"); rebuilt == hash {
+		t.Error("hash does not change with the container code")
+	}
+}
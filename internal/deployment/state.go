@@ -0,0 +1,195 @@
+package deployment
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// State records what was deployed per target, so that functions can be
+// removed and benchmark configs generated without asking the providers.
+type State struct {
+	Targets map[string]*TargetState `json:"targets"`
+}
+
+// TargetState is the last successful deployment of a target.
+type TargetState struct {
+	Provider   string    `json:"provider"`
+	Region     string    `json:"region"`
+	DeployedAt time.Time `json:"deployedAt"`
+	// Benchmarks and MemorySizes are the deployment config at that time;
+	// nil MemorySizes means all memory sizes.
+	Benchmarks  map[string]int     `json:"benchmarks"`
+	MemorySizes []int              `json:"memorySizes"`
+	Functions   []DeployedFunction `json:"functions"`
+}
+
+// merge adds the benchmarks, memory sizes and functions of a previous
+// deployment that were not deployed again. Functions keep their previous code
+// hash until setCodeHash marks them as deployed again.
+func (t *TargetState) merge(previous *TargetState) {
+	for benchmark, parameter := range previous.Benchmarks {
+		if _, ok := t.Benchmarks[benchmark]; !ok {
+			t.Benchmarks[benchmark] = parameter
+		}
+	}
+
+	if t.MemorySizes != nil {
+		if previous.MemorySizes == nil {
+			t.MemorySizes = nil
+		} else {
+			for _, memory := range previous.MemorySizes {
+				if !slices.Contains(t.MemorySizes, memory) {
+					t.MemorySizes = append(t.MemorySizes, memory)
+				}
+			}
+			sort.Ints(t.MemorySizes)
+		}
+	}
+
+	// the functions listed after deploying include the previous ones, unless
+	// listing them failed
+	if len(t.Functions) == 0 {
+		t.Functions = append([]DeployedFunction(nil), previous.Functions...)
+	}
+	t.Functions = withCodeHashes(t.Functions, previous.Functions)
+}
+
+// setCodeHash records hash as the code of the functions matching s.
+func (t *TargetState) setCodeHash(s FunctionSelector, hash string) {
+	for i, f := range t.Functions {
+		if s.Matches(f) {
+			t.Functions[i].CodeHash = hash
+		}
+	}
+}
+
+// withCodeHashes sets the code hash of the functions without one to that of
+// the recorded function of the same benchmark and memory size.
+func withCodeHashes(funcs, recorded []DeployedFunction) []DeployedFunction {
+	for i, f := range funcs {
+		if f.CodeHash != "" {
+			continue
+		}
+		for _, r := range recorded {
+			if r.Benchmark == f.Benchmark && r.Memory == f.Memory {
+				funcs[i].CodeHash = r.CodeHash
+				break
+			}
+		}
+	}
+	return funcs
+}
+
+// remove forgets the functions matching s.
+func (t *TargetState) remove(s FunctionSelector) {
+	var kept []DeployedFunction
+	for _, f := range t.Functions {
+		if !s.Matches(f) {
+			kept = append(kept, f)
+		}
+	}
+	t.Functions = kept
+}
+
+// LoadState reads the state file at path. A missing file is an empty state.
+func LoadState(path string) (*State, error) {
+	state := &State{Targets: make(map[string]*TargetState)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse deployment state %s: %v", path, err)
+	}
+	if state.Targets == nil {
+		state.Targets = make(map[string]*TargetState)
+	}
+	return state, nil
+}
+
+// Save writes the state to path, replacing the previous file atomically.
+func (s *State) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".state-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// codeHash hashes the names and contents of all files below dirs. Installed
+// dependencies, hidden folders and the copy of the shared code some scripts
+// place next to their own code are skipped, as are missing dirs.
+func codeHash(dirs ...string) (string, error) {
+	hash := sha256.New()
+	for _, dir := range dirs {
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			continue
+		}
+		var files []string
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				name := d.Name()
+				if path != dir && (name == "node_modules" || name == "shared" || strings.HasPrefix(name, ".")) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if d.Type().IsRegular() {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to hash function code: %v", err)
+		}
+		sort.Strings(files)
+
+		for _, path := range files {
+			rel, _ := filepath.Rel(dir, path)
+			fmt.Fprintf(hash, "%s\x00%s\x00", filepath.Base(dir), filepath.ToSlash(rel))
+			file, err := os.Open(path)
+			if err != nil {
+				return "", fmt.Errorf("failed to hash function code: %v", err)
+			}
+			_, err = io.Copy(hash, file)
+			file.Close()
+			if err != nil {
+				return "", fmt.Errorf("failed to hash function code: %v", err)
+			}
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
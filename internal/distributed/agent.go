@@ -0,0 +1,299 @@
+package distributed
+
+import (
+	"ClassiFaaS/internal/utils"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// pollInterval is the delay between attempts to register or fetch an assignment.
+	pollInterval = time.Second
+	// streamFlushSize and streamFlushInterval control how results are batched before upload.
+	streamFlushSize     = 256 * 1024
+	streamFlushInterval = time.Second
+	// uploadRetries is the number of attempts to upload a batch of results.
+	uploadRetries = 3
+	// heartbeatInterval is the delay between heartbeats, see KeepAlive.
+	heartbeatInterval = 5 * time.Second
+)
+
+// StatusError is a request the coordinator answered with an error status.
+type StatusError struct {
+	StatusCode int
+	Status     string
+	Message    string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("coordinator returned %s: %s", e.Status, e.Message)
+}
+
+// statusError returns a StatusError for an unsuccessful response, or nil.
+func statusError(resp *http.Response) error {
+	if resp.StatusCode < 300 {
+		return nil
+	}
+	msg, _ := io.ReadAll(resp.Body)
+	return &StatusError{StatusCode: resp.StatusCode, Status: resp.Status, Message: strings.TrimSpace(string(msg))}
+}
+
+// Agent executes a slice of a distributed benchmark run on behalf of a coordinator.
+type Agent struct {
+	ID          string
+	coordinator string
+	client      *http.Client
+}
+
+// NewAgent creates an agent talking to the coordinator at coordinatorURL.
+func NewAgent(coordinatorURL, id string) *Agent {
+	return &Agent{
+		ID:          id,
+		coordinator: strings.TrimRight(coordinatorURL, "/"),
+		client:      &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+func (a *Agent) endpoint(path string, query url.Values) string {
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("agent", a.ID)
+	return a.coordinator + path + "?" + query.Encode()
+}
+
+func (a *Agent) postJSON(path string, body any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	resp, err := a.client.Post(a.endpoint(path, nil), "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return statusError(resp)
+}
+
+// Register announces the agent to the coordinator, retrying until the
+// coordinator is reachable or ctx is cancelled.
+func (a *Agent) Register(ctx context.Context) error {
+	for {
+		err := a.postJSON(registerPath, RegisterRequest{Agent: a.ID})
+		if err == nil {
+			return nil
+		}
+		// all agent slots are taken, which retrying does not change
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusConflict {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("failed to register with coordinator: %v", err)
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// WaitForAssignment polls the coordinator until all agents have registered
+// and returns the agent's assignment. StartAt is converted to the local
+// clock using the offset estimated from the coordinator's ServerTime.
+func (a *Agent) WaitForAssignment(ctx context.Context) (*Assignment, error) {
+	for {
+		sent := time.Now()
+		resp, err := a.client.Get(a.endpoint(assignmentPath, nil))
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode == http.StatusOK {
+			received := time.Now()
+			var assignment Assignment
+			err := json.NewDecoder(resp.Body).Decode(&assignment)
+			resp.Body.Close()
+			if err != nil {
+				return nil, fmt.Errorf("invalid assignment: %v", err)
+			}
+
+			// assume the server timestamp was taken halfway through the round trip
+			localServerTime := sent.Add(received.Sub(sent) / 2)
+			offset := assignment.ServerTime.Sub(localServerTime)
+			assignment.StartAt = assignment.StartAt.Add(-offset)
+			return &assignment, nil
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusNoContent {
+			return nil, fmt.Errorf("coordinator returned %s", resp.Status)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// KeepAlive sends heartbeats to the coordinator until ctx is cancelled, so
+// that the coordinator can tell a busy agent from a dead one.
+func (a *Agent) KeepAlive(ctx context.Context) {
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// a missed heartbeat is made up by the next one
+			a.postJSON(heartbeatPath, RegisterRequest{Agent: a.ID})
+		}
+	}
+}
+
+// ReportDone sends the agent's function summaries to the coordinator.
+func (a *Agent) ReportDone(report DoneReport) error {
+	report.Agent = a.ID
+	return a.postJSON(donePath, report)
+}
+
+// ResultStream returns a writer uploading archived JSON-lines records of the
+// i-th function to the coordinator. Records are batched and only complete
+// lines are sent; Close uploads the remainder. Batches that fail to upload
+// are kept and sent again with the next flush, which is reported to ep.
+func (a *Agent) ResultStream(function int, ep utils.EventPublisher) io.WriteCloser {
+	s := &resultStream{
+		agent:    a,
+		function: function,
+		ep:       ep,
+		stop:     make(chan struct{}),
+	}
+
+	s.wg.Add(1)
+	go s.flushPeriodically()
+	return s
+}
+
+type resultStream struct {
+	agent    *Agent
+	function int
+	ep       utils.EventPublisher
+
+	// mu guards buf, which holds the results not yet accepted by the
+	// coordinator. uploadMu serializes the uploads, which are done without
+	// holding mu so that writers are not blocked.
+	mu       sync.Mutex
+	buf      bytes.Buffer
+	uploadMu sync.Mutex
+	// offset is the number of bytes accepted by the coordinator, which
+	// ignores bytes it received before, e.g. from a failed upload
+	offset int64
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func (s *resultStream) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	s.buf.Write(p)
+	full := s.buf.Len() >= streamFlushSize
+	s.mu.Unlock()
+
+	// failed uploads are not returned, as the results are kept and the
+	// archive's buffered writer would stop writing after an error
+	if full {
+		s.retryingFlush()
+	}
+	return len(p), nil
+}
+
+func (s *resultStream) Close() error {
+	close(s.stop)
+	s.wg.Wait()
+	return s.flush(true)
+}
+
+func (s *resultStream) flushPeriodically() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(streamFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.retryingFlush()
+		}
+	}
+}
+
+// retryingFlush uploads the complete lines, leaving them to the next flush if
+// the upload fails.
+func (s *resultStream) retryingFlush() {
+	if err := s.flush(false); err != nil {
+		s.ep.SendEvent(utils.SeverityWarning, "agent_results", fmt.Sprintf("Error streaming results, retrying: %v", err))
+	}
+}
+
+// flush uploads all complete lines, or everything if all is set. The
+// uploaded bytes are only dropped from the buffer once the coordinator
+// accepted them.
+func (s *resultStream) flush(all bool) error {
+	s.uploadMu.Lock()
+	defer s.uploadMu.Unlock()
+
+	// writers only append, so the batch stays at the front of the buffer
+	s.mu.Lock()
+	n := s.buf.Len()
+	if !all {
+		n = bytes.LastIndexByte(s.buf.Bytes(), '\n') + 1
+	}
+	batch := bytes.Clone(s.buf.Bytes()[:n])
+	s.mu.Unlock()
+	if n == 0 {
+		return nil
+	}
+
+	query := url.Values{
+		"function": {strconv.Itoa(s.function)},
+		"offset":   {strconv.FormatInt(s.offset, 10)},
+	}
+	target := s.agent.endpoint(resultsPath, query)
+
+	var err error
+	for attempt := 0; attempt < uploadRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(1<<(attempt-1)) * time.Second)
+		}
+		var resp *http.Response
+		resp, err = s.agent.client.Post(target, "application/x-ndjson", bytes.NewReader(batch))
+		if err == nil {
+			err = statusError(resp)
+			resp.Body.Close()
+			if err == nil {
+				s.mu.Lock()
+				s.buf.Next(n)
+				s.mu.Unlock()
+				s.offset += int64(n)
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("failed to upload %d bytes of results: %v", len(batch), err)
+}
@@ -0,0 +1,502 @@
+package distributed
+
+import (
+	"ClassiFaaS/internal/config"
+	"ClassiFaaS/internal/loadgenerator"
+	"ClassiFaaS/internal/utils"
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxRecordSize bounds the size of a single streamed result line.
+const maxRecordSize = 4 * 1024 * 1024
+
+// Coordinator distributes a benchmark workload between a fixed number of
+// agents, starts them at a common time and merges their results into a
+// single run folder.
+type Coordinator struct {
+	cfg          config.BenchmarkConfig
+	agentCount   int
+	startDelay   time.Duration
+	agentTimeout time.Duration
+	ep           utils.EventPublisher
+
+	mu          sync.Mutex
+	agents      []string
+	lastSeen    map[string]time.Time
+	assignments map[string]Assignment
+	archives    []*utils.ArchiveClient
+	streams     map[streamKey]*ingestedStream
+	reports     map[string]DoneReport
+	runFolder   string
+	startAt     time.Time
+	allDone     chan struct{}
+	// finishErr is set before allDone is closed
+	finishErr error
+}
+
+// streamKey identifies the result stream of one function of an agent.
+type streamKey struct {
+	agent    string
+	function int
+}
+
+// ingestedStream tracks how many bytes of a result stream were archived, so
+// that uploads retried by the agent are not archived twice.
+type ingestedStream struct {
+	mu     sync.Mutex
+	offset int64
+}
+
+// NewCoordinator creates a coordinator that waits for agentCount agents and
+// schedules the run startDelay after the last agent registered. The run
+// fails if a registered agent is not heard of for agentTimeout, unless it
+// is 0.
+func NewCoordinator(cfg config.BenchmarkConfig, agentCount int, startDelay, agentTimeout time.Duration, ep utils.EventPublisher) (*Coordinator, error) {
+	if _, err := SplitWorkload(cfg, agentCount); err != nil {
+		return nil, err
+	}
+
+	return &Coordinator{
+		cfg:          cfg,
+		agentCount:   agentCount,
+		startDelay:   startDelay,
+		agentTimeout: agentTimeout,
+		ep:           ep,
+		lastSeen:     make(map[string]time.Time),
+		assignments:  make(map[string]Assignment),
+		streams:      make(map[streamKey]*ingestedStream),
+		reports:      make(map[string]DoneReport),
+		allDone:      make(chan struct{}),
+	}, nil
+}
+
+// Run serves the coordinator API on addr until all agents have reported
+// completion, an agent timed out or ctx is cancelled, and returns the merged
+// run folder. It fails if an agent reported a failure.
+func (c *Coordinator) Run(ctx context.Context, addr string) (string, error) {
+	mux := http.NewServeMux()
+	mux.HandleFunc(registerPath, c.handleRegister)
+	mux.HandleFunc(assignmentPath, c.handleAssignment)
+	mux.HandleFunc(resultsPath, c.handleResults)
+	mux.HandleFunc(donePath, c.handleDone)
+	mux.HandleFunc(heartbeatPath, c.handleHeartbeat)
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return "", fmt.Errorf("failed to listen on %s: %v", addr, err)
+	}
+
+	server := &http.Server{Handler: mux}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(listener)
+	}()
+
+	c.ep.SendEvent(utils.SeverityInfo, "coordinator_start",
+		fmt.Sprintf("Coordinator listening on %s, waiting for %d agents", listener.Addr(), c.agentCount))
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	var runErr error
+wait:
+	for {
+		select {
+		case <-c.allDone:
+			runErr = c.finishErr
+			break wait
+		case <-ctx.Done():
+			runErr = fmt.Errorf("coordinator interrupted before all agents finished")
+			break wait
+		case err := <-serveErr:
+			runErr = fmt.Errorf("coordinator server failed: %v", err)
+			break wait
+		case <-ticker.C:
+			if agents := c.timedOutAgents(); len(agents) > 0 {
+				runErr = fmt.Errorf("agents %s not heard of for %s", strings.Join(agents, ", "), c.agentTimeout)
+				break wait
+			}
+		}
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	server.Shutdown(shutdownCtx)
+
+	// keep the results received before the run failed
+	c.mu.Lock()
+	archives := c.takeArchives()
+	runFolder := c.runFolder
+	c.mu.Unlock()
+	stopArchives(archives)
+
+	return runFolder, runErr
+}
+
+// timedOutAgents returns the registered agents that have not finished and
+// were not heard of for the agent timeout.
+func (c *Coordinator) timedOutAgents() []string {
+	if c.agentTimeout <= 0 {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var agents []string
+	for _, agent := range c.agents {
+		if _, done := c.reports[agent]; !done && time.Since(c.lastSeen[agent]) > c.agentTimeout {
+			agents = append(agents, agent)
+		}
+	}
+	return agents
+}
+
+// seen records that a registered agent is alive. Must be called with c.mu
+// held.
+func (c *Coordinator) seen(agent string) {
+	if _, ok := c.lastSeen[agent]; ok {
+		c.lastSeen[agent] = time.Now()
+	}
+}
+
+func (c *Coordinator) handleHeartbeat(w http.ResponseWriter, r *http.Request) {
+	var req RegisterRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid heartbeat", http.StatusBadRequest)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.lastSeen[req.Agent]; !ok {
+		http.Error(w, "unknown agent", http.StatusBadRequest)
+		return
+	}
+	c.seen(req.Agent)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c *Coordinator) handleRegister(w http.ResponseWriter, r *http.Request) {
+	var req RegisterRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Agent == "" {
+		http.Error(w, "invalid registration", http.StatusBadRequest)
+		return
+	}
+
+	// the archives of a failed preparation are stopped once c.mu is
+	// released, as stopping them takes a while
+	var failed []*utils.ArchiveClient
+	defer func() { stopArchives(failed) }()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, agent := range c.agents {
+		if agent == req.Agent {
+			c.seen(agent)
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+	}
+	if len(c.agents) >= c.agentCount {
+		http.Error(w, "all agent slots are taken", http.StatusConflict)
+		return
+	}
+
+	c.agents = append(c.agents, req.Agent)
+	c.lastSeen[req.Agent] = time.Now()
+	c.ep.SendEvent(utils.SeverityInfo, "agent_registered",
+		fmt.Sprintf("Agent %s registered (%d/%d)", req.Agent, len(c.agents), c.agentCount))
+
+	if len(c.agents) == c.agentCount {
+		if err := c.prepareRun(); err != nil {
+			// the agent may register again once the cause is fixed
+			c.agents = c.agents[:len(c.agents)-1]
+			delete(c.lastSeen, req.Agent)
+			failed = c.takeArchives()
+			c.ep.SendEvent(utils.SeverityError, "coordinator_prepare", err.Error())
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// prepareRun assigns workload slices to the registered agents and creates
+// the merged archives. On failure, nothing is assigned and the archives
+// created so far are left to the caller. Must be called with c.mu held.
+func (c *Coordinator) prepareRun() error {
+	slices, err := SplitWorkload(c.cfg, c.agentCount)
+	if err != nil {
+		return err
+	}
+
+	c.startAt = time.Now().Add(c.startDelay)
+	c.runFolder = loadgenerator.RunFolder(c.cfg.WorkloadParameters.ResultFolder, c.startAt)
+
+	for _, fn := range c.cfg.Functions {
+		metadata := loadgenerator.Metadata(&c.cfg.WorkloadParameters, fn)
+		metadata["agents"] = strings.Join(c.agents, ",")
+		metaStr, err := json.Marshal(metadata)
+		if err != nil {
+			return err
+		}
+
+		archiver, err := utils.NewFileArchiveClient(loadgenerator.ArchivePath(c.runFolder, fn), string(metaStr))
+		if err != nil {
+			return fmt.Errorf("failed to create archive for function %s: %v", fn.Name, err)
+		}
+		// Run stops the archives when interrupted
+		archiver.StartWriting()
+		c.archives = append(c.archives, archiver)
+	}
+
+	for i, agent := range c.agents {
+		c.assignments[agent] = Assignment{
+			Agent:   agent,
+			StartAt: c.startAt,
+			Config:  slices[i],
+		}
+	}
+
+	c.ep.SendEvent(utils.SeverityInfo, "coordinator_ready",
+		fmt.Sprintf("All agents registered, run starts at %s", c.startAt.Format(time.RFC3339Nano)))
+	return nil
+}
+
+func (c *Coordinator) handleAssignment(w http.ResponseWriter, r *http.Request) {
+	agent := r.URL.Query().Get("agent")
+
+	c.mu.Lock()
+	c.seen(agent)
+	assignment, ok := c.assignments[agent]
+	c.mu.Unlock()
+
+	if !ok {
+		// not all agents registered yet, the agent polls again
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	assignment.ServerTime = time.Now()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(assignment)
+}
+
+// handleResults appends streamed JSON-lines records of one function to the
+// merged archive, tagging each record with the sending agent. The offset
+// parameter is the position of the upload in the agent's stream; bytes
+// archived by an earlier upload of the same part are skipped.
+func (c *Coordinator) handleResults(w http.ResponseWriter, r *http.Request) {
+	agent := r.URL.Query().Get("agent")
+	function, err := strconv.Atoi(r.URL.Query().Get("function"))
+	offset, offsetErr := strconv.ParseInt(r.URL.Query().Get("offset"), 10, 64)
+
+	c.mu.Lock()
+	c.seen(agent)
+	_, registered := c.assignments[agent]
+	finished := registered && c.archives == nil
+	known := err == nil && function >= 0 && function < len(c.archives)
+	key := streamKey{agent: agent, function: function}
+	stream, ok := c.streams[key]
+	if !ok {
+		stream = &ingestedStream{}
+		c.streams[key] = stream
+	}
+	c.mu.Unlock()
+
+	if finished {
+		http.Error(w, "the run is finished", http.StatusGone)
+		return
+	}
+	if !registered || !known {
+		http.Error(w, "unknown agent or function", http.StatusBadRequest)
+		return
+	}
+	if offsetErr != nil || offset < 0 {
+		http.Error(w, "invalid offset", http.StatusBadRequest)
+		return
+	}
+
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+
+	if offset > stream.offset {
+		http.Error(w, fmt.Sprintf("results before offset %d are missing", stream.offset), http.StatusConflict)
+		return
+	}
+	if _, err := io.CopyN(io.Discard, r.Body, stream.offset-offset); err == io.EOF {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// consumed counts the bytes of the current line including its newline
+	var consumed int64
+	scanner := bufio.NewScanner(r.Body)
+	scanner.Buffer(make([]byte, 64*1024), maxRecordSize)
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		advance, token, err := bufio.ScanLines(data, atEOF)
+		consumed += int64(advance)
+		return advance, token, err
+	})
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			stream.offset += consumed
+			consumed = 0
+			continue
+		}
+
+		var record utils.BenchmarkResponse
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			http.Error(w, fmt.Sprintf("invalid record: %v", err), http.StatusBadRequest)
+			return
+		}
+		record.Agent = agent
+
+		line, err := record.ToString()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !c.archive(function, line) {
+			http.Error(w, "the run is finished", http.StatusGone)
+			return
+		}
+		stream.offset += consumed
+		consumed = 0
+	}
+	if err := scanner.Err(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c *Coordinator) handleDone(w http.ResponseWriter, r *http.Request) {
+	var report DoneReport
+	if err := json.NewDecoder(r.Body).Decode(&report); err != nil {
+		http.Error(w, "invalid report", http.StatusBadRequest)
+		return
+	}
+
+	c.mu.Lock()
+	c.seen(report.Agent)
+	if _, ok := c.assignments[report.Agent]; !ok {
+		c.mu.Unlock()
+		http.Error(w, "unknown agent", http.StatusBadRequest)
+		return
+	}
+	if _, ok := c.reports[report.Agent]; ok {
+		c.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	c.reports[report.Agent] = report
+	if report.Error != "" {
+		c.ep.SendEvent(utils.SeverityError, "agent_finished", fmt.Sprintf("Agent %s failed: %s", report.Agent, report.Error))
+	} else {
+		c.ep.SendEvent(utils.SeverityInfo, "agent_finished", fmt.Sprintf("Agent %s finished", report.Agent))
+	}
+
+	finished := len(c.reports) == c.agentCount
+	var archives []*utils.ArchiveClient
+	if finished {
+		archives = c.takeArchives()
+	}
+	c.mu.Unlock()
+
+	if finished {
+		stopArchives(archives)
+		c.finishErr = c.finishRun()
+		if c.finishErr != nil {
+			c.ep.SendEvent(utils.SeverityError, "coordinator_finish", c.finishErr.Error())
+		}
+		close(c.allDone)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// finishRun writes the run summary containing the per-agent function
+// summaries once all agents reported completion.
+func (c *Coordinator) finishRun() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	summary := loadgenerator.RunSummary{Start: c.startAt, End: time.Now()}
+	var failed []string
+	for _, agent := range c.agents {
+		report := c.reports[agent]
+		for _, fn := range report.Functions {
+			fn.Agent = agent
+			summary.Functions = append(summary.Functions, fn)
+		}
+		if report.Error != "" {
+			failed = append(failed, agent)
+		}
+	}
+	sort.SliceStable(summary.Functions, func(i, j int) bool {
+		return summary.Functions[i].Function < summary.Functions[j].Function
+	})
+
+	if err := summary.WriteToFile(c.runFolder); err != nil {
+		return err
+	}
+	if len(failed) > 0 {
+		return errors.New("agents failed: " + strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// archive writes a result line to the merged archive of a function. It
+// returns false if the archives were closed in the meantime.
+func (c *Coordinator) archive(function int, line string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if function >= len(c.archives) {
+		return false
+	}
+	c.archives[function].Write(line)
+	return true
+}
+
+// takeArchives detaches the merged archives, so that no more results are
+// written to them, and returns them to be stopped. Must be called with c.mu
+// held.
+func (c *Coordinator) takeArchives() []*utils.ArchiveClient {
+	archives := c.archives
+	c.archives = nil
+	return archives
+}
+
+// stopArchives flushes and closes archives. It must not be called with c.mu
+// held, as stopping an archive takes a few seconds.
+func stopArchives(archives []*utils.ArchiveClient) {
+	var wg sync.WaitGroup
+	for _, archiver := range archives {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			archiver.Stop()
+		}()
+	}
+	wg.Wait()
+}
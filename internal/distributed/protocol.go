@@ -0,0 +1,38 @@
+package distributed
+
+import (
+	"ClassiFaaS/internal/config"
+	"ClassiFaaS/internal/loadgenerator"
+	"time"
+)
+
+// HTTP endpoints served by the coordinator.
+const (
+	registerPath   = "/v1/register"
+	assignmentPath = "/v1/assignment"
+	resultsPath    = "/v1/results"
+	donePath       = "/v1/done"
+	heartbeatPath  = "/v1/heartbeat"
+)
+
+// RegisterRequest is sent by an agent to join the next run.
+type RegisterRequest struct {
+	Agent string `json:"agent"`
+}
+
+// Assignment is the slice of the benchmark workload executed by one agent.
+// All agents start at StartAt (coordinator clock); ServerTime lets agents
+// correct for clock offset.
+type Assignment struct {
+	Agent      string                 `json:"agent"`
+	StartAt    time.Time              `json:"startAt"`
+	ServerTime time.Time              `json:"serverTime"`
+	Config     config.BenchmarkConfig `json:"config"`
+}
+
+// DoneReport is sent by an agent after its slice of the workload has finished.
+type DoneReport struct {
+	Agent     string                          `json:"agent"`
+	Functions []loadgenerator.FunctionSummary `json:"functions"`
+	Error     string                          `json:"error,omitempty"`
+}
@@ -0,0 +1,68 @@
+package distributed
+
+import (
+	"ClassiFaaS/internal/config"
+	"fmt"
+)
+
+// SplitWorkload divides the workload of cfg between n agents. Every agent
+// benchmarks all functions with an even share of the total requests and
+// parallelism; remainders are assigned to the first agents. Load patterns
+// are split by burst size or request rate, so burst and cycle indexes line
+// up across agents.
+func SplitWorkload(cfg config.BenchmarkConfig, n int) ([]config.BenchmarkConfig, error) {
+	wp := cfg.WorkloadParameters
+	if n <= 0 {
+		return nil, fmt.Errorf("at least one agent is required")
+	}
+	if wp.StopCriterion != nil {
+		return nil, fmt.Errorf("adaptive sampling (workload.stopCriterion) is not supported in distributed runs")
+	}
+	if wp.Trace != nil {
+		return nil, fmt.Errorf("trace replay (workload.trace) is not supported in distributed runs")
+	}
+	if wp.Pattern != nil && wp.Pattern.Burst != nil && wp.Pattern.Burst.Size < n {
+		return nil, fmt.Errorf("bursts of %d requests cannot be split between %d agents", wp.Pattern.Burst.Size, n)
+	}
+	if wp.TotalRequests < n || wp.ParallelRequests < n {
+		return nil, fmt.Errorf("workload of %d requests with %d parallel requests cannot be split between %d agents",
+			wp.TotalRequests, wp.ParallelRequests, n)
+	}
+
+	slices := make([]config.BenchmarkConfig, n)
+	for i := range slices {
+		slice := cfg
+		slice.WorkloadParameters.TotalRequests = share(wp.TotalRequests, n, i)
+		slice.WorkloadParameters.ParallelRequests = share(wp.ParallelRequests, n, i)
+		if wp.Pattern != nil {
+			slice.WorkloadParameters.Pattern = splitPattern(*wp.Pattern, n, i)
+		}
+		slices[i] = slice
+	}
+	return slices, nil
+}
+
+// share returns the i-th of n near-equal parts of total.
+func share(total, n, i int) int {
+	part := total / n
+	if i < total%n {
+		part++
+	}
+	return part
+}
+
+// splitPattern returns the i-th of n shares of a load pattern.
+func splitPattern(pattern config.PatternConfig, n, i int) *config.PatternConfig {
+	if pattern.Burst != nil {
+		burst := *pattern.Burst
+		burst.Size = share(burst.Size, n, i)
+		pattern.Burst = &burst
+	}
+	if pattern.Periodic != nil {
+		periodic := *pattern.Periodic
+		periodic.MinRate /= float64(n)
+		periodic.MaxRate /= float64(n)
+		pattern.Periodic = &periodic
+	}
+	return &pattern
+}
@@ -0,0 +1,208 @@
+package emulator
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	mathrand "math/rand/v2"
+	"time"
+)
+
+// Benchmarks lists the benchmarks the emulator can run, with the parameter
+// used when a request does not pass one, as in the real handlers.
+var Benchmarks = map[string]int{
+	"gemm":   100,
+	"sha256": 2,
+	"aesCtr": 2,
+	"gzip":   2,
+	"json":   500,
+}
+
+// runBenchmark runs the named benchmark with the given parameter and returns
+// the benchmark object of the response, with the same fields as the
+// benchmarks in deployment/shared/benchmarks.
+func runBenchmark(name string, parameter int) (map[string]any, error) {
+	switch name {
+	case "gemm":
+		return runGemm(parameter), nil
+	case "sha256":
+		return runSha256(parameter), nil
+	case "aesCtr":
+		return runAesCtr(parameter)
+	case "gzip":
+		return runGzip(parameter)
+	case "json":
+		return runJSON(parameter)
+	default:
+		return nil, fmt.Errorf("unknown benchmark %q", name)
+	}
+}
+
+func elapsedMs(start time.Time) float64 {
+	return float64(time.Since(start).Nanoseconds()) / 1e6
+}
+
+func randomBuffer(sizeMB int) []byte {
+	buffer := make([]byte, sizeMB*1024*1024)
+	rand.Read(buffer)
+	return buffer
+}
+
+func createMatrix(size int, seed float64) [][]float64 {
+	matrix := make([][]float64, size)
+	for i := range matrix {
+		matrix[i] = make([]float64, size)
+		for j := range matrix[i] {
+			matrix[i][j] = float64((i+1)*(j+1)) + seed
+		}
+	}
+	return matrix
+}
+
+func runGemm(matrixSize int) map[string]any {
+	if matrixSize <= 0 {
+		return map[string]any{"type": "gemm", "matrixSize": 0, "multiplicationTimeMs": 0}
+	}
+
+	a := createMatrix(matrixSize, 42)
+	b := createMatrix(matrixSize, 99)
+	result := make([][]float64, matrixSize)
+
+	start := time.Now()
+	for i := range a {
+		result[i] = make([]float64, matrixSize)
+		for j := range b[0] {
+			var sum float64
+			for k := range a[0] {
+				sum += a[i][k] * b[k][j]
+			}
+			result[i][j] = sum
+		}
+	}
+
+	return map[string]any{
+		"type":                 "gemm",
+		"matrixSize":           matrixSize,
+		"multiplicationTimeMs": elapsedMs(start),
+	}
+}
+
+func runSha256(iterations int) map[string]any {
+	buffer := randomBuffer(8)
+
+	start := time.Now()
+	for i := 0; i < iterations; i++ {
+		sha256.Sum256(buffer)
+	}
+
+	return map[string]any{
+		"type":       "sha256",
+		"hashSizeMB": 8,
+		"hashTimeMs": elapsedMs(start),
+		"iterations": iterations,
+	}
+}
+
+func runAesCtr(iterations int) (map[string]any, error) {
+	const keySize = 128
+	buffer := randomBuffer(8)
+	key := make([]byte, keySize/8)
+	rand.Read(key)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	iv := make([]byte, aes.BlockSize)
+	out := make([]byte, len(buffer))
+
+	start := time.Now()
+	for i := 0; i < iterations; i++ {
+		cipher.NewCTR(block, iv).XORKeyStream(out, buffer)
+	}
+
+	return map[string]any{
+		"type":          "aesCtr",
+		"encryptSizeMB": 8,
+		"encryptTimeMs": elapsedMs(start),
+		"keySize":       keySize,
+		"iterations":    iterations,
+	}, nil
+}
+
+func runGzip(iterations int) (map[string]any, error) {
+	buffer := randomBuffer(4)
+
+	start := time.Now()
+	for i := 0; i < iterations; i++ {
+		var out bytes.Buffer
+		writer := gzip.NewWriter(&out)
+		if _, err := writer.Write(buffer); err != nil {
+			return nil, err
+		}
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	return map[string]any{
+		"type":           "gzip",
+		"compressSizeMB": 4,
+		"compressTimeMS": elapsedMs(start),
+		"iterations":     iterations,
+	}, nil
+}
+
+// complexObject builds the nested object serialized by the json benchmark.
+func complexObject(depth, breadth int) any {
+	if depth == 0 {
+		return fmt.Sprintf("Leaf string data %v", mathrand.Float64())
+	}
+	obj := make(map[string]any, breadth+3)
+	for i := 0; i < breadth; i++ {
+		obj[fmt.Sprintf("key_%d", i)] = complexObject(depth-1, breadth)
+	}
+	obj["id"] = mathrand.Float64()
+	obj["isActive"] = true
+	obj["tags"] = []any{1, 2, 3, "tag"}
+	return obj
+}
+
+func runJSON(iterations int) (map[string]any, error) {
+	data := complexObject(5, 4)
+
+	start := time.Now()
+	totalLength := 0
+	for i := 0; i < iterations; i++ {
+		str, err := json.Marshal(data)
+		if err != nil {
+			return nil, err
+		}
+		totalLength += len(str)
+
+		var obj map[string]any
+		if err := json.Unmarshal(str, &obj); err != nil {
+			return nil, err
+		}
+		if _, ok := obj["id"]; !ok {
+			return nil, fmt.Errorf("parsing failed")
+		}
+	}
+	jsonTimeMs := elapsedMs(start)
+
+	var throughput float64
+	if jsonTimeMs > 0 {
+		throughput = float64(totalLength) / (1024 * 1024) / (jsonTimeMs / 1000)
+	}
+
+	return map[string]any{
+		"type":           "json",
+		"throughputMBps": throughput,
+		"jsonTimeMs":     jsonTimeMs,
+		"iterations":     iterations,
+	}, nil
+}
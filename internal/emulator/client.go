@@ -0,0 +1,89 @@
+package emulator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Client manages the functions of an emulator.
+type Client struct {
+	endpoint string
+	client   *http.Client
+}
+
+// NewClient creates a client for the emulator at endpoint, e.g.
+// http://127.0.0.1:8090.
+func NewClient(endpoint string) *Client {
+	return &Client{
+		endpoint: strings.TrimRight(endpoint, "/"),
+		client:   &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// URL returns the URL invoking the named function.
+func (c *Client) URL(name string) string {
+	return c.endpoint + "/" + url.PathEscape(name)
+}
+
+// Functions lists the deployed functions.
+func (c *Client) Functions(ctx context.Context) ([]Function, error) {
+	var functions []Function
+	err := c.do(ctx, http.MethodGet, c.endpoint+functionsPath, nil, &functions)
+	return functions, err
+}
+
+// Deploy adds the functions, replacing those of the same name.
+func (c *Client) Deploy(ctx context.Context, functions []Function) error {
+	return c.do(ctx, http.MethodPut, c.endpoint+functionsPath, functions, nil)
+}
+
+// Remove removes the named functions.
+func (c *Client) Remove(ctx context.Context, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	return c.do(ctx, http.MethodDelete, c.endpoint+functionsPath+"?"+url.Values{"name": names}.Encode(), nil, nil)
+}
+
+func (c *Client) do(ctx context.Context, method, target string, body, result any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("emulator at %s is not reachable, start it with go run ./cmd/emulator: %v", c.endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("emulator returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if result != nil {
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return fmt.Errorf("failed to decode emulator response: %v", err)
+		}
+	}
+	return nil
+}
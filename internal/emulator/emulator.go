@@ -0,0 +1,280 @@
+// Package emulator runs the benchmark functions as HTTP handlers in a local
+// process that mimics a FaaS platform: functions scale out to instances
+// which start cold, serve one request at a time and are recycled. The
+// responses have the same shape as those of the handlers deployed to the
+// cloud providers, so the local provider can stand in for them offline.
+package emulator
+
+import (
+	"ClassiFaaS/internal/utils"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Provider is the provider name reported by the emulated functions.
+const Provider = "local"
+
+// functionsPath is the admin endpoint listing, deploying and removing
+// functions. Functions are invoked on /<name>.
+const functionsPath = "/_emulator/functions"
+
+// Options configure how the emulator runs a function.
+type Options struct {
+	// ColdStart delays the first request of every new instance.
+	ColdStart time.Duration `json:"coldStart,omitempty"`
+	// IdleTimeout stops instances idle for longer. Zero keeps them.
+	IdleTimeout time.Duration `json:"idleTimeout,omitempty"`
+	// Concurrency limits the number of instances; requests beyond it are
+	// rejected with 429 Too Many Requests. Zero means no limit.
+	Concurrency int `json:"concurrency,omitempty"`
+	// MaxInvocations stops an instance after that many invocations, as the
+	// real handlers do. Zero keeps it.
+	MaxInvocations int `json:"maxInvocations,omitempty"`
+	// CPUModels are the CPU models reported by the instances, one picked at
+	// random per instance.
+	CPUModels []string `json:"cpuModels,omitempty"`
+	// FailureRate is the probability of an invocation crashing its
+	// instance with 500 Internal Server Error.
+	FailureRate float64 `json:"failureRate,omitempty"`
+}
+
+// Function is a benchmark function deployed to the emulator.
+type Function struct {
+	Name      string  `json:"name"`
+	Benchmark string  `json:"benchmark"`
+	Memory    int     `json:"memory"`
+	Region    string  `json:"region"`
+	Options   Options `json:"options"`
+}
+
+// FunctionName returns the name of the function of a benchmark, region and
+// memory size, following the deployment scripts.
+func FunctionName(benchmark, region string, memory int) string {
+	return fmt.Sprintf("b-%s-%s-%d", benchmark, region, memory)
+}
+
+// response is the body returned by a function, a subset of the attributes
+// collected by the SAAF inspector of the real handlers.
+type response struct {
+	Version         float64        `json:"version"`
+	Lang            string         `json:"lang"`
+	StartTime       int64          `json:"startTime"`
+	UUID            string         `json:"uuid"`
+	NewContainer    int            `json:"newcontainer"`
+	VMUptime        int64          `json:"vmuptime"`
+	CPUType         string         `json:"cpuType"`
+	CPUVendor       string         `json:"cpuVendor"`
+	Platform        string         `json:"platform"`
+	FunctionName    string         `json:"functionName"`
+	FunctionMemory  int            `json:"functionMemory"`
+	FunctionRegion  string         `json:"functionRegion"`
+	Provider        string         `json:"provider"`
+	InstanceID      string         `json:"instanceId"`
+	InvocationCount int            `json:"invocationCount"`
+	Benchmark       map[string]any `json:"benchmark"`
+	Runtime         int64          `json:"runtime"`
+	EndTime         int64          `json:"endTime"`
+}
+
+// Server hosts the deployed functions.
+type Server struct {
+	ep      utils.EventPublisher
+	started time.Time
+
+	mu        sync.RWMutex
+	functions map[string]*function
+}
+
+// NewServer creates an emulator without functions.
+func NewServer(ep utils.EventPublisher) *Server {
+	return &Server{
+		ep:        ep,
+		started:   time.Now(),
+		functions: make(map[string]*function),
+	}
+}
+
+// Handler serves the admin endpoint and the function invocations.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(functionsPath, s.handleFunctions)
+	mux.HandleFunc("/", s.handleInvoke)
+	return mux
+}
+
+// Run serves the emulator on addr until ctx is cancelled.
+func (s *Server) Run(ctx context.Context, addr string) error {
+	server := &http.Server{Addr: addr, Handler: s.Handler()}
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.ListenAndServe()
+	}()
+	s.ep.SendEvent(utils.SeverityInfo, "emulator", fmt.Sprintf("Emulating FaaS platform on %s", addr))
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return server.Shutdown(shutdownCtx)
+	}
+}
+
+func (s *Server) handleFunctions(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.list())
+	case http.MethodPut:
+		var functions []Function
+		if err := json.NewDecoder(r.Body).Decode(&functions); err != nil {
+			http.Error(w, fmt.Sprintf("invalid functions: %v", err), http.StatusBadRequest)
+			return
+		}
+		if err := s.deploy(functions); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodDelete:
+		s.remove(r.URL.Query()["name"])
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) list() []Function {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	functions := make([]Function, 0, len(s.functions))
+	for _, f := range s.functions {
+		functions = append(functions, f.Function)
+	}
+	slices.SortFunc(functions, func(a, b Function) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return functions
+}
+
+// deploy adds the functions, replacing those of the same name and their
+// instances.
+func (s *Server) deploy(functions []Function) error {
+	for _, f := range functions {
+		if _, ok := Benchmarks[f.Benchmark]; !ok {
+			return fmt.Errorf("function %s: unknown benchmark %q", f.Name, f.Benchmark)
+		}
+		if f.Name == "" || strings.ContainsAny(f.Name, "/?#") || strings.HasPrefix(f.Name, "_") {
+			return fmt.Errorf("invalid function name %q", f.Name)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, f := range functions {
+		s.functions[f.Name] = newFunction(f)
+		s.ep.SendScopedEvent(utils.Scope{Provider: Provider, Region: f.Region, Function: f.Name}, utils.SeverityInfo, "emulator", "function deployed")
+	}
+	return nil
+}
+
+func (s *Server) remove(names []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, name := range names {
+		f, ok := s.functions[name]
+		if !ok {
+			continue
+		}
+		delete(s.functions, name)
+		s.ep.SendScopedEvent(utils.Scope{Provider: Provider, Region: f.Region, Function: name}, utils.SeverityInfo, "emulator", "function removed")
+	}
+}
+
+func (s *Server) handleInvoke(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/")
+	s.mu.RLock()
+	f, ok := s.functions[name]
+	s.mu.RUnlock()
+	if !ok {
+		http.Error(w, fmt.Sprintf("function %q not found", name), http.StatusNotFound)
+		return
+	}
+
+	parameter := Benchmarks[f.Benchmark]
+	if value := r.URL.Query().Get("parameter"); value != "" {
+		var err error
+		if parameter, err = strconv.Atoi(value); err != nil {
+			http.Error(w, fmt.Sprintf("invalid parameter %q", value), http.StatusBadRequest)
+			return
+		}
+	}
+
+	inst, cold, ok := f.acquire(time.Now())
+	if !ok {
+		http.Error(w, "Rate exceeded: too many concurrent requests", http.StatusTooManyRequests)
+		return
+	}
+	crashed := true
+	defer func() {
+		f.release(inst, time.Now(), crashed)
+	}()
+
+	if cold && f.Options.ColdStart > 0 {
+		select {
+		case <-time.After(f.Options.ColdStart):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if f.Options.FailureRate > 0 && rand.Float64() < f.Options.FailureRate {
+		http.Error(w, "injected failure", http.StatusInternalServerError)
+		return
+	}
+
+	start := time.Now()
+	benchmark, err := runBenchmark(f.Benchmark, parameter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	crashed = false
+
+	newContainer := 0
+	if cold {
+		newContainer = 1
+	}
+	end := time.Now()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response{
+		Version:         0.5,
+		Lang:            "go",
+		StartTime:       start.UnixMilli(),
+		UUID:            inst.id,
+		NewContainer:    newContainer,
+		VMUptime:        s.started.Unix(),
+		CPUType:         inst.cpuModel,
+		CPUVendor:       cpuVendor(inst.cpuModel),
+		Platform:        "ClassiFaaS Emulator",
+		FunctionName:    f.Name,
+		FunctionMemory:  f.Memory,
+		FunctionRegion:  f.Region,
+		Provider:        Provider,
+		InstanceID:      inst.id,
+		InvocationCount: inst.invocations,
+		Benchmark:       benchmark,
+		Runtime:         end.Sub(start).Milliseconds(),
+		EndTime:         end.UnixMilli(),
+	})
+}
@@ -0,0 +1,112 @@
+package emulator
+
+import (
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// defaultCPUModel is reported by instances of functions without CPU models.
+const defaultCPUModel = "ClassiFaaS Emulator CPU"
+
+// instance is an emulated function instance. Like a Lambda execution
+// environment, it serves one request at a time.
+type instance struct {
+	id          string
+	cpuModel    string
+	busy        bool
+	invocations int
+	lastUsed    time.Time
+}
+
+// function is a deployed function and its instances.
+type function struct {
+	Function
+
+	mu        sync.Mutex
+	instances []*instance
+}
+
+func newFunction(spec Function) *function {
+	return &function{Function: spec}
+}
+
+// acquire returns an idle instance, or starts a new one if all are busy. It
+// returns false if the function's concurrency limit is reached.
+func (f *function) acquire(now time.Time) (inst *instance, cold bool, ok bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// recycle the instances idle for longer than the idle timeout
+	if f.Options.IdleTimeout > 0 {
+		kept := f.instances[:0]
+		for _, inst := range f.instances {
+			if inst.busy || now.Sub(inst.lastUsed) <= f.Options.IdleTimeout {
+				kept = append(kept, inst)
+			}
+		}
+		f.instances = kept
+	}
+
+	for _, inst := range f.instances {
+		if !inst.busy {
+			inst.busy = true
+			inst.invocations++
+			return inst, false, true
+		}
+	}
+
+	if f.Options.Concurrency > 0 && len(f.instances) >= f.Options.Concurrency {
+		return nil, false, false
+	}
+	inst = &instance{
+		id:          uuid.NewString(),
+		cpuModel:    f.pickCPUModel(),
+		busy:        true,
+		invocations: 1,
+	}
+	f.instances = append(f.instances, inst)
+	return inst, true, true
+}
+
+// release makes the instance available again, or stops it if it crashed or
+// served its maximum number of invocations.
+func (f *function) release(inst *instance, now time.Time, crashed bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	inst.busy = false
+	inst.lastUsed = now
+	if crashed || (f.Options.MaxInvocations > 0 && inst.invocations >= f.Options.MaxInvocations) {
+		for i, other := range f.instances {
+			if other == inst {
+				f.instances = append(f.instances[:i], f.instances[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+func (f *function) pickCPUModel() string {
+	if len(f.Options.CPUModels) == 0 {
+		return defaultCPUModel
+	}
+	return f.Options.CPUModels[rand.IntN(len(f.Options.CPUModels))]
+}
+
+// cpuVendor derives the vendor ID of /proc/cpuinfo from a CPU model.
+func cpuVendor(model string) string {
+	switch {
+	case strings.Contains(model, "Intel"):
+		return "GenuineIntel"
+	case strings.Contains(model, "AMD"):
+		return "AuthenticAMD"
+	case strings.Contains(model, "Graviton"), strings.Contains(model, "Neoverse"):
+		return "ARM"
+	default:
+		return ""
+	}
+}
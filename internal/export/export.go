@@ -0,0 +1,136 @@
+package export
+
+import (
+	"ClassiFaaS/internal/results"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Supported export formats.
+const (
+	FormatCSV     = "csv"
+	FormatParquet = "parquet"
+)
+
+// Options controls the export of benchmark runs.
+type Options struct {
+	// Format is FormatCSV or FormatParquet.
+	Format string
+	// Out is the output file, or the output directory if Partition is set.
+	Out string
+	// Partition writes one file per run below provider=<p>/region=<r>/date=<d>
+	// directories instead of a single file.
+	Partition bool
+}
+
+// Columns returns the names of the exported columns in schema order.
+func Columns() []string {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.name
+	}
+	return names
+}
+
+// Export flattens every archived invocation of the runs into one row and
+// writes the rows in the requested format. It returns the written files.
+func Export(runs []*results.Run, opts Options) ([]string, error) {
+	if opts.Format != FormatCSV && opts.Format != FormatParquet {
+		return nil, fmt.Errorf("unknown export format %q", opts.Format)
+	}
+	if opts.Out == "" {
+		return nil, fmt.Errorf("no output path given")
+	}
+
+	writers := make(map[string]rowWriter)
+	closeAll := func() error {
+		var firstErr error
+		for _, w := range writers {
+			if err := w.close(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		return firstErr
+	}
+
+	for _, run := range runs {
+		start := run.Start()
+		for _, archive := range run.Archives {
+			path := opts.Out
+			if opts.Partition {
+				path = filepath.Join(opts.Out,
+					"provider="+partitionValue(archive.Provider()),
+					"region="+partitionValue(archive.Region()),
+					"date="+start.UTC().Format("2006-01-02"),
+					filepath.Base(run.Folder)+"."+opts.Format)
+			}
+
+			w, ok := writers[path]
+			if !ok {
+				var err error
+				if w, err = create(path, opts.Format); err != nil {
+					closeAll()
+					return nil, err
+				}
+				writers[path] = w
+			}
+
+			for i := range archive.Records {
+				src := &source{run: run, archive: archive, record: &archive.Records[i]}
+				if err := w.write(row(src)); err != nil {
+					closeAll()
+					return nil, fmt.Errorf("failed to write %s: %v", path, err)
+				}
+			}
+		}
+	}
+
+	if len(writers) == 0 && !opts.Partition {
+		// Still produce a file with the schema so that consumers do not break.
+		w, err := create(opts.Out, opts.Format)
+		if err != nil {
+			return nil, err
+		}
+		writers[opts.Out] = w
+	}
+
+	if err := closeAll(); err != nil {
+		return nil, fmt.Errorf("failed to finish export: %v", err)
+	}
+
+	files := make([]string, 0, len(writers))
+	for path := range writers {
+		files = append(files, path)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func create(path, format string) (rowWriter, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory for %s: %v", path, err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %v", path, err)
+	}
+	if format == FormatParquet {
+		return newParquetWriter(f), nil
+	}
+	w, err := newCSVWriter(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	return w, nil
+}
+
+func partitionValue(v string) string {
+	if v == "" {
+		return "unknown"
+	}
+	return strings.ReplaceAll(v, string(filepath.Separator), "_")
+}
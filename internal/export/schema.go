@@ -0,0 +1,283 @@
+package export
+
+import (
+	"ClassiFaaS/internal/analysis"
+	"ClassiFaaS/internal/results"
+	"ClassiFaaS/internal/utils"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// kind is the type of an exported column.
+type kind int
+
+const (
+	kindString kind = iota
+	kindInt
+	kindFloat
+	kindTime
+)
+
+// source is the archived invocation a row is built from.
+type source struct {
+	run     *results.Run
+	archive *results.Archive
+	record  *utils.BenchmarkResponse
+}
+
+// column is a single column of the export schema. All columns are nullable.
+type column struct {
+	name  string
+	kind  kind
+	value func(src *source) (any, bool)
+	// bodyPath is set for columns read directly from the response body, so
+	// that the remaining attributes can be collected into extra_json.
+	bodyPath string
+}
+
+func stringColumn(name string, value func(src *source) string) column {
+	return column{name: name, kind: kindString, value: func(src *source) (any, bool) {
+		v := value(src)
+		return v, v != ""
+	}}
+}
+
+func bodyColumn(name, path string, k kind) column {
+	return column{name: name, kind: k, bodyPath: path, value: func(src *source) (any, bool) {
+		v, ok := src.record.Lookup(path)
+		if !ok || v == nil {
+			return nil, false
+		}
+		switch k {
+		case kindString:
+			switch s := v.(type) {
+			case string:
+				return s, true
+			case []any:
+				parts := make([]string, len(s))
+				for i, p := range s {
+					parts[i], _ = p.(string)
+				}
+				return strings.Join(parts, " "), true
+			}
+			data, _ := json.Marshal(v)
+			return string(data), true
+		case kindInt:
+			f, ok := v.(float64)
+			return int64(f), ok
+		default:
+			f, ok := v.(float64)
+			return f, ok
+		}
+	}}
+}
+
+// columns is the stable export schema: run metadata, provider request IDs,
+// client timings, workload tags, SAAF attributes and benchmark metrics.
+// New columns must only be appended.
+var columns = []column{
+	stringColumn("run", func(src *source) string { return filepath.Base(src.run.Folder) }),
+	{name: "run_start", kind: kindTime, value: func(src *source) (any, bool) {
+		t := src.run.Start()
+		return t, !t.IsZero()
+	}},
+	stringColumn("function", func(src *source) string { return src.archive.Function() }),
+	stringColumn("provider", func(src *source) string { return src.archive.Provider() }),
+	stringColumn("region", func(src *source) string { return src.archive.Region() }),
+	{name: "memory_size", kind: kindInt, value: func(src *source) (any, bool) {
+		return int64(src.archive.MemorySize()), true
+	}},
+	stringColumn("agent", func(src *source) string { return src.record.Agent }),
+
+	stringColumn("aws_request_id", func(src *source) string { return src.record.Header.AWSRequestID }),
+	stringColumn("gcp_execution_id", func(src *source) string { return src.record.Header.GCPRequestID }),
+	stringColumn("azure_invocation_id", func(src *source) string { return src.record.Header.AZUREInvocationID }),
+	stringColumn("alibaba_request_id", func(src *source) string { return src.record.Header.ALIBABARequestID }),
+
+	{name: "client_start", kind: kindTime, value: func(src *source) (any, bool) {
+		if src.record.Client == nil {
+			return nil, false
+		}
+		return src.record.Client.Start, true
+	}},
+	{name: "client_latency_ms", kind: kindFloat, value: func(src *source) (any, bool) {
+		if src.record.Client == nil {
+			return nil, false
+		}
+		return src.record.Client.LatencyMs, true
+	}},
+	{name: "client_attempts", kind: kindInt, value: func(src *source) (any, bool) {
+		if src.record.Client == nil {
+			return nil, false
+		}
+		return int64(src.record.Client.Attempts), true
+	}},
+
+	stringColumn("workload_source", func(src *source) string {
+		if src.record.Workload == nil {
+			return ""
+		}
+		return src.record.Workload.Source
+	}),
+	{name: "workload_scheduled_offset_ms", kind: kindFloat, value: func(src *source) (any, bool) {
+		if src.record.Workload == nil {
+			return nil, false
+		}
+		return src.record.Workload.ScheduledOffsetMs, true
+	}},
+	{name: "workload_lag_ms", kind: kindFloat, value: func(src *source) (any, bool) {
+		if src.record.Workload == nil {
+			return nil, false
+		}
+		return src.record.Workload.LagMs, true
+	}},
+	stringColumn("workload_trace_function", func(src *source) string {
+		if src.record.Workload == nil {
+			return ""
+		}
+		return src.record.Workload.TraceFunction
+	}),
+	{name: "workload_burst", kind: kindInt, value: func(src *source) (any, bool) {
+		if src.record.Workload == nil || src.record.Workload.Burst == nil {
+			return nil, false
+		}
+		return int64(*src.record.Workload.Burst), true
+	}},
+	{name: "workload_cycle", kind: kindInt, value: func(src *source) (any, bool) {
+		if src.record.Workload == nil || src.record.Workload.Cycle == nil {
+			return nil, false
+		}
+		return int64(*src.record.Workload.Cycle), true
+	}},
+
+	bodyColumn("saaf_version", "version", kindFloat),
+	bodyColumn("lang", "lang", kindString),
+	bodyColumn("saaf_provider", "provider", kindString),
+	bodyColumn("platform", "platform", kindString),
+	bodyColumn("start_time_ms", "startTime", kindInt),
+	bodyColumn("end_time_ms", "endTime", kindInt),
+	bodyColumn("runtime_ms", "runtime", kindFloat),
+	bodyColumn("framework_runtime_ms", "frameworkRuntime", kindFloat),
+	bodyColumn("framework_runtime_deltas_ms", "frameworkRuntimeDeltas", kindFloat),
+	bodyColumn("uuid", "uuid", kindString),
+	bodyColumn("new_container", "newcontainer", kindInt),
+	bodyColumn("instance_id", "instanceId", kindString),
+	bodyColumn("invocation_count", "invocationCount", kindInt),
+	bodyColumn("container_id", "containerID", kindString),
+	bodyColumn("vm_id", "vmID", kindString),
+	bodyColumn("vm_uptime", "vmuptime", kindInt),
+	bodyColumn("linux_version", "linuxVersion", kindString),
+	bodyColumn("function_name", "functionName", kindString),
+	bodyColumn("function_region", "functionRegion", kindString),
+	bodyColumn("function_memory", "functionMemory", kindString),
+	bodyColumn("cpu_type", "cpuType", kindString),
+	bodyColumn("cpu_vendor", "cpuVendor", kindString),
+	bodyColumn("cpu_model", "cpuModel", kindString),
+	bodyColumn("cpu_frequency_mhz", "cpuFrequencyMHz", kindFloat),
+	bodyColumn("cpu_cache_size_kb", "cpuCacheSizeKB", kindString),
+	bodyColumn("cpu_flags", "cpuFlags", kindString),
+	bodyColumn("cpu_usr", "cpuUsr", kindInt),
+	bodyColumn("cpu_nice", "cpuNice", kindInt),
+	bodyColumn("cpu_krn", "cpuKrn", kindInt),
+	bodyColumn("cpu_idle", "cpuIdle", kindInt),
+	bodyColumn("cpu_iowait", "cpuIowait", kindInt),
+	bodyColumn("cpu_irq", "cpuIrq", kindInt),
+	bodyColumn("cpu_soft_irq", "cpuSoftIrq", kindInt),
+	bodyColumn("vm_cpu_steal", "vmcpusteal", kindInt),
+	bodyColumn("context_switches", "contextSwitches", kindInt),
+	bodyColumn("cpu_usr_delta", "cpuUsrDelta", kindInt),
+	bodyColumn("cpu_nice_delta", "cpuNiceDelta", kindInt),
+	bodyColumn("cpu_krn_delta", "cpuKrnDelta", kindInt),
+	bodyColumn("cpu_idle_delta", "cpuIdleDelta", kindInt),
+	bodyColumn("cpu_iowait_delta", "cpuIowaitDelta", kindInt),
+	bodyColumn("cpu_irq_delta", "cpuIrqDelta", kindInt),
+	bodyColumn("cpu_soft_irq_delta", "cpuSoftIrqDelta", kindInt),
+	bodyColumn("vm_cpu_steal_delta", "vmcpustealDelta", kindInt),
+	bodyColumn("context_switches_delta", "contextSwitchesDelta", kindInt),
+	bodyColumn("total_memory", "totalMemory", kindInt),
+	bodyColumn("free_memory", "freeMemory", kindInt),
+	bodyColumn("page_faults", "pageFaults", kindInt),
+	bodyColumn("major_page_faults", "majorPageFaults", kindInt),
+	bodyColumn("page_faults_delta", "pageFaultsDelta", kindInt),
+	bodyColumn("major_page_faults_delta", "majorPageFaultsDelta", kindInt),
+
+	bodyColumn("benchmark_type", "benchmark.type", kindString),
+	{name: "benchmark_metric", kind: kindFloat, value: func(src *source) (any, bool) {
+		v, ok := analysis.MetricValue(src.record, analysis.MetricBenchmark)
+		return v, ok
+	}},
+	bodyColumn("benchmark_iterations", "benchmark.iterations", kindInt),
+	bodyColumn("benchmark_matrix_size", "benchmark.matrixSize", kindInt),
+	bodyColumn("benchmark_multiplication_time_ms", "benchmark.multiplicationTimeMs", kindFloat),
+	bodyColumn("benchmark_hash_size_mb", "benchmark.hashSizeMB", kindFloat),
+	bodyColumn("benchmark_hash_time_ms", "benchmark.hashTimeMs", kindFloat),
+	bodyColumn("benchmark_encrypt_size_mb", "benchmark.encryptSizeMB", kindFloat),
+	bodyColumn("benchmark_encrypt_time_ms", "benchmark.encryptTimeMs", kindFloat),
+	bodyColumn("benchmark_key_size", "benchmark.keySize", kindInt),
+	bodyColumn("benchmark_compress_size_mb", "benchmark.compressSizeMB", kindFloat),
+	bodyColumn("benchmark_compress_time_ms", "benchmark.compressTimeMS", kindFloat),
+	bodyColumn("benchmark_throughput_mbps", "benchmark.throughputMBps", kindFloat),
+	bodyColumn("benchmark_json_time_ms", "benchmark.jsonTimeMs", kindFloat),
+
+	{name: "extra_json", kind: kindString, value: extraAttributes},
+}
+
+// knownPaths holds the body paths covered by dedicated columns.
+var knownPaths = make(map[string]bool)
+
+func init() {
+	for _, c := range columns {
+		if c.bodyPath != "" {
+			knownPaths[c.bodyPath] = true
+		}
+	}
+}
+
+// extraAttributes collects body attributes without a dedicated column, so
+// that no information is lost when handlers add new attributes.
+func extraAttributes(src *source) (any, bool) {
+	body, ok := src.record.Body.(map[string]any)
+	if !ok {
+		return nil, false
+	}
+
+	extra := make(map[string]any)
+	for key, value := range body {
+		if nested, ok := value.(map[string]any); ok && key == "benchmark" {
+			for nestedKey, nestedValue := range nested {
+				if !knownPaths[key+"."+nestedKey] {
+					extra[key+"."+nestedKey] = nestedValue
+				}
+			}
+			continue
+		}
+		if !knownPaths[key] {
+			extra[key] = value
+		}
+	}
+	if len(extra) == 0 {
+		return nil, false
+	}
+
+	data, err := json.Marshal(extra)
+	if err != nil {
+		return nil, false
+	}
+	return string(data), true
+}
+
+// row extracts the values of all columns; missing values are nil.
+func row(src *source) []any {
+	values := make([]any, len(columns))
+	for i, c := range columns {
+		if v, ok := c.value(src); ok {
+			if t, isTime := v.(time.Time); isTime {
+				v = t.UTC()
+			}
+			values[i] = v
+		}
+	}
+	return values
+}
@@ -0,0 +1,142 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// rowWriter writes rows of the export schema to a single file.
+type rowWriter interface {
+	write(values []any) error
+	close() error
+}
+
+type csvWriter struct {
+	out io.WriteCloser
+	w   *csv.Writer
+}
+
+func newCSVWriter(out io.WriteCloser) (*csvWriter, error) {
+	w := csv.NewWriter(out)
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		header[i] = c.name
+	}
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+	return &csvWriter{out: out, w: w}, nil
+}
+
+func (c *csvWriter) write(values []any) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		switch v := v.(type) {
+		case nil:
+		case string:
+			record[i] = v
+		case int64:
+			record[i] = strconv.FormatInt(v, 10)
+		case float64:
+			record[i] = strconv.FormatFloat(v, 'g', -1, 64)
+		case time.Time:
+			record[i] = v.Format(time.RFC3339Nano)
+		}
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvWriter) close() error {
+	c.w.Flush()
+	if err := c.w.Error(); err != nil {
+		c.out.Close()
+		return err
+	}
+	return c.out.Close()
+}
+
+// parquetSchema is the export schema as Parquet schema. All columns are
+// optional, times are stored as UTC timestamps in microseconds.
+var parquetSchema = func() *parquet.Schema {
+	group := parquet.Group{}
+	for _, c := range columns {
+		var node parquet.Node
+		switch c.kind {
+		case kindString:
+			node = parquet.String()
+		case kindInt:
+			node = parquet.Int(64)
+		case kindFloat:
+			node = parquet.Leaf(parquet.DoubleType)
+		case kindTime:
+			node = parquet.Timestamp(parquet.Microsecond)
+		}
+		group[c.name] = parquet.Optional(node)
+	}
+	return parquet.NewSchema("invocation", group)
+}()
+
+// parquetIndex maps the position of a column in the export schema to its
+// Parquet column index, which follows the alphabetical order of the group.
+var parquetIndex = func() []int {
+	leaves := make(map[string]int)
+	for i, path := range parquetSchema.Columns() {
+		leaves[path[0]] = i
+	}
+	index := make([]int, len(columns))
+	for i, c := range columns {
+		index[i] = leaves[c.name]
+	}
+	return index
+}()
+
+type parquetWriter struct {
+	out io.WriteCloser
+	w   *parquet.Writer
+}
+
+func newParquetWriter(out io.WriteCloser) *parquetWriter {
+	return &parquetWriter{
+		out: out,
+		w:   parquet.NewWriter(out, parquetSchema, parquet.Compression(&parquet.Snappy)),
+	}
+}
+
+func (p *parquetWriter) write(values []any) error {
+	row := make(parquet.Row, len(values))
+	for i, v := range values {
+		index := parquetIndex[i]
+		var value parquet.Value
+		switch v := v.(type) {
+		case nil:
+			row[index] = parquet.NullValue().Level(0, 0, index)
+			continue
+		case string:
+			value = parquet.ByteArrayValue([]byte(v))
+		case int64:
+			value = parquet.Int64Value(v)
+		case float64:
+			value = parquet.DoubleValue(v)
+		case time.Time:
+			value = parquet.Int64Value(v.UnixMicro())
+		default:
+			return fmt.Errorf("unsupported value type %T in column %s", v, columns[i].name)
+		}
+		row[index] = value.Level(0, 1, index)
+	}
+	_, err := p.w.WriteRows([]parquet.Row{row})
+	return err
+}
+
+func (p *parquetWriter) close() error {
+	if err := p.w.Close(); err != nil {
+		p.out.Close()
+		return err
+	}
+	return p.out.Close()
+}
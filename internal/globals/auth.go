@@ -5,4 +5,9 @@ var AuthKeys = map[string]string{
 	"aws":     "x-api-key",
 	"azure":   "x-functions-key",
 	"alibaba": "Authorization",
+	// functions of the emulator, OpenFaaS and Knative need no auth
+	"local":     "",
+	"openfaas":  "",
+	"knative":   "",
+	"openwhisk": "X-Require-Whisk-Auth",
 }
@@ -15,4 +15,28 @@ var (
 
 	//  AWS Serverless Framework folder
 	AWSDeploymentScriptFolder = "deployment/aws"
+
+	// Container image code deployed to OpenFaaS and Knative
+	ContainerDeploymentFolder = "deployment/container"
+
+	// OpenWhisk action code
+	OpenWhiskDeploymentFolder = "deployment/openwhisk"
+
+	// Benchmark code shared by all providers
+	SharedDeploymentFolder = "deployment/shared"
+
+	// Deployer plugins, one folder with a manifest.json per plugin
+	DeploymentPluginFolder = "deployment/plugins"
+
+	// Prefix of deployer plugin executables on the PATH
+	DeployerPluginPrefix = "classifaas-deployer-"
+
+	// Source of the emulator run by the local provider
+	EmulatorSourceFolder = "internal/emulator"
+
+	// Emulator of the local provider, as started by cmd/emulator
+	LocalEmulatorEndpoint = "http://127.0.0.1:8090"
+
+	// Local record of the deployed functions
+	DeploymentStateFile = ".classifaas/state.json"
 )
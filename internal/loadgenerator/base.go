@@ -2,6 +2,7 @@ package loadgenerator
 
 import (
 	"ClassiFaaS/internal/config"
+	"ClassiFaaS/internal/metrics"
 	"ClassiFaaS/internal/utils"
 	"encoding/json"
 	"fmt"
@@ -9,7 +10,11 @@ import (
 	"net/http"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
 // LoadGenerator manages the coordinated execution of benchmark jobs
@@ -17,70 +22,72 @@ import (
 type LoadGenerator struct {
 	workerPoolSize int
 	queueLen       int
-	workerSpec     workerSpec
+	workerSpec     *workerSpec
 	task           *task
+
+	// schedule is set for open-loop workloads such as trace replay; the
+	// requests are dispatched by Run instead of being queued upfront.
+	schedule   []ScheduledRequest
+	dispatched atomic.Int64
 }
 
 // NewLoadGenerator constructs a new LoadGenerator instance using the provided
 // benchmark parameters and function configurations.
 //
-// It prepares a task queue that populated with one task for each function configuration,
-// repeated TotalRequests times. Each task is associated with a file archiver
-// responsible for persisting benchmark results and metadata.
+// Results and metadata are persisted by a file archiver at ArchivePath in runFolder.
 func NewLoadGenerator(
 	WorkloadParameters *config.WorkloadParameters,
 	fnCfg config.BenchmarkFunctionConfig,
+	runFolder string,
 ) (*LoadGenerator, error) {
-	taskQueueLen := WorkloadParameters.TotalRequests
-	taskQueue := createTaskQueue(taskQueueLen)
-
-	// group tasks by memory size
-
-	metadata := map[string]string{
-		"timestamp":              time.Now().Format(time.RFC3339),
-		"url":                    fnCfg.URL,
-		"function":               fnCfg.Name,
-		"parallel-requests":      strconv.Itoa(WorkloadParameters.ParallelRequests),
-		"iterationsPerBenchmark": strconv.Itoa(WorkloadParameters.TotalRequests),
-		"retries":                strconv.Itoa(WorkloadParameters.RetriesPerRequest),
-		"provider":               fnCfg.Provider,
-		"region":                 fnCfg.Region,
-		"memorySize":             strconv.Itoa(fnCfg.MemSize),
-	}
-
-	metaStr, err := json.Marshal(metadata)
+	metaStr, err := json.Marshal(Metadata(WorkloadParameters, fnCfg))
 	if err != nil {
 		return nil, err
 	}
 
-	resultFolder := fmt.Sprintf(
-		"%s/%s/%s/%s/%s.log",
-		WorkloadParameters.ResultFolder,
-		time.Now().Format("2006-01-02_15-04"),
-		fnCfg.Provider,
-		fnCfg.Region,
-		fnCfg.Name,
-	)
-
-	archiver, err := utils.NewFileArchiveClient(resultFolder, string(metaStr))
+	archiver, err := utils.NewFileArchiveClient(ArchivePath(runFolder, fnCfg), string(metaStr))
 	if err != nil {
 		log.Fatalf("Failed to create archive client for function %s: %v", fnCfg.Name, err)
 	}
 
+	return NewLoadGeneratorWithArchive(WorkloadParameters, fnCfg, archiver), nil
+}
+
+// NewLoadGeneratorWithArchive constructs a LoadGenerator persisting results
+// through the given, not yet started, archive client.
+//
+// It prepares a task queue that populated with one task for each function configuration,
+// repeated TotalRequests times (or StopCriterion.MaxSamples times when adaptive
+// sampling is configured).
+func NewLoadGeneratorWithArchive(
+	WorkloadParameters *config.WorkloadParameters,
+	fnCfg config.BenchmarkFunctionConfig,
+	archiver *utils.ArchiveClient,
+) *LoadGenerator {
+	taskQueueLen := WorkloadParameters.TotalRequests
+	if WorkloadParameters.StopCriterion != nil {
+		taskQueueLen = WorkloadParameters.StopCriterion.MaxSamples
+	}
+	taskQueue := createTaskQueue(taskQueueLen)
+
 	archiver.Start()
 	task := createTask(&fnCfg, archiver)
 
 	// Populate the task queue with all tasks across TotalRequests iterations.
-	for i := 0; i < WorkloadParameters.TotalRequests; i++ {
+	for i := 0; i < taskQueueLen; i++ {
 		taskQueue <- task
 	}
 
 	close(taskQueue)
 
-	workerSpec := workerSpec{
+	workerSpec := &workerSpec{
 		httpClient:     &http.Client{Timeout: 120 * time.Second},
 		taskQueue:      taskQueue,
 		requestRetries: WorkloadParameters.RetriesPerRequest,
+		tracer:         noop.NewTracerProvider().Tracer(""),
+	}
+	if WorkloadParameters.StopCriterion != nil {
+		workerSpec.sampler = newSampler(*WorkloadParameters.StopCriterion)
 	}
 
 	return &LoadGenerator{
@@ -88,7 +95,56 @@ func NewLoadGenerator(
 		task:           task,
 		queueLen:       len(taskQueue),
 		workerSpec:     workerSpec,
-	}, nil
+	}
+}
+
+// Metadata returns the fields of the metadata line written at the top of a function's archive.
+func Metadata(WorkloadParameters *config.WorkloadParameters, fnCfg config.BenchmarkFunctionConfig) map[string]string {
+	metadata := map[string]string{
+		"timestamp":              time.Now().Format(time.RFC3339),
+		"url":                    fnCfg.URL,
+		"function":               fnCfg.Name,
+		"parallel-requests":      strconv.Itoa(WorkloadParameters.ParallelRequests),
+		"iterationsPerBenchmark": strconv.Itoa(WorkloadParameters.TotalRequests),
+		"retries":                strconv.Itoa(WorkloadParameters.RetriesPerRequest),
+		"provider":               fnCfg.Provider,
+		"region":                 fnCfg.Region,
+		"memorySize":             strconv.Itoa(fnCfg.MemSize),
+	}
+
+	if sc := WorkloadParameters.StopCriterion; sc != nil {
+		metadata["stopCriterion"] = fmt.Sprintf("%s %s, %.0f%% confidence, ±%g relative error, %d-%d samples",
+			sc.Statistic, sc.Metric, sc.Confidence*100, sc.RelativeError, sc.MinSamples, sc.MaxSamples)
+	}
+	if trace := WorkloadParameters.Trace; trace != nil {
+		metadata["trace"] = fmt.Sprintf("%s (%s, time scale %g)", trace.Path, trace.Format, trace.TimeScale)
+	}
+	if pattern := WorkloadParameters.Pattern; pattern != nil {
+		if b := pattern.Burst; b != nil {
+			metadata["pattern"] = fmt.Sprintf("burst of %d requests within %s every %s for %s", b.Size, b.Window, b.Interval, pattern.Duration)
+		}
+		if p := pattern.Periodic; p != nil {
+			metadata["pattern"] = fmt.Sprintf("periodic %g-%g requests/s with period %s for %s", p.MinRate, p.MaxRate, p.Period, pattern.Duration)
+		}
+	}
+
+	return metadata
+}
+
+// ArchivePath returns the path of a function's archive within a run folder.
+func ArchivePath(runFolder string, fnCfg config.BenchmarkFunctionConfig) string {
+	return fmt.Sprintf(
+		"%s/%s/%s/%s.log",
+		runFolder,
+		fnCfg.Provider,
+		fnCfg.Region,
+		fnCfg.Name,
+	)
+}
+
+// scope identifies the benchmarked function in events.
+func (l *LoadGenerator) scope() utils.Scope {
+	return utils.Scope{Provider: l.task.Function.Provider, Region: l.task.Function.Region, Function: l.task.Function.Name}
 }
 
 // Run starts the load generator using the configured worker pool Size (ParallelRequests).
@@ -102,8 +158,14 @@ func (l *LoadGenerator) Run(ep utils.EventPublisher) error {
 
 	var workerWg sync.WaitGroup
 
-	ep.SendEvent("info", "load_generator_start",
-		fmt.Sprintf("(%s: %s) Starting load generator with %d workers", l.task.Function.Provider, l.task.Function.Region, l.workerPoolSize))
+	ep.SendScopedEvent(l.scope(), "info", "load_generator_start",
+		fmt.Sprintf("Starting load generator with %d workers", l.workerPoolSize))
+
+	if l.schedule != nil {
+		ep.SendScopedEvent(l.scope(), "info", "load_generator_schedule",
+			fmt.Sprintf("Replaying %d scheduled requests over %s", len(l.schedule), l.scheduleDuration()))
+		go l.dispatch(time.Now())
+	}
 
 	for i := 0; i < l.workerPoolSize; i++ {
 		workerWg.Add(1)
@@ -112,15 +174,61 @@ func (l *LoadGenerator) Run(ep utils.EventPublisher) error {
 
 	workerWg.Wait()
 
+	if l.workerSpec.sampler != nil {
+		summary := l.workerSpec.sampler.summary()
+		ep.SendScopedEvent(l.scope(), "info", "sampling_finished",
+			fmt.Sprintf("Collected %d samples, converged: %t", summary.Samples, summary.Converged))
+	}
+
 	l.task.ArchiveClient.Stop()
-	ep.SendEvent("info", "function_finished",
-		fmt.Sprintf("Finished benchmarking function %s and closed archiver", l.task.Function.Name))
+	ep.SendScopedEvent(l.scope(), "info", "function_finished", "Finished benchmarking function and closed archiver")
 	time.Sleep(2 * time.Second) // wait for any last events to be sent
 
 	return nil
 }
 
 // GetQueueState returns the initial and current length of the task queue.
+// For scheduled workloads, requests not yet dispatched count as queued.
 func (l *LoadGenerator) GetQueueState() (int, int) {
+	if l.schedule != nil {
+		return l.queueLen, l.queueLen - int(l.dispatched.Load()) + len(l.workerSpec.taskQueue)
+	}
 	return l.queueLen, len(l.workerSpec.taskQueue)
 }
+
+func (l *LoadGenerator) scheduleDuration() time.Duration {
+	if len(l.schedule) == 0 {
+		return 0
+	}
+	return l.schedule[len(l.schedule)-1].Offset
+}
+
+// SetMetrics reports the requests of the load generator to m. It must be
+// called before Run.
+func (l *LoadGenerator) SetMetrics(m *metrics.Function) {
+	l.workerSpec.metrics = m
+	m.SetQueue(l.GetQueueState)
+}
+
+// SetTracer traces the requests of the load generator with tracer. It must
+// be called before Run.
+func (l *LoadGenerator) SetTracer(tracer trace.Tracer) {
+	l.workerSpec.tracer = tracer
+}
+
+// Summary returns the outcome of the load generator run, including the
+// achieved precision when adaptive sampling is configured.
+func (l *LoadGenerator) Summary() FunctionSummary {
+	summary := FunctionSummary{
+		Function:   l.task.Function.Name,
+		Provider:   l.task.Function.Provider,
+		Region:     l.task.Function.Region,
+		MemorySize: l.task.Function.MemSize,
+		Completed:  l.workerSpec.completed.Load(),
+		Failed:     l.workerSpec.failed.Load(),
+	}
+	if l.workerSpec.sampler != nil {
+		summary.Sampling = l.workerSpec.sampler.summary()
+	}
+	return summary
+}
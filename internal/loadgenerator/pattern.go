@@ -0,0 +1,73 @@
+package loadgenerator
+
+import (
+	"ClassiFaaS/internal/config"
+	"ClassiFaaS/internal/utils"
+	"math"
+	"time"
+)
+
+// patternResolution is the time step used to integrate the request rate of periodic patterns.
+const patternResolution = 10 * time.Millisecond
+
+// GeneratePattern returns the schedule of a burst or periodic load pattern.
+// Every request is tagged with the index of its burst or cycle.
+func GeneratePattern(cfg config.PatternConfig) []ScheduledRequest {
+	if cfg.Burst != nil {
+		return generateBursts(cfg.Duration, *cfg.Burst)
+	}
+	if cfg.Periodic != nil {
+		return generatePeriodic(cfg.Duration, *cfg.Periodic)
+	}
+	return nil
+}
+
+// generateBursts spreads Size requests evenly over Window at the start of every Interval.
+func generateBursts(duration time.Duration, burst config.BurstPattern) []ScheduledRequest {
+	var schedule []ScheduledRequest
+	for index := 0; time.Duration(index)*burst.Interval < duration; index++ {
+		start := time.Duration(index) * burst.Interval
+		for k := 0; k < burst.Size; k++ {
+			offset := start + time.Duration(float64(burst.Window)*float64(k)/float64(burst.Size))
+			schedule = append(schedule, ScheduledRequest{
+				Offset: offset,
+				Info: utils.WorkloadInfo{
+					Source: "burst",
+					Burst:  intPtr(index),
+				},
+			})
+		}
+	}
+	return schedule
+}
+
+// generatePeriodic emits requests whenever the integral of the sinusoidal
+// rate reaches the next whole request.
+func generatePeriodic(duration time.Duration, periodic config.PeriodicPattern) []ScheduledRequest {
+	var schedule []ScheduledRequest
+	amplitude := periodic.MaxRate - periodic.MinRate
+	step := patternResolution.Seconds()
+
+	var expected float64
+	for t := time.Duration(0); t < duration; t += patternResolution {
+		phase := 2 * math.Pi * float64(t) / float64(periodic.Period)
+		rate := periodic.MinRate + amplitude*(1-math.Cos(phase))/2
+		expected += rate * step
+
+		for expected >= 1 {
+			expected--
+			schedule = append(schedule, ScheduledRequest{
+				Offset: t,
+				Info: utils.WorkloadInfo{
+					Source: "periodic",
+					Cycle:  intPtr(int(t / periodic.Period)),
+				},
+			})
+		}
+	}
+	return schedule
+}
+
+func intPtr(v int) *int {
+	return &v
+}
@@ -0,0 +1,135 @@
+package loadgenerator
+
+import (
+	"ClassiFaaS/internal/config"
+	"ClassiFaaS/internal/stats"
+	"ClassiFaaS/internal/utils"
+	"math"
+	"sync"
+)
+
+// checkInterval is the number of new samples between two evaluations of the
+// stop criterion, to avoid re-sorting the sample set on every response.
+const checkInterval = 10
+
+// sampler collects metric values of completed invocations and decides when
+// the configured stop criterion has been met.
+type sampler struct {
+	criterion config.StopCriterion
+
+	mu        sync.Mutex
+	samples   []float64
+	interval  stats.Interval
+	converged bool
+	done      chan struct{}
+}
+
+// SamplingSummary reports the precision achieved by adaptive sampling.
+type SamplingSummary struct {
+	Metric              string  `json:"metric"`
+	Statistic           string  `json:"statistic"`
+	Confidence          float64 `json:"confidence"`
+	TargetRelativeError float64 `json:"targetRelativeError"`
+	Samples             int     `json:"samples"`
+	// Estimate, bounds and achieved relative error are nil when too few
+	// samples were collected to compute them.
+	Estimate      *float64 `json:"estimate"`
+	Lower         *float64 `json:"lower"`
+	Upper         *float64 `json:"upper"`
+	RelativeError *float64 `json:"relativeError"`
+	Converged     bool     `json:"converged"`
+}
+
+func newSampler(criterion config.StopCriterion) *sampler {
+	return &sampler{
+		criterion: criterion,
+		done:      make(chan struct{}),
+	}
+}
+
+// extract returns the configured metric of a benchmark response.
+func (s *sampler) extract(result *utils.BenchmarkResponse) (float64, bool) {
+	if s.criterion.Metric == "latency" {
+		if result.Client == nil {
+			return 0, false
+		}
+		return result.Client.LatencyMs, true
+	}
+	return result.LookupFloat(s.criterion.Metric)
+}
+
+// add records the metric of a completed invocation. Once MinSamples have been
+// collected the stop criterion is evaluated periodically; reaching it or
+// MaxSamples closes the done channel.
+func (s *sampler) add(result *utils.BenchmarkResponse) {
+	value, ok := s.extract(result)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.samples = append(s.samples, value)
+	n := len(s.samples)
+	if s.stopped() || n < s.criterion.MinSamples {
+		return
+	}
+	if n%checkInterval != 0 && n < s.criterion.MaxSamples {
+		return
+	}
+
+	s.interval = s.estimate()
+	if s.interval.RelativeHalfWidth() <= s.criterion.RelativeError {
+		s.converged = true
+		close(s.done)
+	} else if n >= s.criterion.MaxSamples {
+		close(s.done)
+	}
+}
+
+// stopped reports whether the stop criterion has been reached.
+func (s *sampler) stopped() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *sampler) estimate() stats.Interval {
+	if s.criterion.Statistic == "median" {
+		return stats.MedianCI(s.samples, s.criterion.Confidence)
+	}
+	return stats.MeanCI(s.samples, s.criterion.Confidence)
+}
+
+// summary computes the precision achieved over all collected samples.
+func (s *sampler) summary() *SamplingSummary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	interval := s.estimate()
+	return &SamplingSummary{
+		Metric:              s.criterion.Metric,
+		Statistic:           s.criterion.Statistic,
+		Confidence:          s.criterion.Confidence,
+		TargetRelativeError: s.criterion.RelativeError,
+		Samples:             len(s.samples),
+		Estimate:            finite(interval.Estimate),
+		Lower:               finite(interval.Lower),
+		Upper:               finite(interval.Upper),
+		RelativeError:       finite(interval.RelativeHalfWidth()),
+		Converged:           s.converged,
+	}
+}
+
+// finite returns a pointer to v, or nil if v is NaN or infinite and therefore
+// not representable in JSON.
+func finite(v float64) *float64 {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return nil
+	}
+	return &v
+}
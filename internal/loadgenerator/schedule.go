@@ -0,0 +1,56 @@
+package loadgenerator
+
+import (
+	"ClassiFaaS/internal/utils"
+	"sort"
+	"time"
+)
+
+// ScheduledRequest is a request fired at a fixed offset from the start of a
+// load generator run, independent of the completion of earlier requests.
+type ScheduledRequest struct {
+	Offset time.Duration
+	// Info describes the request in the archive; the scheduled offset and
+	// the lag are filled in during execution.
+	Info utils.WorkloadInfo
+}
+
+// SetSchedule replaces the uniform task queue by an open-loop schedule: Run
+// dispatches each request at its offset, and up to ParallelRequests workers
+// execute them. Requests that cannot start on time because all workers are
+// busy are queued, and their lag is recorded in the archive.
+func (l *LoadGenerator) SetSchedule(schedule []ScheduledRequest) {
+	sorted := make([]ScheduledRequest, len(schedule))
+	copy(sorted, schedule)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Offset < sorted[j].Offset
+	})
+
+	l.schedule = sorted
+	l.queueLen = len(sorted)
+	l.workerSpec.taskQueue = createTaskQueue(len(sorted))
+}
+
+// dispatch pushes the scheduled requests into the task queue at their
+// offsets from start and closes the queue afterwards.
+func (l *LoadGenerator) dispatch(start time.Time) {
+	defer close(l.workerSpec.taskQueue)
+
+	for _, req := range l.schedule {
+		scheduledAt := start.Add(req.Offset)
+		if wait := time.Until(scheduledAt); wait > 0 {
+			time.Sleep(wait)
+		}
+
+		info := req.Info
+		info.ScheduledOffsetMs = float64(req.Offset.Microseconds()) / 1000
+
+		l.workerSpec.taskQueue <- &task{
+			Function:      l.task.Function,
+			ArchiveClient: l.task.ArchiveClient,
+			scheduledAt:   scheduledAt,
+			workload:      &info,
+		}
+		l.dispatched.Add(1)
+	}
+}
@@ -0,0 +1,48 @@
+package loadgenerator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// RunSummaryFile is the name of the summary written into each run folder.
+const RunSummaryFile = "summary.json"
+
+// RunSummary describes a completed benchmark run.
+type RunSummary struct {
+	Start     time.Time         `json:"start"`
+	End       time.Time         `json:"end"`
+	Functions []FunctionSummary `json:"functions"`
+}
+
+// FunctionSummary describes the outcome of benchmarking a single function.
+type FunctionSummary struct {
+	Function   string           `json:"function"`
+	Agent      string           `json:"agent,omitempty"`
+	Provider   string           `json:"provider"`
+	Region     string           `json:"region"`
+	MemorySize int              `json:"memorySize"`
+	Completed  int64            `json:"completed"`
+	Failed     int64            `json:"failed"`
+	Sampling   *SamplingSummary `json:"sampling,omitempty"`
+}
+
+// RunFolder returns the folder results of a run started at the given time are stored in.
+func RunFolder(resultFolder string, start time.Time) string {
+	return filepath.Join(resultFolder, start.Format("2006-01-02_15-04"))
+}
+
+// WriteToFile writes the summary as indented JSON into the run folder.
+func (s *RunSummary) WriteToFile(runFolder string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal run summary: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(runFolder, RunSummaryFile), data, 0644); err != nil {
+		return fmt.Errorf("failed to write run summary: %v", err)
+	}
+	return nil
+}
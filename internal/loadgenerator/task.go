@@ -3,9 +3,15 @@ package loadgenerator
 import (
 	"ClassiFaaS/internal/config"
 	"ClassiFaaS/internal/utils"
+	"context"
 	"fmt"
 	"net/http"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Task represents a single benchmark job to be executed against
@@ -13,10 +19,17 @@ import (
 //
 // Each Task holds a reference to its target function configuration
 // and an ArchiveClient used to persist benchmark results.
+//
+// Tasks of a scheduled workload additionally carry the time they were
+// scheduled at and a description of the request, which are archived
+// alongside the response.
 type task struct {
 	Function *config.BenchmarkFunctionConfig
 
 	ArchiveClient *utils.ArchiveClient
+
+	scheduledAt time.Time
+	workload    *utils.WorkloadInfo
 }
 
 // CreateTaskQueue initializes and returns a buffered channel that acts
@@ -40,44 +53,130 @@ func createTask(function *config.BenchmarkFunctionConfig, archiveClient *utils.A
 // The function is invoked via an HTTP GET request to the configured URL,
 // including any query parameters provided in the query map. If the request
 // or decoding fails, it will be retried up to the specified number of retries.
-func (t *task) execute(httpClient *http.Client, retries int) error {
+//
+// The invocation is traced as a span with one child span per attempt; the
+// trace context is propagated to the function in the traceparent header.
+//
+// The decoded response, annotated with the client-side timing of the
+// successful attempt, is persisted and returned along with the number of
+// attempts made.
+func (t *task) execute(httpClient *http.Client, retries int, tracer trace.Tracer) (*utils.BenchmarkResponse, int, error) {
+	ctx, span := tracer.Start(context.Background(), "invoke "+t.Function.Name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("cloud.provider", t.Function.Provider),
+			attribute.String("cloud.region", t.Function.Region),
+			attribute.String("faas.invoked_name", t.Function.Name),
+			attribute.String("faas.invoked_provider", t.Function.Provider),
+			attribute.String("faas.invoked_region", t.Function.Region),
+			attribute.Int("faas.max_memory", t.Function.MemSize*1024*1024),
+		))
+	defer span.End()
+
 	var err error
+	var firstStart time.Time
 
 	for attempt := 0; attempt <= retries; attempt++ {
-		req, err := http.NewRequest("GET", t.Function.URL, nil)
-		if err != nil {
-			return err
+		attemptCtx, attemptSpan := tracer.Start(ctx, fmt.Sprintf("attempt %d", attempt+1),
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(attribute.Int("classifaas.attempt", attempt+1)))
+
+		req, reqErr := http.NewRequestWithContext(attemptCtx, "GET", t.Function.URL, nil)
+		if reqErr != nil {
+			endSpan(attemptSpan, reqErr)
+			endSpan(span, reqErr)
+			return nil, attempt, reqErr
 		}
+		// the query is omitted as it may carry function keys
+		attemptSpan.SetAttributes(
+			attribute.String("http.request.method", req.Method),
+			attribute.String("server.address", req.URL.Host),
+			attribute.String("url.path", req.URL.Path),
+		)
 
 		if t.Function.Auth.Key != "" && t.Function.Auth.Value != "" {
 			req.Header.Set(t.Function.Auth.Key, t.Function.Auth.Value)
 		}
+		propagation.TraceContext{}.Inject(attemptCtx, propagation.HeaderCarrier(req.Header))
 
-		resp, err := httpClient.Do(req)
+		start := time.Now()
+		if attempt == 0 {
+			firstStart = start
+		}
+		resp, doErr := httpClient.Do(req)
+		latency := time.Since(start)
+
+		if resp != nil {
+			attemptSpan.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+		}
 
-		if err != nil || resp.StatusCode != http.StatusOK {
+		if doErr != nil || resp.StatusCode != http.StatusOK {
+			if doErr != nil {
+				err = doErr
+			} else {
+				err = fmt.Errorf("unexpected status code %d", resp.StatusCode)
+			}
 			if resp != nil {
 				resp.Body.Close()
 			}
+			endSpan(attemptSpan, err)
 			time.Sleep(time.Duration(1<<attempt) * time.Second)
 			continue
 		}
 
+		// the worker publishes the error, printing it would corrupt the
+		// dashboard
 		result, decErr := utils.DecodeBenchmarkResponse(resp)
 		if decErr != nil {
-			fmt.Println("Error decoding benchmark response:", decErr)
-			return decErr
+			decErr = fmt.Errorf("failed to decode benchmark response: %v", decErr)
+			endSpan(attemptSpan, decErr)
+			endSpan(span, decErr)
+			return nil, attempt + 1, decErr
+		}
+		attemptSpan.End()
+
+		span.SetAttributes(
+			attribute.Int("http.response.status_code", resp.StatusCode),
+			attribute.Int("classifaas.attempts", attempt+1),
+		)
+		if requestID := result.Header.RequestID(); requestID != "" {
+			span.SetAttributes(attribute.String("faas.invocation_id", requestID))
+		}
+		if benchmark, ok := result.LookupString("benchmark.type"); ok {
+			span.SetAttributes(attribute.String("classifaas.benchmark", benchmark))
+		}
+
+		result.Client = &utils.ClientTiming{
+			Start:     start,
+			LatencyMs: float64(latency.Microseconds()) / 1000,
+			Attempts:  attempt + 1,
+		}
+		if t.workload != nil {
+			workload := *t.workload
+			workload.LagMs = float64(firstStart.Sub(t.scheduledAt).Microseconds()) / 1000
+			result.Workload = &workload
 		}
 
 		// Persist result
-		resultStr, err := result.ToString()
-		if err != nil {
-			return err
+		resultStr, strErr := result.ToString()
+		if strErr != nil {
+			endSpan(span, strErr)
+			return nil, attempt + 1, strErr
 		}
 
 		t.ArchiveClient.Write(resultStr)
-		return nil
+		return result, attempt + 1, nil
 	}
 
-	return fmt.Errorf("task %s failed after %d retries: %v", t.Function.Name, retries, err)
+	err = fmt.Errorf("task %s failed after %d retries: %v", t.Function.Name, retries, err)
+	span.SetAttributes(attribute.Int("classifaas.attempts", retries+1))
+	endSpan(span, err)
+	return nil, retries + 1, err
+}
+
+// endSpan marks span as failed with err and ends it.
+func endSpan(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+	span.End()
 }
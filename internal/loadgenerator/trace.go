@@ -0,0 +1,211 @@
+package loadgenerator
+
+import (
+	"ClassiFaaS/internal/config"
+	"ClassiFaaS/internal/utils"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// azureMinuteColumns is the index of the first per-minute invocation count
+// in the Azure Functions trace (HashOwner, HashApp, HashFunction, Trigger, 1..1440).
+const azureMinuteColumns = 4
+
+// traceInvocation is a single invocation read from a trace file.
+type traceInvocation struct {
+	offset   time.Duration
+	function string
+}
+
+// LoadTrace reads the trace described by cfg and returns the replay schedule
+// for each configured function, keyed by BenchmarkFunctionConfig.Key.
+func LoadTrace(cfg config.TraceConfig, functions []config.BenchmarkFunctionConfig) (map[string][]ScheduledRequest, error) {
+	file, err := os.Open(cfg.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open trace: %v", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var invocations []traceInvocation
+	switch cfg.Format {
+	case "csv":
+		invocations, err = readCSVTrace(reader)
+	case "azure":
+		invocations, err = readAzureTrace(reader)
+	default:
+		err = fmt.Errorf("unsupported trace format '%s'", cfg.Format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trace %s: %v", cfg.Path, err)
+	}
+
+	mapping, err := mapTraceFunctions(cfg, invocations, functions)
+	if err != nil {
+		return nil, err
+	}
+
+	timeScale := cfg.TimeScale
+	if timeScale == 0 {
+		timeScale = 1
+	}
+
+	schedules := make(map[string][]ScheduledRequest)
+	for _, inv := range invocations {
+		if cfg.Duration > 0 && inv.offset > cfg.Duration {
+			continue
+		}
+		target, ok := mapping[inv.function]
+		if !ok {
+			continue
+		}
+		schedules[target] = append(schedules[target], ScheduledRequest{
+			Offset: time.Duration(float64(inv.offset) * timeScale),
+			Info: utils.WorkloadInfo{
+				Source:        "trace",
+				TraceFunction: inv.function,
+			},
+		})
+	}
+	return schedules, nil
+}
+
+// readCSVTrace reads "offset,function" records, where offset is given in
+// seconds since the start of the trace. A header line is skipped.
+func readCSVTrace(reader *csv.Reader) ([]traceInvocation, error) {
+	var invocations []traceInvocation
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) < 2 {
+			return nil, fmt.Errorf("line %d: expected offset and function", line)
+		}
+
+		seconds, err := strconv.ParseFloat(record[0], 64)
+		if err != nil {
+			if line == 1 {
+				continue // header
+			}
+			return nil, fmt.Errorf("line %d: invalid offset '%s'", line, record[0])
+		}
+		if seconds < 0 {
+			return nil, fmt.Errorf("line %d: offset must not be negative", line)
+		}
+
+		invocations = append(invocations, traceInvocation{
+			offset:   time.Duration(seconds * float64(time.Second)),
+			function: strings.TrimSpace(record[1]),
+		})
+	}
+	return invocations, nil
+}
+
+// readAzureTrace reads the per-minute invocation counts of the Azure Functions
+// public trace and spreads each minute's invocations evenly over that minute.
+// Functions are identified by their HashFunction column.
+func readAzureTrace(reader *csv.Reader) ([]traceInvocation, error) {
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %v", err)
+	}
+	if len(header) <= azureMinuteColumns || header[2] != "HashFunction" {
+		return nil, fmt.Errorf("unexpected header, expected HashOwner,HashApp,HashFunction,Trigger,1,...")
+	}
+
+	var invocations []traceInvocation
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) <= azureMinuteColumns {
+			return nil, fmt.Errorf("line %d: expected function and per-minute counts", line)
+		}
+
+		function := record[2]
+		for col := azureMinuteColumns; col < len(record); col++ {
+			count, err := strconv.Atoi(record[col])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid count '%s' in column %s", line, record[col], header[col])
+			}
+
+			minute := time.Duration(col-azureMinuteColumns) * time.Minute
+			for k := 0; k < count; k++ {
+				spread := time.Duration((float64(k) + 0.5) / float64(count) * float64(time.Minute))
+				invocations = append(invocations, traceInvocation{
+					offset:   minute + spread,
+					function: function,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(invocations, func(i, j int) bool {
+		return invocations[i].offset < invocations[j].offset
+	})
+	return invocations, nil
+}
+
+// mapTraceFunctions maps trace function names onto the keys of the
+// configured functions.
+func mapTraceFunctions(cfg config.TraceConfig, invocations []traceInvocation, functions []config.BenchmarkFunctionConfig) (map[string]string, error) {
+	configured := make(map[string]bool)
+	byName := make(map[string][]string)
+	var keys []string
+	for _, fn := range functions {
+		configured[fn.Key()] = true
+		byName[fn.Name] = append(byName[fn.Name], fn.Key())
+		keys = append(keys, fn.Key())
+	}
+	sort.Strings(keys)
+
+	mapping := make(map[string]string)
+	if len(cfg.Functions) > 0 {
+		for traceFn, target := range cfg.Functions {
+			if configured[target] {
+				mapping[traceFn] = target
+				continue
+			}
+			switch len(byName[target]) {
+			case 0:
+				return nil, fmt.Errorf("trace function '%s' is mapped onto unknown function '%s'", traceFn, target)
+			case 1:
+				mapping[traceFn] = byName[target][0]
+			default:
+				return nil, fmt.Errorf("trace function '%s' is mapped onto function '%s' of several regions, use name:region", traceFn, target)
+			}
+		}
+		return mapping, nil
+	}
+
+	next := 0
+	for _, inv := range invocations {
+		if _, ok := mapping[inv.function]; ok {
+			continue
+		}
+		if len(byName[inv.function]) == 1 {
+			mapping[inv.function] = byName[inv.function][0]
+			continue
+		}
+		mapping[inv.function] = keys[next%len(keys)]
+		next++
+	}
+	return mapping, nil
+}
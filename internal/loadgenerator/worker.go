@@ -1,10 +1,14 @@
 package loadgenerator
 
 import (
+	"ClassiFaaS/internal/metrics"
 	"ClassiFaaS/internal/utils"
 	"fmt"
 	"net/http"
 	"sync"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 // WorkerSpec defines the configuration for a benchmark worker.
@@ -14,6 +18,18 @@ type workerSpec struct {
 
 	requestRetries int
 	httpClient     *http.Client
+
+	// sampler is nil unless adaptive sampling is configured.
+	sampler *sampler
+
+	// metrics is nil unless live metrics are served.
+	metrics *metrics.Function
+
+	// tracer creates the invocation spans; it is a no-op unless tracing is configured.
+	tracer trace.Tracer
+
+	completed atomic.Int64
+	failed    atomic.Int64
 }
 
 // worker consumes and executes tasks from the TaskQueue.
@@ -22,20 +38,39 @@ type workerSpec struct {
 // executed via Task.Execute using an HTTP client.
 //
 // The worker signals completion through the provided WaitGroup once all
-// tasks are finished.
+// tasks are finished, or once the sampler's stop criterion has been reached.
 func (spec *workerSpec) worker(workerWg *sync.WaitGroup, ep utils.EventPublisher) {
 	defer workerWg.Done()
 
 	for task := range spec.taskQueue {
-		err := task.execute(spec.httpClient, spec.requestRetries)
+		if spec.sampler != nil && spec.sampler.stopped() {
+			return
+		}
+
+		if spec.metrics != nil {
+			spec.metrics.RequestStarted()
+		}
+
+		result, attempts, err := task.execute(spec.httpClient, spec.requestRetries, spec.tracer)
+
+		if spec.metrics != nil {
+			spec.metrics.RequestFinished(attempts, result)
+		}
 
 		if err != nil {
-			ep.SendEvent(
+			spec.failed.Add(1)
+			ep.SendScopedEvent(
+				utils.Scope{Provider: task.Function.Provider, Region: task.Function.Region, Function: task.Function.Name},
 				"error",
 				"task_execution",
-				fmt.Sprintf("Error executing task %s: %v", task.Function.Name, err),
+				fmt.Sprintf("Error executing task: %v", err),
 			)
+			continue
 		}
 
+		spec.completed.Add(1)
+		if spec.sampler != nil {
+			spec.sampler.add(result)
+		}
 	}
 }
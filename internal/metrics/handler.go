@@ -0,0 +1,195 @@
+package metrics
+
+import (
+	"ClassiFaaS/internal/stats"
+	"ClassiFaaS/internal/utils"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Handler serves the metrics in Prometheus text format on /metrics and a
+// JSON status on /status.
+func (r *Registry) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.writePrometheus(w)
+	})
+	mux.HandleFunc("/status", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(r.Status())
+	})
+	return mux
+}
+
+// Serve starts an HTTP server for Handler on addr in the background.
+func (r *Registry) Serve(addr string, ep utils.EventPublisher) {
+	server := &http.Server{Addr: addr, Handler: r.Handler()}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			ep.SendEvent(utils.SeverityError, "metrics_server", fmt.Sprintf("Metrics server stopped: %v", err))
+		}
+	}()
+	ep.SendEvent(utils.SeverityInfo, "metrics_server", fmt.Sprintf("Serving metrics on %s (/metrics, /status)", addr))
+}
+
+// Status is the JSON status of the current run.
+type Status struct {
+	Runs      int              `json:"runs"`
+	RunFolder string           `json:"runFolder,omitempty"`
+	RunStart  *time.Time       `json:"runStart,omitempty"`
+	Functions []FunctionStatus `json:"functions"`
+}
+
+// FunctionStatus is the progress of a single function.
+type FunctionStatus struct {
+	Function   string `json:"function"`
+	Provider   string `json:"provider"`
+	Region     string `json:"region"`
+	MemorySize int    `json:"memorySize"`
+	// Total and Remaining describe the queue of the function's current load generator.
+	Total         int      `json:"total"`
+	Remaining     int      `json:"remaining"`
+	Started       int64    `json:"started"`
+	Completed     int64    `json:"completed"`
+	Failed        int64    `json:"failed"`
+	Retries       int64    `json:"retries"`
+	InFlight      int64    `json:"inFlight"`
+	MeanLatencyMs *float64 `json:"meanLatencyMs"`
+	// P50LatencyMs and P99LatencyMs cover the most recent requests only.
+	P50LatencyMs *float64 `json:"p50LatencyMs"`
+	P99LatencyMs *float64 `json:"p99LatencyMs"`
+	// CPUs counts the CPU models reported by successful requests.
+	CPUs map[string]int64 `json:"cpus"`
+}
+
+// Status returns the current status of all functions.
+func (r *Registry) Status() Status {
+	r.mu.Lock()
+	status := Status{Runs: r.runs, RunFolder: r.runFolder, Functions: []FunctionStatus{}}
+	if !r.runStart.IsZero() {
+		start := r.runStart
+		status.RunStart = &start
+	}
+	r.mu.Unlock()
+
+	for _, f := range r.sortedFunctions() {
+		s := f.snapshot()
+		fs := FunctionStatus{
+			Function:   f.Name,
+			Provider:   f.Provider,
+			Region:     f.Region,
+			MemorySize: f.MemorySize,
+			Total:      s.total,
+			Remaining:  s.remaining,
+			Started:    s.started,
+			Completed:  s.completed,
+			Failed:     s.failed,
+			Retries:    s.retries,
+			InFlight:   s.inFlight,
+			CPUs:       s.cpus,
+		}
+		if s.count > 0 {
+			mean := s.sum / float64(s.count)
+			fs.MeanLatencyMs = &mean
+		}
+		if len(s.recent) > 0 {
+			p50, p99 := stats.Quantile(s.recent, 0.5), stats.Quantile(s.recent, 0.99)
+			fs.P50LatencyMs, fs.P99LatencyMs = &p50, &p99
+		}
+		status.Functions = append(status.Functions, fs)
+	}
+	return status
+}
+
+// metric describes a metric family of the Prometheus output.
+type metric struct {
+	name, help, kind string
+	value            func(s snapshot) float64
+}
+
+var counters = []metric{
+	{"classifaas_requests_started_total", "Requests started by the load generator.", "counter", func(s snapshot) float64 { return float64(s.started) }},
+	{"classifaas_requests_completed_total", "Requests completed successfully.", "counter", func(s snapshot) float64 { return float64(s.completed) }},
+	{"classifaas_requests_failed_total", "Requests failed after all retries.", "counter", func(s snapshot) float64 { return float64(s.failed) }},
+	{"classifaas_request_retries_total", "Retried request attempts.", "counter", func(s snapshot) float64 { return float64(s.retries) }},
+	{"classifaas_requests_in_flight", "Requests currently in flight.", "gauge", func(s snapshot) float64 { return float64(s.inFlight) }},
+}
+
+var queueGauges = []metric{
+	{"classifaas_queue_total_requests", "Requests planned for the function in the current run.", "gauge", func(s snapshot) float64 { return float64(s.total) }},
+	{"classifaas_queue_remaining_requests", "Requests of the current run not yet started.", "gauge", func(s snapshot) float64 { return float64(s.remaining) }},
+}
+
+func (r *Registry) writePrometheus(w io.Writer) {
+	functions := r.sortedFunctions()
+	snapshots := make([]snapshot, len(functions))
+	labels := make([]string, len(functions))
+	for i, f := range functions {
+		snapshots[i] = f.snapshot()
+		labels[i] = fmt.Sprintf(`function="%s",provider="%s",region="%s",memory_size="%d"`,
+			escapeLabel(f.Name), escapeLabel(f.Provider), escapeLabel(f.Region), f.MemorySize)
+	}
+
+	r.mu.Lock()
+	runs := r.runs
+	r.mu.Unlock()
+	fmt.Fprintf(w, "# HELP classifaas_runs_total Benchmark runs started.\n# TYPE classifaas_runs_total counter\nclassifaas_runs_total %d\n", runs)
+
+	for _, m := range counters {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", m.name, m.help, m.name, m.kind)
+		for i := range functions {
+			fmt.Fprintf(w, "%s{%s} %s\n", m.name, labels[i], formatValue(m.value(snapshots[i])))
+		}
+	}
+	for _, m := range queueGauges {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", m.name, m.help, m.name, m.kind)
+		for i := range functions {
+			if snapshots[i].hasQueue {
+				fmt.Fprintf(w, "%s{%s} %s\n", m.name, labels[i], formatValue(m.value(snapshots[i])))
+			}
+		}
+	}
+
+	const cpuCounter = "classifaas_cpu_observations_total"
+	fmt.Fprintf(w, "# HELP %s Successful requests per reported CPU model.\n# TYPE %s counter\n", cpuCounter, cpuCounter)
+	for i, s := range snapshots {
+		cpus := make([]string, 0, len(s.cpus))
+		for cpu := range s.cpus {
+			cpus = append(cpus, cpu)
+		}
+		sort.Strings(cpus)
+		for _, cpu := range cpus {
+			fmt.Fprintf(w, "%s{%s,cpu=\"%s\"} %d\n", cpuCounter, labels[i], escapeLabel(cpu), s.cpus[cpu])
+		}
+	}
+
+	const histogram = "classifaas_request_latency_milliseconds"
+	fmt.Fprintf(w, "# HELP %s Client latency of successful requests.\n# TYPE %s histogram\n", histogram, histogram)
+	for i, s := range snapshots {
+		for j, bound := range latencyBuckets {
+			fmt.Fprintf(w, "%s_bucket{%s,le=\"%s\"} %d\n", histogram, labels[i], formatValue(bound), s.buckets[j])
+		}
+		fmt.Fprintf(w, "%s_bucket{%s,le=\"+Inf\"} %d\n", histogram, labels[i], s.count)
+		fmt.Fprintf(w, "%s_sum{%s} %s\n", histogram, labels[i], formatValue(s.sum))
+		fmt.Fprintf(w, "%s_count{%s} %d\n", histogram, labels[i], s.count)
+	}
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(v string) string {
+	return labelEscaper.Replace(v)
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
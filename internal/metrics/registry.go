@@ -0,0 +1,202 @@
+package metrics
+
+import (
+	"ClassiFaaS/internal/stats"
+	"ClassiFaaS/internal/utils"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// latencyBuckets are the upper bounds in milliseconds of the latency histogram.
+var latencyBuckets = []float64{5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000, 30000, 60000}
+
+// recentLatencies is the number of latest latencies quantiles are computed from.
+const recentLatencies = 1000
+
+// Registry collects live metrics of the load generators of one or more
+// consecutive benchmark runs. Counters accumulate across runs.
+type Registry struct {
+	mu        sync.Mutex
+	functions map[functionKey]*Function
+	runs      int
+	runFolder string
+	runStart  time.Time
+}
+
+type functionKey struct {
+	name, provider, region string
+	memorySize             int
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{functions: make(map[functionKey]*Function)}
+}
+
+// StartRun records the start of a benchmark run writing to runFolder.
+func (r *Registry) StartRun(runFolder string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.runs++
+	r.runFolder = runFolder
+	r.runStart = time.Now()
+}
+
+// Function returns the metrics of a benchmarked function, creating them on first use.
+func (r *Registry) Function(name, provider, region string, memorySize int) *Function {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := functionKey{name, provider, region, memorySize}
+	if f, ok := r.functions[key]; ok {
+		return f
+	}
+	f := &Function{
+		Name:       name,
+		Provider:   provider,
+		Region:     region,
+		MemorySize: memorySize,
+		buckets:    make([]uint64, len(latencyBuckets)),
+		cpus:       make(map[string]int64),
+	}
+	r.functions[key] = f
+	return f
+}
+
+// sortedFunctions returns all functions ordered by their labels.
+func (r *Registry) sortedFunctions() []*Function {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	functions := make([]*Function, 0, len(r.functions))
+	for _, f := range r.functions {
+		functions = append(functions, f)
+	}
+	sort.Slice(functions, func(i, j int) bool {
+		a, b := functions[i], functions[j]
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		if a.Region != b.Region {
+			return a.Region < b.Region
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.MemorySize < b.MemorySize
+	})
+	return functions
+}
+
+// Function holds the request counters and latency histogram of a function.
+type Function struct {
+	Name       string
+	Provider   string
+	Region     string
+	MemorySize int
+
+	started   atomic.Int64
+	completed atomic.Int64
+	failed    atomic.Int64
+	retries   atomic.Int64
+	inFlight  atomic.Int64
+
+	mu      sync.Mutex
+	buckets []uint64
+	count   uint64
+	sum     float64
+	recent  []float64
+	cpus    map[string]int64
+	queue   func() (int, int)
+}
+
+// SetQueue registers the queue state of the function's current load
+// generator, returning the total and remaining number of requests.
+func (f *Function) SetQueue(state func() (int, int)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.queue = state
+}
+
+// RequestStarted records the start of a request.
+func (f *Function) RequestStarted() {
+	f.started.Add(1)
+	f.inFlight.Add(1)
+}
+
+// RequestFinished records the outcome of a request after the given number
+// of attempts; result is nil if the request failed. For successful requests
+// the client latency and the CPU model (SAAF cpuType) are observed.
+func (f *Function) RequestFinished(attempts int, result *utils.BenchmarkResponse) {
+	f.inFlight.Add(-1)
+	if attempts > 1 {
+		f.retries.Add(int64(attempts - 1))
+	}
+	if result == nil {
+		f.failed.Add(1)
+		return
+	}
+	f.completed.Add(1)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if result.Client != nil {
+		latencyMs := result.Client.LatencyMs
+		for i, bound := range latencyBuckets {
+			if latencyMs <= bound {
+				f.buckets[i]++
+			}
+		}
+		f.count++
+		f.sum += latencyMs
+
+		if len(f.recent) == recentLatencies {
+			f.recent = f.recent[1:]
+		}
+		f.recent = append(f.recent, latencyMs)
+	}
+	if cpu, ok := result.LookupString("cpuType"); ok && cpu != "" {
+		f.cpus[cpu]++
+	}
+}
+
+// snapshot is a consistent copy of a function's metrics.
+type snapshot struct {
+	started, completed, failed, retries, inFlight int64
+	buckets                                       []uint64
+	count                                         uint64
+	sum                                           float64
+	recent                                        []float64
+	cpus                                          map[string]int64
+	total, remaining                              int
+	hasQueue                                      bool
+}
+
+func (f *Function) snapshot() snapshot {
+	f.mu.Lock()
+	s := snapshot{
+		buckets: append([]uint64(nil), f.buckets...),
+		count:   f.count,
+		sum:     f.sum,
+		recent:  stats.Sorted(f.recent),
+		cpus:    make(map[string]int64, len(f.cpus)),
+	}
+	for cpu, count := range f.cpus {
+		s.cpus[cpu] = count
+	}
+	queue := f.queue
+	f.mu.Unlock()
+
+	s.started = f.started.Load()
+	s.completed = f.completed.Load()
+	s.failed = f.failed.Load()
+	s.retries = f.retries.Load()
+	s.inFlight = f.inFlight.Load()
+	if queue != nil {
+		s.total, s.remaining = queue()
+		s.hasQueue = true
+	}
+	return s
+}
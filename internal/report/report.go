@@ -0,0 +1,257 @@
+package report
+
+import (
+	"ClassiFaaS/internal/analysis"
+	"ClassiFaaS/internal/results"
+	"ClassiFaaS/internal/stats"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+//go:embed report.html.tmpl
+var reportTemplate string
+
+var tmpl = template.Must(template.New("report").Funcs(template.FuncMap{
+	"ms": func(v float64) string { return fmt.Sprintf("%.1f", v) },
+}).Parse(reportTemplate))
+
+// maxCDFPoints bounds the number of points drawn per CDF.
+const maxCDFPoints = 500
+
+// Report is the content of the HTML report of a single run.
+type Report struct {
+	Run        string
+	Folder     string
+	Start      time.Time
+	End        time.Time
+	Generated  time.Time
+	Functions  []FunctionStats
+	Manifest   []Manifest
+	LatencyCDF template.HTML
+	Throughput template.HTML
+	Benchmarks []Figure
+}
+
+// FunctionStats holds the request outcome and latency statistics of a function.
+type FunctionStats struct {
+	Name       string
+	Provider   string
+	Region     string
+	MemorySize int
+	Benchmark  string
+	Records    int
+	// Completed and Failed are nil for runs recorded without summary.
+	Completed  *int64
+	Failed     *int64
+	Retried    int
+	Retries    int
+	ColdStarts int
+	Latency    stats.Description
+}
+
+// Manifest lists the archived metadata of a function.
+type Manifest struct {
+	Function string
+	Entries  []Entry
+}
+
+// Entry is a single metadata key and value.
+type Entry struct {
+	Key   string
+	Value string
+}
+
+// Figure is a titled chart.
+type Figure struct {
+	Title string
+	Chart template.HTML
+}
+
+// Build computes the report of a run.
+func Build(run *results.Run) *Report {
+	report := &Report{
+		Run:       filepath.Base(run.Folder),
+		Folder:    run.Folder,
+		Start:     run.Start(),
+		Generated: time.Now(),
+	}
+	if run.Summary != nil {
+		report.End = run.Summary.End
+	}
+
+	var cdfs, throughput []series
+	origin := clientOrigin(run)
+	for _, archive := range run.Archives {
+		name := archive.Function()
+		fn := FunctionStats{
+			Name:       name,
+			Provider:   archive.Provider(),
+			Region:     archive.Region(),
+			MemorySize: archive.MemorySize(),
+			Benchmark:  analysis.BenchmarkType(archive),
+			Records:    len(archive.Records),
+		}
+		fn.Completed, fn.Failed = summaryCounts(run, archive)
+
+		var completions []float64
+		for i := range archive.Records {
+			record := &archive.Records[i]
+			if record.Client != nil {
+				if record.Client.Attempts > 1 {
+					fn.Retried++
+					fn.Retries += record.Client.Attempts - 1
+				}
+				end := record.Client.Start.Add(time.Duration(record.Client.LatencyMs * float64(time.Millisecond)))
+				completions = append(completions, end.Sub(origin).Seconds())
+			}
+			if cold, ok := record.LookupFloat("newcontainer"); ok && cold == 1 {
+				fn.ColdStarts++
+			}
+		}
+
+		latencies := analysis.MetricValues(archive, analysis.MetricLatency)
+		fn.Latency = stats.Describe(latencies)
+		report.Functions = append(report.Functions, fn)
+
+		cdfs = append(cdfs, series{Name: name, Points: cdf(latencies)})
+		throughput = append(throughput, series{Name: name, Points: rate(completions)})
+
+		report.Manifest = append(report.Manifest, manifest(archive))
+		metric, ok := analysis.BenchmarkMetrics[fn.Benchmark]
+		if !ok {
+			metric = "primary benchmark metric"
+		}
+		report.Benchmarks = append(report.Benchmarks, Figure{
+			Title: fmt.Sprintf("%s (%s)", name, fn.Benchmark),
+			Chart: boxPlot(benchmarkByCPU(archive), metric),
+		})
+	}
+
+	report.LatencyCDF = lineChart(cdfs, "client latency (ms)", "fraction of requests", true)
+	report.Throughput = lineChart(throughput, "seconds since first request", "completed requests / s", false)
+	return report
+}
+
+// Write renders the report as self-contained HTML.
+func (r *Report) Write(w io.Writer) error {
+	return tmpl.Execute(w, r)
+}
+
+// summaryCounts sums the completed and failed requests of an archive's
+// function over all summary entries, e.g. of several agents.
+func summaryCounts(run *results.Run, archive *results.Archive) (*int64, *int64) {
+	if run.Summary == nil {
+		return nil, nil
+	}
+	var completed, failed int64
+	for _, fn := range run.Summary.Functions {
+		if fn.Function == archive.Function() && fn.Provider == archive.Provider() &&
+			fn.Region == archive.Region() && fn.MemorySize == archive.MemorySize() {
+			completed += fn.Completed
+			failed += fn.Failed
+		}
+	}
+	return &completed, &failed
+}
+
+// clientOrigin returns the earliest client start time of the run.
+func clientOrigin(run *results.Run) time.Time {
+	var origin time.Time
+	for _, archive := range run.Archives {
+		for i := range archive.Records {
+			if c := archive.Records[i].Client; c != nil && (origin.IsZero() || c.Start.Before(origin)) {
+				origin = c.Start
+			}
+		}
+	}
+	return origin
+}
+
+// cdf returns the empirical distribution function of values, thinned out to
+// at most maxCDFPoints points.
+func cdf(values []float64) []point {
+	sorted := stats.Sorted(values)
+	n := len(sorted)
+	stride := int(math.Ceil(float64(n) / maxCDFPoints))
+	var points []point
+	for i := 0; i < n; i += stride {
+		points = append(points, point{X: sorted[i], Y: float64(i+1) / float64(n)})
+	}
+	if n > 0 && points[len(points)-1].Y != 1 {
+		points = append(points, point{X: sorted[n-1], Y: 1})
+	}
+	return points
+}
+
+// rate buckets completion offsets (in seconds) into a completions-per-second series.
+func rate(offsets []float64) []point {
+	if len(offsets) == 0 {
+		return nil
+	}
+	last := 0.0
+	for _, o := range offsets {
+		last = math.Max(last, o)
+	}
+
+	// choose the smallest round bucket width resulting in at most 120 buckets
+	width := 1.0
+	for _, w := range []float64{1, 2, 5, 10, 15, 30, 60, 120, 300, 600, 900, 1800, 3600} {
+		width = w
+		if last/w <= 120 {
+			break
+		}
+	}
+
+	counts := make([]int, int(last/width)+1)
+	for _, o := range offsets {
+		counts[int(math.Max(o, 0)/width)]++
+	}
+	points := make([]point, len(counts))
+	for i, c := range counts {
+		points[i] = point{X: float64(i) * width, Y: float64(c) / width}
+	}
+	return points
+}
+
+// benchmarkByCPU groups the primary benchmark metric of an archive by CPU type.
+func benchmarkByCPU(archive *results.Archive) []box {
+	groups := make(map[string][]float64)
+	for i := range archive.Records {
+		record := &archive.Records[i]
+		v, ok := analysis.MetricValue(record, analysis.MetricBenchmark)
+		if !ok {
+			continue
+		}
+		cpu, ok := record.LookupString("cpuType")
+		if !ok || cpu == "" {
+			cpu = "unknown"
+		}
+		groups[cpu] = append(groups[cpu], v)
+	}
+
+	boxes := make([]box, 0, len(groups))
+	for cpu, values := range groups {
+		boxes = append(boxes, box{Label: cpu, Values: values})
+	}
+	sort.Slice(boxes, func(i, j int) bool {
+		return boxes[i].Label < boxes[j].Label
+	})
+	return boxes
+}
+
+func manifest(archive *results.Archive) Manifest {
+	m := Manifest{Function: archive.Function()}
+	for key, value := range archive.Metadata {
+		m.Entries = append(m.Entries, Entry{Key: key, Value: value})
+	}
+	sort.Slice(m.Entries, func(i, j int) bool {
+		return m.Entries[i].Key < m.Entries[j].Key
+	})
+	return m
+}
@@ -0,0 +1,229 @@
+package report
+
+import (
+	"ClassiFaaS/internal/stats"
+	"fmt"
+	"html"
+	"html/template"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Chart dimensions in SVG user units.
+const (
+	chartWidth   = 760
+	chartHeight  = 320
+	marginLeft   = 64
+	marginRight  = 16
+	marginTop    = 16
+	marginBottom = 48
+)
+
+// palette colors the series of a chart.
+var palette = []string{
+	"#1f77b4", "#ff7f0e", "#2ca02c", "#d62728", "#9467bd",
+	"#8c564b", "#e377c2", "#7f7f7f", "#bcbd22", "#17becf",
+}
+
+func color(i int) string {
+	return palette[i%len(palette)]
+}
+
+// point is a data point of a line series.
+type point struct{ X, Y float64 }
+
+// series is a named line of a line chart.
+type series struct {
+	Name   string
+	Points []point
+}
+
+// scale maps a data range linearly onto a pixel range.
+type scale struct {
+	min, max float64
+	from, to float64
+}
+
+func (s scale) at(v float64) float64 {
+	if s.max == s.min {
+		return (s.from + s.to) / 2
+	}
+	return s.from + (v-s.min)/(s.max-s.min)*(s.to-s.from)
+}
+
+// niceTicks returns about n evenly spaced round tick values covering [min, max].
+func niceTicks(min, max float64, n int) []float64 {
+	if max <= min {
+		return []float64{min}
+	}
+	raw := (max - min) / float64(n)
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	step := magnitude
+	for _, m := range []float64{1, 2, 5, 10} {
+		step = m * magnitude
+		if step >= raw {
+			break
+		}
+	}
+
+	var ticks []float64
+	for v := math.Ceil(min/step) * step; v <= max+step*1e-9; v += step {
+		ticks = append(ticks, v)
+	}
+	return ticks
+}
+
+func formatTick(v float64) string {
+	return strconv.FormatFloat(v, 'g', 4, 64)
+}
+
+// frame writes the SVG header, axes, grid lines and labels.
+func frame(b *strings.Builder, xs, ys scale, xTicks, yTicks []string, xPos, yPos []float64, xLabel, yLabel string) {
+	fmt.Fprintf(b, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" class="chart">`, chartWidth, chartHeight)
+	for i, y := range yPos {
+		py := ys.at(y)
+		fmt.Fprintf(b, `<line x1="%d" x2="%d" y1="%.1f" y2="%.1f" class="grid"/>`, marginLeft, chartWidth-marginRight, py, py)
+		fmt.Fprintf(b, `<text x="%d" y="%.1f" class="tick" text-anchor="end">%s</text>`, marginLeft-6, py+4, html.EscapeString(yTicks[i]))
+	}
+	for i, x := range xPos {
+		px := xs.at(x)
+		fmt.Fprintf(b, `<text x="%.1f" y="%d" class="tick" text-anchor="middle">%s</text>`, px, chartHeight-marginBottom+16, html.EscapeString(xTicks[i]))
+	}
+	fmt.Fprintf(b, `<line x1="%d" x2="%d" y1="%d" y2="%d" class="axis"/>`, marginLeft, chartWidth-marginRight, chartHeight-marginBottom, chartHeight-marginBottom)
+	fmt.Fprintf(b, `<line x1="%d" x2="%d" y1="%d" y2="%d" class="axis"/>`, marginLeft, marginLeft, marginTop, chartHeight-marginBottom)
+	fmt.Fprintf(b, `<text x="%d" y="%d" class="label" text-anchor="middle">%s</text>`, (marginLeft+chartWidth-marginRight)/2, chartHeight-8, html.EscapeString(xLabel))
+	fmt.Fprintf(b, `<text transform="translate(14 %d) rotate(-90)" class="label" text-anchor="middle">%s</text>`, (marginTop+chartHeight-marginBottom)/2, html.EscapeString(yLabel))
+}
+
+func numericTicks(min, max float64) ([]string, []float64) {
+	values := niceTicks(min, max, 6)
+	labels := make([]string, len(values))
+	for i, v := range values {
+		labels[i] = formatTick(v)
+	}
+	return labels, values
+}
+
+// lineChart renders the series as an SVG line chart. If step is set, lines
+// are drawn as step functions, as used for CDFs.
+func lineChart(lines []series, xLabel, yLabel string, step bool) template.HTML {
+	xMin, xMax := math.Inf(1), math.Inf(-1)
+	yMin, yMax := 0.0, math.Inf(-1)
+	for _, s := range lines {
+		for _, p := range s.Points {
+			xMin, xMax = math.Min(xMin, p.X), math.Max(xMax, p.X)
+			yMin, yMax = math.Min(yMin, p.Y), math.Max(yMax, p.Y)
+		}
+	}
+	if math.IsInf(xMin, 1) {
+		return template.HTML(`<p class="empty">No data.</p>`)
+	}
+
+	xTickLabels, xTicks := numericTicks(xMin, xMax)
+	yTickLabels, yTicks := numericTicks(yMin, yMax)
+	xs := scale{min: math.Min(xMin, xTicks[0]), max: math.Max(xMax, xTicks[len(xTicks)-1]), from: marginLeft, to: chartWidth - marginRight}
+	ys := scale{min: math.Min(yMin, yTicks[0]), max: math.Max(yMax, yTicks[len(yTicks)-1]), from: chartHeight - marginBottom, to: marginTop}
+
+	var b strings.Builder
+	frame(&b, xs, ys, xTickLabels, yTickLabels, xTicks, yTicks, xLabel, yLabel)
+	for i, s := range lines {
+		var path strings.Builder
+		for j, p := range s.Points {
+			cmd := "L"
+			if j == 0 {
+				cmd = "M"
+			} else if step {
+				fmt.Fprintf(&path, "H%.1f", xs.at(p.X))
+			}
+			fmt.Fprintf(&path, "%s%.1f %.1f", cmd, xs.at(p.X), ys.at(p.Y))
+		}
+		fmt.Fprintf(&b, `<path d="%s" fill="none" stroke="%s" stroke-width="1.5"><title>%s</title></path>`,
+			path.String(), color(i), html.EscapeString(s.Name))
+	}
+	b.WriteString(`</svg>`)
+	b.WriteString(legend(lines))
+	return template.HTML(b.String())
+}
+
+func legend(lines []series) string {
+	var b strings.Builder
+	b.WriteString(`<ul class="legend">`)
+	for i, s := range lines {
+		fmt.Fprintf(&b, `<li><span class="swatch" style="background:%s"></span>%s</li>`, color(i), html.EscapeString(s.Name))
+	}
+	b.WriteString(`</ul>`)
+	return b.String()
+}
+
+// box is a labelled sample rendered as a box plot.
+type box struct {
+	Label  string
+	Values []float64
+}
+
+// boxPlot renders Tukey box plots (whiskers at 1.5 IQR) side by side.
+func boxPlot(boxes []box, yLabel string) template.HTML {
+	yMin, yMax := math.Inf(1), math.Inf(-1)
+	for _, bx := range boxes {
+		for _, v := range bx.Values {
+			yMin, yMax = math.Min(yMin, v), math.Max(yMax, v)
+		}
+	}
+	if math.IsInf(yMin, 1) {
+		return template.HTML(`<p class="empty">No data.</p>`)
+	}
+
+	yTickLabels, yTicks := numericTicks(yMin, yMax)
+	ys := scale{min: math.Min(yMin, yTicks[0]), max: math.Max(yMax, yTicks[len(yTicks)-1]), from: chartHeight - marginBottom, to: marginTop}
+	xs := scale{min: -0.5, max: float64(len(boxes)) - 0.5, from: marginLeft, to: chartWidth - marginRight}
+
+	xLabels := make([]string, len(boxes))
+	xPos := make([]float64, len(boxes))
+	for i, bx := range boxes {
+		xLabels[i] = fmt.Sprintf("%s (n=%d)", bx.Label, len(bx.Values))
+		xPos[i] = float64(i)
+	}
+
+	var b strings.Builder
+	frame(&b, xs, ys, xLabels, yTickLabels, xPos, yTicks, "", yLabel)
+	halfWidth := math.Min(40, (xs.at(1)-xs.at(0))*0.3)
+	for i, bx := range boxes {
+		if len(bx.Values) == 0 {
+			continue
+		}
+		sorted := stats.Sorted(bx.Values)
+		q1, median, q3 := stats.Quantile(sorted, 0.25), stats.Quantile(sorted, 0.5), stats.Quantile(sorted, 0.75)
+		low, high := q1-1.5*(q3-q1), q3+1.5*(q3-q1)
+		whiskerLow, whiskerHigh := median, median
+		for _, v := range sorted {
+			if v >= low {
+				whiskerLow = v
+				break
+			}
+		}
+		for j := len(sorted) - 1; j >= 0; j-- {
+			if sorted[j] <= high {
+				whiskerHigh = sorted[j]
+				break
+			}
+		}
+
+		x := xs.at(float64(i))
+		c := color(i)
+		fmt.Fprintf(&b, `<g><title>%s: median %s, IQR %s–%s</title>`, html.EscapeString(bx.Label), formatTick(median), formatTick(q1), formatTick(q3))
+		fmt.Fprintf(&b, `<line x1="%.1f" x2="%.1f" y1="%.1f" y2="%.1f" stroke="%s"/>`, x, x, ys.at(whiskerLow), ys.at(q1), c)
+		fmt.Fprintf(&b, `<line x1="%.1f" x2="%.1f" y1="%.1f" y2="%.1f" stroke="%s"/>`, x, x, ys.at(q3), ys.at(whiskerHigh), c)
+		fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s" fill-opacity="0.3" stroke="%s"/>`,
+			x-halfWidth, ys.at(q3), 2*halfWidth, math.Max(ys.at(q1)-ys.at(q3), 0.5), c, c)
+		fmt.Fprintf(&b, `<line x1="%.1f" x2="%.1f" y1="%.1f" y2="%.1f" stroke="%s" stroke-width="2"/>`, x-halfWidth, x+halfWidth, ys.at(median), ys.at(median), c)
+		for _, v := range sorted {
+			if v < whiskerLow || v > whiskerHigh {
+				fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="2" fill="none" stroke="%s"/>`, x, ys.at(v), c)
+			}
+		}
+		b.WriteString(`</g>`)
+	}
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
@@ -0,0 +1,195 @@
+package results
+
+import (
+	"ClassiFaaS/internal/loadgenerator"
+	"ClassiFaaS/internal/utils"
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// maxLineSize bounds the size of a single archived record.
+const maxLineSize = 16 * 1024 * 1024
+
+// runFolderLayout is the time layout of run folder names, see loadgenerator.RunFolder.
+const runFolderLayout = "2006-01-02_15-04"
+
+// Archive is the content of a single function's result log: the metadata
+// line followed by one benchmark response per invocation.
+type Archive struct {
+	Path     string
+	Metadata map[string]string
+	Records  []utils.BenchmarkResponse
+}
+
+// Run is a benchmark run folder with all function archives it contains.
+type Run struct {
+	Folder string
+	// Summary is nil for runs recorded before summaries were written.
+	Summary  *loadgenerator.RunSummary
+	Archives []*Archive
+}
+
+// Function returns the configured function name of the archive.
+func (a *Archive) Function() string {
+	return a.Metadata["function"]
+}
+
+// Provider returns the provider the archived function is deployed on.
+func (a *Archive) Provider() string {
+	return a.Metadata["provider"]
+}
+
+// Region returns the region the archived function is deployed in.
+func (a *Archive) Region() string {
+	return a.Metadata["region"]
+}
+
+// MemorySize returns the memory size of the archived function in MB.
+func (a *Archive) MemorySize() int {
+	size, _ := strconv.Atoi(a.Metadata["memorySize"])
+	return size
+}
+
+// Timestamp returns the time the archive was created.
+func (a *Archive) Timestamp() time.Time {
+	t, _ := time.Parse(time.RFC3339, a.Metadata["timestamp"])
+	return t
+}
+
+// Start returns the start of the run from its summary, or the earliest
+// archive timestamp for runs recorded without summary.
+func (r *Run) Start() time.Time {
+	if r.Summary != nil {
+		return r.Summary.Start
+	}
+	var start time.Time
+	for _, archive := range r.Archives {
+		if t := archive.Timestamp(); !t.IsZero() && (start.IsZero() || t.Before(start)) {
+			start = t
+		}
+	}
+	return start
+}
+
+// LoadRun reads the summary and all archives of a run folder.
+func LoadRun(folder string) (*Run, error) {
+	run := &Run{Folder: folder}
+
+	data, err := os.ReadFile(filepath.Join(folder, loadgenerator.RunSummaryFile))
+	if err == nil {
+		var summary loadgenerator.RunSummary
+		if err := json.Unmarshal(data, &summary); err != nil {
+			return nil, fmt.Errorf("failed to parse run summary: %v", err)
+		}
+		run.Summary = &summary
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	err = filepath.WalkDir(folder, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != ".log" {
+			return nil
+		}
+
+		archive, err := ReadArchive(path)
+		if err != nil {
+			return err
+		}
+		run.Archives = append(run.Archives, archive)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(run.Archives) == 0 {
+		return nil, fmt.Errorf("no archives found in %s", folder)
+	}
+
+	sort.Slice(run.Archives, func(i, j int) bool {
+		return run.Archives[i].Path < run.Archives[j].Path
+	})
+	return run, nil
+}
+
+// ReadArchive reads a function's result log.
+func ReadArchive(path string) (*Archive, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	archive := &Archive{Path: path}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		if archive.Metadata == nil {
+			if err := json.Unmarshal(scanner.Bytes(), &archive.Metadata); err != nil {
+				return nil, fmt.Errorf("%s: invalid metadata line: %v", path, err)
+			}
+			continue
+		}
+
+		var record utils.BenchmarkResponse
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid record: %v", path, line, err)
+		}
+		archive.Records = append(archive.Records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if archive.Metadata == nil {
+		return nil, fmt.Errorf("%s: archive is empty", path)
+	}
+	return archive, nil
+}
+
+// FindRuns returns the run folders directly below resultFolder whose
+// timestamp lies within [since, until), ordered chronologically. Zero
+// bounds are open.
+func FindRuns(resultFolder string, since, until time.Time) ([]string, error) {
+	entries, err := os.ReadDir(resultFolder)
+	if err != nil {
+		return nil, err
+	}
+
+	var folders []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		started, err := ParseRunFolder(entry.Name())
+		if err != nil {
+			continue
+		}
+		if (!since.IsZero() && started.Before(since)) || (!until.IsZero() && !started.Before(until)) {
+			continue
+		}
+		folders = append(folders, filepath.Join(resultFolder, entry.Name()))
+	}
+
+	// the timestamp format sorts lexicographically
+	sort.Strings(folders)
+	return folders, nil
+}
+
+// ParseRunFolder returns the start time encoded in a run folder name.
+func ParseRunFolder(name string) (time.Time, error) {
+	return time.ParseInLocation(runFolderLayout, filepath.Base(name), time.Local)
+}
@@ -0,0 +1,213 @@
+package scheduler
+
+import (
+	"ClassiFaaS/internal/config"
+	"ClassiFaaS/internal/utils"
+	"context"
+	"fmt"
+	"math/rand"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// maxWindowSearch bounds the number of cron activations skipped while looking
+// for one that falls into a configured window.
+const maxWindowSearch = 10000
+
+// RunFunc executes a single benchmark run and returns the folder its results were written to.
+type RunFunc func(ep utils.EventPublisher) (string, error)
+
+// Campaign executes benchmark runs back-to-back according to a schedule.
+// Runs never overlap: the next start time is computed once the previous run
+// has finished, skipping activations that were missed in the meantime.
+type Campaign struct {
+	cfg       config.CampaignConfig
+	cron      *cronSchedule
+	windows   []window
+	indexPath string
+	run       RunFunc
+}
+
+// NewCampaign creates a campaign recording completed runs in the index at indexPath.
+func NewCampaign(cfg config.CampaignConfig, indexPath string, run RunFunc) (*Campaign, error) {
+	c := &Campaign{
+		cfg:       cfg,
+		indexPath: indexPath,
+		run:       run,
+	}
+
+	if cfg.Schedule.Cron != "" {
+		cron, err := parseCron(cfg.Schedule.Cron)
+		if err != nil {
+			return nil, err
+		}
+		c.cron = cron
+	}
+
+	for _, w := range cfg.Windows {
+		win, err := newWindow(w)
+		if err != nil {
+			return nil, err
+		}
+		c.windows = append(c.windows, win)
+	}
+
+	return c, nil
+}
+
+// Run executes the campaign until ctx is cancelled or MaxRuns runs have been
+// recorded in the index. Runs recorded by earlier invocations count towards
+// MaxRuns, so an interrupted campaign can be resumed.
+func (c *Campaign) Run(ctx context.Context, ep utils.EventPublisher) error {
+	entries, err := LoadIndex(c.indexPath)
+	if err != nil {
+		return err
+	}
+
+	runNumber := len(entries)
+	var lastStart time.Time
+	if runNumber > 0 {
+		lastStart = entries[runNumber-1].Start
+	}
+
+	for c.cfg.MaxRuns == 0 || runNumber < c.cfg.MaxRuns {
+		scheduled, err := c.nextStart(time.Now(), lastStart)
+		if err != nil {
+			return err
+		}
+
+		ep.SendEvent(utils.SeverityInfo, "campaign_wait",
+			fmt.Sprintf("(%s) Next run #%d scheduled at %s", c.cfg.Name, runNumber+1, scheduled.Format(time.RFC3339)))
+
+		timer := time.NewTimer(time.Until(scheduled))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			ep.SendEvent(utils.SeverityInfo, "campaign_stopped", fmt.Sprintf("(%s) Campaign stopped", c.cfg.Name))
+			return nil
+		case <-timer.C:
+		}
+
+		runNumber++
+		entry := IndexEntry{
+			Campaign:  c.cfg.Name,
+			Run:       runNumber,
+			Scheduled: scheduled,
+			Start:     time.Now(),
+			Status:    RunCompleted,
+		}
+		lastStart = entry.Start
+
+		if err := c.refreshCredentials(ep); err != nil {
+			entry.Status, entry.Error = RunFailed, err.Error()
+		} else {
+			entry.RunFolder, err = c.run(ep)
+			if err != nil {
+				entry.Status, entry.Error = RunFailed, err.Error()
+			}
+		}
+		entry.End = time.Now()
+
+		if entry.Status == RunFailed {
+			ep.SendEvent(utils.SeverityError, "campaign_run", fmt.Sprintf("(%s) Run #%d failed: %s", c.cfg.Name, runNumber, entry.Error))
+		} else {
+			ep.SendEvent(utils.SeverityInfo, "campaign_run", fmt.Sprintf("(%s) Run #%d completed in %s", c.cfg.Name, runNumber, entry.End.Sub(entry.Start).Round(time.Second)))
+		}
+
+		if err := appendIndex(c.indexPath, entry); err != nil {
+			return fmt.Errorf("failed to record run #%d in campaign index: %v", runNumber, err)
+		}
+	}
+
+	ep.SendEvent(utils.SeverityInfo, "campaign_finished", fmt.Sprintf("(%s) Completed %d runs", c.cfg.Name, runNumber))
+	return nil
+}
+
+// refreshCredentials runs the configured refresh commands before a run.
+func (c *Campaign) refreshCredentials(ep utils.EventPublisher) error {
+	for _, r := range c.cfg.Refresh {
+		ep.SendEvent(utils.SeverityInfo, "campaign_refresh", fmt.Sprintf("Refreshing credentials: %s", r.Name))
+
+		cmd := exec.Command("bash", "-c", r.Command)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("credential refresh %q failed: %v, output: %s", r.Name, err, strings.TrimSpace(string(output)))
+		}
+	}
+	return nil
+}
+
+// nextStart computes when the next run starts, given the current time and
+// the start of the previous run (zero if there was none).
+func (c *Campaign) nextStart(now, lastStart time.Time) (time.Time, error) {
+	var next time.Time
+
+	if c.cron != nil {
+		next = now
+		found := false
+		for i := 0; i < maxWindowSearch && !found; i++ {
+			var err error
+			if next, err = c.cron.next(next); err != nil {
+				return time.Time{}, err
+			}
+			found = c.inWindow(next)
+		}
+		if !found {
+			return time.Time{}, fmt.Errorf("no cron activation of %q falls into the configured windows", c.cfg.Schedule.Cron)
+		}
+	} else {
+		next = lastStart.Add(c.cfg.Schedule.Interval)
+		if lastStart.IsZero() || next.Before(now) {
+			next = now
+		}
+		if !c.inWindow(next) {
+			next = c.nextWindowOpening(next)
+		}
+	}
+
+	// the jitter never delays a run past the end of its window
+	jitter := c.cfg.Schedule.Jitter
+	if closing, ok := c.windowClosing(next); ok && closing.Sub(next) < jitter {
+		jitter = closing.Sub(next)
+	}
+	if jitter > 0 {
+		next = next.Add(time.Duration(rand.Int63n(int64(jitter))))
+	}
+	return next, nil
+}
+
+func (c *Campaign) inWindow(t time.Time) bool {
+	if len(c.windows) == 0 {
+		return true
+	}
+	for _, w := range c.windows {
+		if w.contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// windowClosing returns the latest time at which a window containing t
+// closes. It returns false if no windows are configured.
+func (c *Campaign) windowClosing(t time.Time) (time.Time, bool) {
+	var latest time.Time
+	for _, w := range c.windows {
+		if closing := w.closing(t); w.contains(t) && closing.After(latest) {
+			latest = closing
+		}
+	}
+	return latest, !latest.IsZero()
+}
+
+func (c *Campaign) nextWindowOpening(t time.Time) time.Time {
+	var earliest time.Time
+	for _, w := range c.windows {
+		opening := w.nextOpening(t)
+		if earliest.IsZero() || opening.Before(earliest) {
+			earliest = opening
+		}
+	}
+	return earliest
+}
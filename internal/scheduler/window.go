@@ -0,0 +1,66 @@
+package scheduler
+
+import (
+	"ClassiFaaS/internal/config"
+	"time"
+)
+
+// window is a daily time range in which runs may start.
+type window struct {
+	start, end time.Duration
+	days       map[time.Weekday]bool
+}
+
+func newWindow(cfg config.WindowConfig) (window, error) {
+	bounds, err := cfg.Bounds()
+	if err != nil {
+		return window{}, err
+	}
+	days, err := cfg.Weekdays()
+	if err != nil {
+		return window{}, err
+	}
+	return window{start: bounds[0], end: bounds[1], days: days}, nil
+}
+
+func midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func (w window) onDay(day time.Weekday) bool {
+	return len(w.days) == 0 || w.days[day]
+}
+
+// contains reports whether t lies inside the window. Windows spanning
+// midnight belong to the day they open on.
+func (w window) contains(t time.Time) bool {
+	offset := t.Sub(midnight(t))
+	if w.start <= w.end {
+		return w.onDay(t.Weekday()) && offset >= w.start && offset < w.end
+	}
+	if offset >= w.start {
+		return w.onDay(t.Weekday())
+	}
+	return offset < w.end && w.onDay((t.Weekday()+6)%7)
+}
+
+// closing returns the time at which the window containing t closes.
+func (w window) closing(t time.Time) time.Time {
+	day := midnight(t)
+	if w.start > w.end && t.Sub(day) >= w.start {
+		day = time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, t.Location())
+	}
+	return day.Add(w.end)
+}
+
+// nextOpening returns the first time at or after t at which the window opens.
+func (w window) nextOpening(t time.Time) time.Time {
+	day := midnight(t)
+	for d := 0; d <= 7; d++ {
+		candidate := time.Date(day.Year(), day.Month(), day.Day()+d, 0, 0, 0, 0, t.Location()).Add(w.start)
+		if !candidate.Before(t) && w.onDay(candidate.Weekday()) {
+			return candidate
+		}
+	}
+	return t
+}
@@ -0,0 +1,54 @@
+package stats
+
+import "math"
+
+// Interval is a point estimate together with its confidence interval.
+type Interval struct {
+	Estimate float64 `json:"estimate"`
+	Lower    float64 `json:"lower"`
+	Upper    float64 `json:"upper"`
+}
+
+// HalfWidth returns half the width of the interval.
+func (i Interval) HalfWidth() float64 {
+	return (i.Upper - i.Lower) / 2
+}
+
+// RelativeHalfWidth returns the half-width relative to the absolute value of
+// the estimate, or +Inf if the estimate is zero.
+func (i Interval) RelativeHalfWidth() float64 {
+	if i.Estimate == 0 {
+		return math.Inf(1)
+	}
+	return i.HalfWidth() / math.Abs(i.Estimate)
+}
+
+// MeanCI returns the mean of values with a normal-approximation confidence
+// interval at the given level.
+func MeanCI(values []float64, confidence float64) Interval {
+	mean := Mean(values)
+	if len(values) < 2 {
+		return Interval{Estimate: mean, Lower: math.Inf(-1), Upper: math.Inf(1)}
+	}
+	margin := NormalQuantile(confidence) * StdDev(values) / math.Sqrt(float64(len(values)))
+	return Interval{Estimate: mean, Lower: mean - margin, Upper: mean + margin}
+}
+
+// MedianCI returns the median of values with a distribution-free confidence
+// interval built from the order statistics around the median.
+func MedianCI(values []float64, confidence float64) Interval {
+	sorted := Sorted(values)
+	n := len(sorted)
+	median := Quantile(sorted, 0.5)
+	if n < 2 {
+		return Interval{Estimate: median, Lower: math.Inf(-1), Upper: math.Inf(1)}
+	}
+
+	offset := NormalQuantile(confidence) * math.Sqrt(float64(n)) / 2
+	lower := int(math.Floor(float64(n)/2 - offset))
+	upper := int(math.Ceil(float64(n)/2+offset)) - 1
+	if lower < 0 || upper > n-1 {
+		return Interval{Estimate: median, Lower: math.Inf(-1), Upper: math.Inf(1)}
+	}
+	return Interval{Estimate: median, Lower: sorted[lower], Upper: sorted[upper]}
+}
@@ -0,0 +1,97 @@
+package stats
+
+import (
+	"math"
+	"sort"
+)
+
+// Mean returns the arithmetic mean of values, or NaN if values is empty.
+func Mean(values []float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// StdDev returns the sample standard deviation of values, or NaN if fewer
+// than two values are given.
+func StdDev(values []float64) float64 {
+	if len(values) < 2 {
+		return math.NaN()
+	}
+	mean := Mean(values)
+	var sq float64
+	for _, v := range values {
+		sq += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(sq / float64(len(values)-1))
+}
+
+// Sorted returns a sorted copy of values.
+func Sorted(values []float64) []float64 {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+	return sorted
+}
+
+// Median returns the median of values, or NaN if values is empty.
+func Median(values []float64) float64 {
+	return Quantile(Sorted(values), 0.5)
+}
+
+// Quantile returns the q-th quantile (0 <= q <= 1) of an already sorted slice
+// using linear interpolation between closest ranks.
+func Quantile(sorted []float64, q float64) float64 {
+	n := len(sorted)
+	if n == 0 {
+		return math.NaN()
+	}
+	if q <= 0 {
+		return sorted[0]
+	}
+	if q >= 1 {
+		return sorted[n-1]
+	}
+	pos := q * float64(n-1)
+	lower := int(math.Floor(pos))
+	upper := int(math.Ceil(pos))
+	frac := pos - float64(lower)
+	return sorted[lower] + frac*(sorted[upper]-sorted[lower])
+}
+
+// NormalQuantile returns the z value for a two-sided confidence level,
+// e.g. 1.96 for 0.95.
+func NormalQuantile(confidence float64) float64 {
+	return math.Sqrt2 * math.Erfinv(confidence)
+}
+
+// Description summarizes the distribution of a sample.
+type Description struct {
+	Count  int     `json:"count"`
+	Mean   float64 `json:"mean"`
+	Min    float64 `json:"min"`
+	Median float64 `json:"median"`
+	P99    float64 `json:"p99"`
+	Max    float64 `json:"max"`
+}
+
+// Describe summarizes values. An empty sample yields a zero Description.
+func Describe(values []float64) Description {
+	if len(values) == 0 {
+		return Description{}
+	}
+	sorted := Sorted(values)
+	return Description{
+		Count:  len(sorted),
+		Mean:   Mean(sorted),
+		Min:    sorted[0],
+		Median: Quantile(sorted, 0.5),
+		P99:    Quantile(sorted, 0.99),
+		Max:    sorted[len(sorted)-1],
+	}
+}
@@ -0,0 +1,183 @@
+package stats
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// NormalCDF returns the standard normal cumulative distribution function at x.
+func NormalCDF(x float64) float64 {
+	return 0.5 * math.Erfc(-x/math.Sqrt2)
+}
+
+// MannWhitneyU performs a two-sided Mann-Whitney U test using the normal
+// approximation with tie correction. It returns the U statistic of a and the p-value.
+func MannWhitneyU(a, b []float64) (u, p float64) {
+	n1, n2 := float64(len(a)), float64(len(b))
+	if n1 == 0 || n2 == 0 {
+		return math.NaN(), math.NaN()
+	}
+
+	type ranked struct {
+		value float64
+		fromA bool
+		rank  float64
+	}
+	all := make([]ranked, 0, len(a)+len(b))
+	for _, v := range a {
+		all = append(all, ranked{value: v, fromA: true})
+	}
+	for _, v := range b {
+		all = append(all, ranked{value: v})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].value < all[j].value })
+
+	// assign average ranks to ties and accumulate the tie correction term
+	var tieTerm float64
+	for i := 0; i < len(all); {
+		j := i
+		for j < len(all) && all[j].value == all[i].value {
+			j++
+		}
+		rank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			all[k].rank = rank
+		}
+		t := float64(j - i)
+		tieTerm += t*t*t - t
+		i = j
+	}
+
+	var rankSumA float64
+	for _, r := range all {
+		if r.fromA {
+			rankSumA += r.rank
+		}
+	}
+
+	u = rankSumA - n1*(n1+1)/2
+	n := n1 + n2
+	mean := n1 * n2 / 2
+	variance := n1 * n2 / 12 * ((n + 1) - tieTerm/(n*(n-1)))
+	if variance <= 0 {
+		return u, 1
+	}
+
+	// continuity correction
+	z := (math.Abs(u-mean) - 0.5) / math.Sqrt(variance)
+	if z < 0 {
+		z = 0
+	}
+	return u, 2 * (1 - NormalCDF(z))
+}
+
+// BootstrapMedianDifference returns the observed difference median(b) -
+// median(a) together with a percentile bootstrap confidence interval.
+func BootstrapMedianDifference(a, b []float64, confidence float64, resamples int, rng *rand.Rand) Interval {
+	observed := Median(b) - Median(a)
+	if len(a) == 0 || len(b) == 0 || resamples <= 0 {
+		return Interval{Estimate: observed, Lower: math.NaN(), Upper: math.NaN()}
+	}
+
+	diffs := make([]float64, resamples)
+	sampleA := make([]float64, len(a))
+	sampleB := make([]float64, len(b))
+	for i := range diffs {
+		for j := range sampleA {
+			sampleA[j] = a[rng.Intn(len(a))]
+		}
+		for j := range sampleB {
+			sampleB[j] = b[rng.Intn(len(b))]
+		}
+		diffs[i] = Median(sampleB) - Median(sampleA)
+	}
+
+	sort.Float64s(diffs)
+	alpha := (1 - confidence) / 2
+	return Interval{
+		Estimate: observed,
+		Lower:    Quantile(diffs, alpha),
+		Upper:    Quantile(diffs, 1-alpha),
+	}
+}
+
+// ChiSquareHomogeneity tests whether two samples of categorical counts come
+// from the same distribution. It returns the statistic, degrees of freedom and p-value.
+func ChiSquareHomogeneity(a, b map[string]int) (chi2 float64, df int, p float64) {
+	categories := make(map[string]bool)
+	var totalA, totalB float64
+	for k, v := range a {
+		categories[k] = true
+		totalA += float64(v)
+	}
+	for k, v := range b {
+		categories[k] = true
+		totalB += float64(v)
+	}
+	if totalA == 0 || totalB == 0 || len(categories) < 2 {
+		return 0, 0, 1
+	}
+
+	total := totalA + totalB
+	for k := range categories {
+		column := float64(a[k] + b[k])
+		for _, obs := range []struct{ observed, rowTotal float64 }{
+			{float64(a[k]), totalA},
+			{float64(b[k]), totalB},
+		} {
+			expected := obs.rowTotal * column / total
+			chi2 += (obs.observed - expected) * (obs.observed - expected) / expected
+		}
+	}
+
+	df = len(categories) - 1
+	return chi2, df, 1 - regularizedGammaP(float64(df)/2, chi2/2)
+}
+
+// regularizedGammaP returns the regularized lower incomplete gamma function P(s, x).
+func regularizedGammaP(s, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	lgamma, _ := math.Lgamma(s)
+
+	if x < s+1 {
+		// series expansion
+		sum, term := 1/s, 1/s
+		for n := 1; n < 1000; n++ {
+			term *= x / (s + float64(n))
+			sum += term
+			if math.Abs(term) < math.Abs(sum)*1e-14 {
+				break
+			}
+		}
+		return sum * math.Exp(-x+s*math.Log(x)-lgamma)
+	}
+
+	// continued fraction for Q(s, x) (modified Lentz)
+	const tiny = 1e-300
+	b := x + 1 - s
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for n := 1; n < 1000; n++ {
+		an := -float64(n) * (float64(n) - s)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < 1e-14 {
+			break
+		}
+	}
+	return 1 - math.Exp(-x+s*math.Log(x)-lgamma)*h
+}
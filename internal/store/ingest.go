@@ -0,0 +1,188 @@
+package store
+
+import (
+	"ClassiFaaS/internal/analysis"
+	"ClassiFaaS/internal/results"
+	"ClassiFaaS/internal/utils"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// IngestResult counts the invocations of a run added to the store and those
+// skipped because they had been ingested before.
+type IngestResult struct {
+	Inserted int
+	Skipped  int
+}
+
+// Ingest loads a run into the store within a single transaction. Runs may be
+// ingested repeatedly; invocations already present are skipped.
+func (s *Store) Ingest(run *results.Run) (IngestResult, error) {
+	var result IngestResult
+
+	folder, err := filepath.Abs(run.Folder)
+	if err != nil {
+		return result, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback()
+
+	var end any
+	if run.Summary != nil {
+		end = formatTime(run.Summary.End)
+	}
+	var runID int64
+	err = tx.QueryRow(`INSERT INTO runs (folder, start, end) VALUES (?, ?, ?)
+		ON CONFLICT (folder) DO UPDATE SET start = excluded.start, end = COALESCE(excluded.end, runs.end)
+		RETURNING id`, folder, formatTime(run.Start()), end).Scan(&runID)
+	if err != nil {
+		return result, fmt.Errorf("failed to store run %s: %v", run.Folder, err)
+	}
+
+	insert, err := tx.Prepare(`INSERT OR IGNORE INTO invocations (request_id, function_id, cpu_id, agent,
+		client_start, latency_ms, attempts, new_container, instance_id, uuid, cpu_frequency_mhz,
+		runtime_ms, benchmark_metric, record) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return result, err
+	}
+	defer insert.Close()
+
+	cpus := make(map[string]int64)
+	for _, archive := range run.Archives {
+		var functionID int64
+		err = tx.QueryRow(`INSERT INTO functions (run_id, name, provider, region, memory_size, benchmark)
+			VALUES (?, ?, ?, ?, ?, ?)
+			ON CONFLICT (run_id, provider, region, name, memory_size) DO UPDATE SET benchmark = excluded.benchmark
+			RETURNING id`, runID, archive.Function(), archive.Provider(), archive.Region(),
+			archive.MemorySize(), analysis.BenchmarkType(archive)).Scan(&functionID)
+		if err != nil {
+			return result, fmt.Errorf("failed to store function %s: %v", archive.Function(), err)
+		}
+
+		relPath, _ := filepath.Rel(run.Folder, archive.Path)
+		for i := range archive.Records {
+			record := &archive.Records[i]
+
+			// Records without provider request ID, e.g. failed invocations,
+			// are keyed by their position in the run instead.
+			requestID := record.Header.RequestID()
+			if requestID == "" {
+				requestID = fmt.Sprintf("%s/%s#%d", folder, relPath, i)
+			}
+
+			cpuID, err := cpuFingerprint(tx, cpus, record)
+			if err != nil {
+				return result, err
+			}
+
+			raw, err := json.Marshal(record)
+			if err != nil {
+				return result, err
+			}
+
+			var clientStart, latency, attempts any
+			if record.Client != nil {
+				clientStart = formatTime(record.Client.Start)
+				latency = record.Client.LatencyMs
+				attempts = record.Client.Attempts
+			}
+
+			res, err := insert.Exec(requestID, functionID, cpuID, nullString(record.Agent),
+				clientStart, latency, attempts,
+				bodyFloat(record, "newcontainer"), bodyString(record, "instanceId"), bodyString(record, "uuid"),
+				bodyFloat(record, "cpuFrequencyMHz"), bodyFloat(record, "runtime"),
+				metric(record, analysis.MetricBenchmark), string(raw))
+			if err != nil {
+				return result, fmt.Errorf("failed to store invocation %s: %v", requestID, err)
+			}
+			if n, _ := res.RowsAffected(); n > 0 {
+				result.Inserted++
+			} else {
+				result.Skipped++
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// cpuFingerprint returns the id of the record's CPU fingerprint, inserting it
+// if needed, or nil if the record carries no CPU information.
+func cpuFingerprint(tx *sql.Tx, cache map[string]int64, record *utils.BenchmarkResponse) (any, error) {
+	cpuType, _ := record.LookupString("cpuType")
+	vendor, _ := record.LookupString("cpuVendor")
+	model, _ := record.LookupString("cpuModel")
+	if cpuType == "" && vendor == "" && model == "" {
+		return nil, nil
+	}
+
+	cacheSize := ""
+	if v, ok := record.Lookup("cpuCacheSizeKB"); ok && v != nil {
+		cacheSize = fmt.Sprint(v)
+	}
+	var flags []string
+	if v, ok := record.Lookup("cpuFlags"); ok {
+		if list, ok := v.([]any); ok {
+			for _, flag := range list {
+				flags = append(flags, fmt.Sprint(flag))
+			}
+		} else if s, ok := v.(string); ok {
+			flags = strings.Fields(s)
+		}
+	}
+	flagList := strings.Join(flags, " ")
+
+	key := strings.Join([]string{cpuType, vendor, model, cacheSize, flagList}, "\x00")
+	if id, ok := cache[key]; ok {
+		return id, nil
+	}
+
+	var id int64
+	err := tx.QueryRow(`INSERT INTO cpu_fingerprints (type, vendor, model, cache_size_kb, flags)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (type, vendor, model, cache_size_kb, flags) DO UPDATE SET type = excluded.type
+		RETURNING id`, cpuType, vendor, model, cacheSize, flagList).Scan(&id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store cpu fingerprint: %v", err)
+	}
+	cache[key] = id
+	return id, nil
+}
+
+func nullString(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func bodyString(record *utils.BenchmarkResponse, path string) any {
+	if v, ok := record.LookupString(path); ok {
+		return v
+	}
+	return nil
+}
+
+func bodyFloat(record *utils.BenchmarkResponse, path string) any {
+	if v, ok := record.LookupFloat(path); ok {
+		return v
+	}
+	return nil
+}
+
+func metric(record *utils.BenchmarkResponse, name string) any {
+	if v, ok := analysis.MetricValue(record, name); ok {
+		return v
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package store
+
+import (
+	"ClassiFaaS/internal/analysis"
+	"ClassiFaaS/internal/stats"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Periods reports can be aggregated by.
+const (
+	PeriodDay  = "day"
+	PeriodWeek = "week"
+)
+
+// periodOf labels t with its day (2006-01-02) or ISO week (2006-W01).
+func periodOf(t time.Time, period string) string {
+	if period == PeriodDay {
+		return t.UTC().Format("2006-01-02")
+	}
+	year, week := t.UTC().ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
+
+func validPeriod(period string) error {
+	if period != PeriodDay && period != PeriodWeek {
+		return fmt.Errorf("unknown period %q, expected %q or %q", period, PeriodDay, PeriodWeek)
+	}
+	return nil
+}
+
+// CPUShare is the share of invocations that ran on a CPU type within a period.
+type CPUShare struct {
+	Period   string  `json:"period"`
+	Provider string  `json:"provider"`
+	Region   string  `json:"region"`
+	CPU      string  `json:"cpu"`
+	Count    int     `json:"count"`
+	Share    float64 `json:"share"`
+}
+
+// CPUMix reports the CPU type mix per provider and region over time.
+func (s *Store) CPUMix(period string) ([]CPUShare, error) {
+	if err := validPeriod(period); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`SELECT r.start, f.provider, f.region, c.type, COUNT(*)
+		FROM invocations i
+		JOIN functions f ON f.id = i.function_id
+		JOIN runs r ON r.id = f.run_id
+		JOIN cpu_fingerprints c ON c.id = i.cpu_id
+		GROUP BY r.id, f.provider, f.region, c.type`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type groupKey struct{ period, provider, region string }
+	counts := make(map[groupKey]map[string]int)
+	for rows.Next() {
+		var start, provider, region, cpu string
+		var count int
+		if err := rows.Scan(&start, &provider, &region, &cpu, &count); err != nil {
+			return nil, err
+		}
+		key := groupKey{periodOf(parseTime(start), period), provider, region}
+		if counts[key] == nil {
+			counts[key] = make(map[string]int)
+		}
+		counts[key][cpu] += count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var shares []CPUShare
+	for key, cpus := range counts {
+		total := 0
+		for _, count := range cpus {
+			total += count
+		}
+		for cpu, count := range cpus {
+			shares = append(shares, CPUShare{
+				Period:   key.period,
+				Provider: key.provider,
+				Region:   key.region,
+				CPU:      cpu,
+				Count:    count,
+				Share:    float64(count) / float64(total),
+			})
+		}
+	}
+	sort.Slice(shares, func(i, j int) bool {
+		a, b := shares[i], shares[j]
+		if a.Period != b.Period {
+			return a.Period < b.Period
+		}
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		if a.Region != b.Region {
+			return a.Region < b.Region
+		}
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.CPU < b.CPU
+	})
+	return shares, nil
+}
+
+// MemoryMedian is the median of a metric for a benchmark and memory size within a period.
+type MemoryMedian struct {
+	Period     string  `json:"period"`
+	Provider   string  `json:"provider"`
+	Region     string  `json:"region"`
+	Benchmark  string  `json:"benchmark"`
+	MemorySize int     `json:"memorySize"`
+	Count      int     `json:"count"`
+	Median     float64 `json:"median"`
+}
+
+// metricColumns maps the metrics available in the store to their column.
+var metricColumns = map[string]string{
+	analysis.MetricBenchmark: "i.benchmark_metric",
+	analysis.MetricLatency:   "i.latency_ms",
+}
+
+// MemoryMedians reports the median of metric (MetricBenchmark or
+// MetricLatency) per benchmark and memory size over time.
+func (s *Store) MemoryMedians(metric, period string) ([]MemoryMedian, error) {
+	if err := validPeriod(period); err != nil {
+		return nil, err
+	}
+	column, ok := metricColumns[metric]
+	if !ok {
+		return nil, fmt.Errorf("unknown metric %q, expected %q or %q", metric, analysis.MetricBenchmark, analysis.MetricLatency)
+	}
+
+	rows, err := s.db.Query(`SELECT r.start, f.provider, f.region, f.benchmark, f.memory_size, ` + column + `
+		FROM invocations i
+		JOIN functions f ON f.id = i.function_id
+		JOIN runs r ON r.id = f.run_id
+		WHERE ` + column + ` IS NOT NULL`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type groupKey struct {
+		period, provider, region, benchmark string
+		memorySize                          int
+	}
+	values := make(map[groupKey][]float64)
+	for rows.Next() {
+		var start string
+		var key groupKey
+		var value float64
+		if err := rows.Scan(&start, &key.provider, &key.region, &key.benchmark, &key.memorySize, &value); err != nil {
+			return nil, err
+		}
+		key.period = periodOf(parseTime(start), period)
+		values[key] = append(values[key], value)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var medians []MemoryMedian
+	for key, v := range values {
+		medians = append(medians, MemoryMedian{
+			Period:     key.period,
+			Provider:   key.provider,
+			Region:     key.region,
+			Benchmark:  key.benchmark,
+			MemorySize: key.memorySize,
+			Count:      len(v),
+			Median:     stats.Median(v),
+		})
+	}
+	sort.Slice(medians, func(i, j int) bool {
+		a, b := medians[i], medians[j]
+		if a.Period != b.Period {
+			return a.Period < b.Period
+		}
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		if a.Region != b.Region {
+			return a.Region < b.Region
+		}
+		if a.Benchmark != b.Benchmark {
+			return a.Benchmark < b.Benchmark
+		}
+		return a.MemorySize < b.MemorySize
+	})
+	return medians, nil
+}
@@ -0,0 +1,96 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// schema creates the store tables. Invocations are keyed by the provider
+// request ID, so ingesting a run twice does not duplicate its invocations.
+const schema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id INTEGER PRIMARY KEY,
+	folder TEXT NOT NULL UNIQUE,
+	start TEXT NOT NULL,
+	end TEXT
+);
+
+CREATE TABLE IF NOT EXISTS functions (
+	id INTEGER PRIMARY KEY,
+	run_id INTEGER NOT NULL REFERENCES runs(id),
+	name TEXT NOT NULL,
+	provider TEXT NOT NULL,
+	region TEXT NOT NULL,
+	memory_size INTEGER NOT NULL,
+	benchmark TEXT NOT NULL,
+	UNIQUE (run_id, provider, region, name, memory_size)
+);
+
+CREATE TABLE IF NOT EXISTS cpu_fingerprints (
+	id INTEGER PRIMARY KEY,
+	type TEXT NOT NULL,
+	vendor TEXT NOT NULL,
+	model TEXT NOT NULL,
+	cache_size_kb TEXT NOT NULL,
+	flags TEXT NOT NULL,
+	UNIQUE (type, vendor, model, cache_size_kb, flags)
+);
+
+CREATE TABLE IF NOT EXISTS invocations (
+	id INTEGER PRIMARY KEY,
+	request_id TEXT NOT NULL UNIQUE,
+	function_id INTEGER NOT NULL REFERENCES functions(id),
+	cpu_id INTEGER REFERENCES cpu_fingerprints(id),
+	agent TEXT,
+	client_start TEXT,
+	latency_ms REAL,
+	attempts INTEGER,
+	new_container INTEGER,
+	instance_id TEXT,
+	uuid TEXT,
+	cpu_frequency_mhz REAL,
+	runtime_ms REAL,
+	benchmark_metric REAL,
+	record TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS invocations_function ON invocations(function_id);
+`
+
+// timeLayout is the layout times are stored with; it sorts lexically.
+const timeLayout = "2006-01-02T15:04:05.000000000Z"
+
+// Store is a SQLite database holding ingested benchmark runs.
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens the store at path, creating the database and its tables if needed.
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(10000)&_pragma=foreign_keys(1)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open store %s: %v", path, err)
+	}
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create store schema in %s: %v", path, err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the underlying database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+func parseTime(value string) time.Time {
+	t, _ := time.Parse(timeLayout, value)
+	return t
+}
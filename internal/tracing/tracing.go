@@ -0,0 +1,68 @@
+package tracing
+
+import (
+	"ClassiFaaS/internal/config"
+	"context"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName identifies the spans created by the load generator.
+const InstrumentationName = "ClassiFaaS/internal/loadgenerator"
+
+// Provider exports spans to an OTLP collector.
+type Provider struct {
+	provider *sdktrace.TracerProvider
+}
+
+// NewProvider creates a tracer provider exporting spans via OTLP/HTTP as
+// configured. Spans are batched; Shutdown flushes them.
+func NewProvider(ctx context.Context, cfg config.TracingConfig) (*Provider, error) {
+	var options []otlptracehttp.Option
+	if cfg.Endpoint != "" {
+		if strings.Contains(cfg.Endpoint, "://") {
+			options = append(options, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+		} else {
+			options = append(options, otlptracehttp.WithEndpoint(cfg.Endpoint))
+		}
+	}
+	if cfg.Insecure {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+	if len(cfg.Headers) > 0 {
+		options = append(options, otlptracehttp.WithHeaders(cfg.Headers))
+	}
+
+	exporter, err := otlptracehttp.New(ctx, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %v", err)
+	}
+
+	ratio := 1.0
+	if cfg.SampleRatio != nil {
+		ratio = *cfg.SampleRatio
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", cfg.ServiceName))),
+	)
+	return &Provider{provider: provider}, nil
+}
+
+// Tracer returns the tracer used for invocation spans.
+func (p *Provider) Tracer() trace.Tracer {
+	return p.provider.Tracer(InstrumentationName)
+}
+
+// Shutdown flushes pending spans and stops the exporter.
+func (p *Provider) Shutdown(ctx context.Context) error {
+	return p.provider.Shutdown(ctx)
+}
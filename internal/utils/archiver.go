@@ -3,6 +3,7 @@ package utils
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"os/signal"
@@ -17,6 +18,7 @@ import (
 type ArchiveClient struct {
 	debugMode  bool
 	writer     *bufio.Writer
+	closer     io.Closer
 	writeChan  chan string
 	allWritten sync.WaitGroup
 }
@@ -60,18 +62,32 @@ func NewFileArchiveClient(filePath string, metadata string) (*ArchiveClient, err
 		return nil, fmt.Errorf("failed to create file: %w", err)
 	}
 
+	return NewArchiveClient(file, metadata)
+}
+
+// NewArchiveClient creates an ArchiveClient writing to an arbitrary writer,
+// e.g. a network stream. The metadata line is omitted if metadata is empty.
+// If w is an io.Closer, it is closed when the client is stopped.
+func NewArchiveClient(w io.Writer, metadata string) (*ArchiveClient, error) {
 	// Get the block size of the system, and use it to optimize the buffer size
 	bs, err := systemsBlockSize()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get block size: %w", err)
 	}
 
-	writer := bufio.NewWriterSize(file, bs)
+	writer := bufio.NewWriterSize(w, bs)
 
 	ac := &ArchiveClient{
 		writer:    writer,
 		writeChan: make(chan string),
 	}
+	if closer, ok := w.(io.Closer); ok {
+		ac.closer = closer
+	}
+
+	if metadata == "" {
+		return ac, nil
+	}
 
 	_, err = ac.writer.WriteString(metadata + "\n")
 	if err != nil {
@@ -102,19 +118,25 @@ func (ac *ArchiveClient) Start() {
 		os.Exit(0)
 	}()
 
+	ac.StartWriting()
+}
+
+// StartWriting starts the archive client like Start, but leaves stopping it
+// on interrupts to the caller.
+func (ac *ArchiveClient) StartWriting() {
 	if ac.debugMode {
 		go func() {
 			for range ac.writeChan {
 			}
 		}()
 	}
+	ac.allWritten.Add(1)
 	go ac.writeToFile()
 }
 
 // writeToFile writes the data from the write channel to the file using the writer
 // from the bufio package.
 func (ac *ArchiveClient) writeToFile() {
-	ac.allWritten.Add(1)
 	defer ac.allWritten.Done()
 	for line := range ac.writeChan {
 		_, err := ac.writer.WriteString(line)
@@ -132,6 +154,11 @@ func (ac *ArchiveClient) Stop() {
 	close(ac.writeChan)
 	ac.allWritten.Wait()
 	ac.writer.Flush()
+	if ac.closer != nil {
+		if err := ac.closer.Close(); err != nil {
+			fmt.Println("Error closing archive:", err)
+		}
+	}
 
 	time.Sleep(5 * time.Second) // wait for any last writes to finish
 }
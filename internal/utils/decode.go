@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"strings"
+	"time"
 )
 
 type Header struct {
@@ -13,9 +15,44 @@ type Header struct {
 	ALIBABARequestID  string `json:"ali-request-id,omitempty"`
 }
 
+// RequestID returns the provider request ID of the invocation, or an empty
+// string if the provider did not return one.
+func (h Header) RequestID() string {
+	for _, id := range []string{h.AWSRequestID, h.GCPRequestID, h.AZUREInvocationID, h.ALIBABARequestID} {
+		if id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// ClientTiming holds the timings measured by the load generator for a single invocation.
+type ClientTiming struct {
+	Start     time.Time `json:"start"`
+	LatencyMs float64   `json:"latencyMs"`
+	Attempts  int       `json:"attempts"`
+}
+
+// WorkloadInfo describes the scheduled request that produced a response when
+// requests are fired at predefined offsets, e.g. during trace replay.
+type WorkloadInfo struct {
+	Source            string  `json:"source"`
+	ScheduledOffsetMs float64 `json:"scheduledOffsetMs"`
+	// LagMs is the delay between the scheduled and the actual start of the request.
+	LagMs         float64 `json:"lagMs"`
+	TraceFunction string  `json:"traceFunction,omitempty"`
+	// Burst and Cycle index the burst or period of a load pattern the request belongs to.
+	Burst *int `json:"burst,omitempty"`
+	Cycle *int `json:"cycle,omitempty"`
+}
+
 type BenchmarkResponse struct {
-	Header Header `json:"header"`
-	Body   any    `json:"body"`
+	Header   Header        `json:"header"`
+	Body     any           `json:"body"`
+	Client   *ClientTiming `json:"client,omitempty"`
+	Workload *WorkloadInfo `json:"workload,omitempty"`
+	// Agent identifies the load generator agent that issued the request in distributed runs.
+	Agent string `json:"agent,omitempty"`
 }
 
 func DecodeBenchmarkResponse(resp *http.Response) (*BenchmarkResponse, error) {
@@ -51,3 +88,39 @@ func (r *BenchmarkResponse) ToString() (string, error) {
 	}
 	return string(bytes), nil
 }
+
+// Lookup resolves a dotted path such as "benchmark.hashTimeMs" in the response body.
+func (r *BenchmarkResponse) Lookup(path string) (any, bool) {
+	var current any = r.Body
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		current, ok = obj[key]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// LookupFloat resolves a dotted path in the response body and returns it as a number.
+func (r *BenchmarkResponse) LookupFloat(path string) (float64, bool) {
+	value, ok := r.Lookup(path)
+	if !ok {
+		return 0, false
+	}
+	number, ok := value.(float64)
+	return number, ok
+}
+
+// LookupString resolves a dotted path in the response body and returns it as a string.
+func (r *BenchmarkResponse) LookupString(path string) (string, bool) {
+	value, ok := r.Lookup(path)
+	if !ok {
+		return "", false
+	}
+	str, ok := value.(string)
+	return str, ok
+}
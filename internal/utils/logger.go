@@ -2,18 +2,15 @@ package utils
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
-	"log"
 	"os"
-	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
-var logChannel = make(chan Event, 1000)
-var wg sync.WaitGroup
-
 type Severity string
 
 const (
@@ -22,93 +19,347 @@ const (
 	SeverityError   Severity = "error"
 )
 
+// level orders severities; unknown severities are treated as info.
+func (s Severity) level() int {
+	switch s {
+	case SeverityWarning:
+		return 1
+	case SeverityError:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// ParseSeverity parses "info", "warning" or "error".
+func ParseSeverity(s string) (Severity, error) {
+	switch Severity(s) {
+	case SeverityInfo, SeverityWarning, SeverityError:
+		return Severity(s), nil
+	}
+	return "", fmt.Errorf("unknown severity %q, expected info, warning or error", s)
+}
+
+// Scope identifies what an event relates to. All fields are optional.
+type Scope struct {
+	Provider string `json:"provider,omitempty"`
+	Region   string `json:"region,omitempty"`
+	Function string `json:"function,omitempty"`
+}
+
 type Event struct {
 	Time     time.Time `json:"time,omitempty"`
 	Severity Severity  `json:"severity,omitempty"`
 	Type     string    `json:"type,omitempty"`
-	Message  string    `json:"message,omitempty"`
+	Scope
+	Message string `json:"message,omitempty"`
+}
+
+// Sink writes events to a destination such as the console or a file.
+type Sink interface {
+	WriteEvent(event Event) error
+	Close() error
+}
+
+type logSink struct {
+	sink        Sink
+	minSeverity Severity
 }
 
-// Publisher wraps a send-only channel
-type EventPublisher chan<- Event
+// Backpressure decides what happens to an event published while the
+// logger's buffer is full.
+type Backpressure string
+
+const (
+	// BackpressureBlock waits until the event fits into the buffer.
+	BackpressureBlock Backpressure = "block"
+	// BackpressureDrop discards the event and counts it.
+	BackpressureDrop Backpressure = "drop"
+	// BackpressureSpill appends the event to a temporary file whose events
+	// are delivered in order once the buffer has drained.
+	BackpressureSpill Backpressure = "spill"
+)
+
+// ParseBackpressure parses "block", "drop" or "spill".
+func ParseBackpressure(s string) (Backpressure, error) {
+	switch Backpressure(s) {
+	case BackpressureBlock, BackpressureDrop, BackpressureSpill:
+		return Backpressure(s), nil
+	}
+	return "", fmt.Errorf("unknown backpressure policy %q, expected block, drop or spill", s)
+}
+
+// EventLoggerOptions configures an event logger. The zero value buffers 1000
+// events and blocks publishers while the buffer is full.
+type EventLoggerOptions struct {
+	BufferSize   int
+	Backpressure Backpressure
+	// SpillDir is the directory of the spill file, os.TempDir by default.
+	SpillDir string
+}
 
+// EventPublisher publishes events to the sinks of an event logger. It is
+// safe for concurrent use, including concurrently with Close. The zero
+// value discards all events.
+type EventPublisher struct {
+	logger *eventLogger
+}
+
+type eventLogger struct {
+	events       chan Event
+	backpressure Backpressure
+	spill        *spillQueue
+
+	// closeMu guards closing events against concurrent sends
+	closeMu sync.RWMutex
+	closed  bool
+	done    chan struct{}
+
+	sinksMu sync.Mutex
+	sinks   []*logSink
+
+	// accepted and delivered count the events entering and leaving the
+	// logger, so that a sink is only removed once earlier events reached it
+	accepted  atomic.Int64
+	delivered atomic.Int64
+	dropped   atomic.Int64
+	// droppedAfterClose counts the events published after Close, which are
+	// not part of the drop report
+	droppedAfterClose atomic.Int64
+}
+
+// NewEventLogger starts an event logger with a console sink writing all
+// events to stdout.
 func NewEventLogger() EventPublisher {
-	logger := log.New(os.Stdout, "", 0)
-	wg.Add(1)
-
-	go func() {
-		defer wg.Done()
-		for event := range logChannel {
-			reset := "\033[0m"
-			yellow := "\033[33m"
-			orange := "\033[38;5;208m"
-			green := "\033[32m"
-			magenta := "\033[35m"
-
-			// Set severity color
-			var sevColor string
-			switch event.Severity {
-			case "info":
-				sevColor = "\033[36m" // cyan
-			case "warn":
-				sevColor = "\033[33m" // yellow
-			case "error":
-				sevColor = "\033[31m" // red
+	ep, _ := NewEventLoggerWithOptions(EventLoggerOptions{})
+	ep.AddSink(NewConsoleSink(os.Stdout), SeverityInfo)
+	return ep
+}
+
+// NewEventLoggerWithOptions starts an event logger without sinks; attach
+// them with AddSink.
+func NewEventLoggerWithOptions(opts EventLoggerOptions) (EventPublisher, error) {
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = 1000
+	}
+	if opts.Backpressure == "" {
+		opts.Backpressure = BackpressureBlock
+	}
+
+	l := &eventLogger{
+		events:       make(chan Event, opts.BufferSize),
+		backpressure: opts.Backpressure,
+		done:         make(chan struct{}),
+	}
+	if opts.Backpressure == BackpressureSpill {
+		spill, err := newSpillQueue(opts.SpillDir)
+		if err != nil {
+			return EventPublisher{}, err
+		}
+		l.spill = spill
+	}
+
+	go l.run()
+	return EventPublisher{logger: l}, nil
+}
+
+// run delivers buffered events until the buffer is closed, followed by the
+// spilled events whenever the buffer has drained.
+func (l *eventLogger) run() {
+	defer close(l.done)
+	for event := range l.events {
+		l.deliver(event)
+		if l.spill != nil && len(l.events) == 0 {
+			l.deliverSpilled()
+		}
+	}
+	if l.spill != nil {
+		l.deliverSpilled()
+		l.spill.close()
+	}
+}
+
+func (l *eventLogger) deliverSpilled() {
+	events, err := l.spill.drain()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read spilled events: %v\n", err)
+	}
+	for _, event := range events {
+		l.deliver(event)
+	}
+}
+
+func (l *eventLogger) deliver(event Event) {
+	defer l.delivered.Add(1)
+
+	l.sinksMu.Lock()
+	defer l.sinksMu.Unlock()
+	for _, s := range l.sinks {
+		if event.Severity.level() < s.minSeverity.level() {
+			continue
+		}
+		if err := s.sink.WriteEvent(event); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write event: %v\n", err)
+		}
+	}
+}
+
+// publish hands an event to the buffer according to the backpressure policy.
+func (l *eventLogger) publish(event Event) {
+	l.closeMu.RLock()
+	defer l.closeMu.RUnlock()
+	if l.closed {
+		l.droppedAfterClose.Add(1)
+		return
+	}
+
+	switch l.backpressure {
+	case BackpressureDrop:
+		select {
+		case l.events <- event:
+		default:
+			l.dropped.Add(1)
+			return
+		}
+	case BackpressureSpill:
+		// once spilling started, later events are spilled as well to keep
+		// them in order
+		if !l.spill.pushIfPending(event) {
+			select {
+			case l.events <- event:
 			default:
-				sevColor = "\033[0m" // default
+				if err := l.spill.push(event); err != nil {
+					fmt.Fprintf(os.Stderr, "failed to spill event: %v\n", err)
+					l.dropped.Add(1)
+					return
+				}
 			}
+		}
+	default:
+		l.events <- event
+	}
+	l.accepted.Add(1)
+}
+
+// AddSink attaches a sink receiving events of at least minSeverity. The
+// returned function detaches and closes the sink once the events published
+// so far have been delivered; sinks still attached are closed by Close.
+func (p EventPublisher) AddSink(sink Sink, minSeverity Severity) (remove func() error) {
+	l := p.logger
+	if l == nil {
+		return sink.Close
+	}
+	entry := &logSink{sink: sink, minSeverity: minSeverity}
+
+	l.sinksMu.Lock()
+	l.sinks = append(l.sinks, entry)
+	l.sinksMu.Unlock()
 
-			typeStr := event.Type
-			switch {
-			case strings.Contains(strings.ToLower(event.Type), "gcp"):
-				typeStr = yellow + event.Type + reset
-			case strings.Contains(strings.ToLower(event.Type), "aws"):
-				typeStr = orange + event.Type + reset
-			case strings.Contains(strings.ToLower(event.Type), "azure"):
-				typeStr = green + event.Type + reset
-			case strings.Contains(strings.ToLower(event.Type), "alibaba"):
-				typeStr = magenta + event.Type + reset
+	return func() error {
+		target := l.accepted.Load()
+	wait:
+		for l.delivered.Load() < target {
+			select {
+			case <-l.done:
+				break wait
+			case <-time.After(10 * time.Millisecond):
 			}
+		}
 
-			logger.Printf("[%s] [%s%s%s] [%s] %s",
-				event.Time.Format(time.RFC3339),
-				sevColor, event.Severity, reset,
-				typeStr,
-				event.Message,
-			)
+		l.sinksMu.Lock()
+		defer l.sinksMu.Unlock()
+		for i, s := range l.sinks {
+			if s == entry {
+				l.sinks = append(l.sinks[:i], l.sinks[i+1:]...)
+				return sink.Close()
+			}
 		}
-	}()
+		return nil
+	}
+}
 
-	return EventPublisher(logChannel)
+// Dropped returns the number of events discarded so far by the drop policy,
+// a failing spill file or because they were published after Close.
+func (p EventPublisher) Dropped() int64 {
+	if p.logger == nil {
+		return 0
+	}
+	return p.logger.dropped.Load() + p.logger.droppedAfterClose.Load()
 }
 
+// Close delivers all pending events, reports dropped events to the sinks and
+// closes them. It may be called repeatedly and concurrently with publishers;
+// events published afterwards are dropped.
 func (p EventPublisher) Close() {
-	close(logChannel)
-	wg.Wait()
+	l := p.logger
+	if l == nil {
+		return
+	}
+
+	l.closeMu.Lock()
+	if l.closed {
+		l.closeMu.Unlock()
+		<-l.done
+		return
+	}
+	l.closed = true
+	close(l.events)
+	l.closeMu.Unlock()
+
+	<-l.done
+
+	l.sinksMu.Lock()
+	defer l.sinksMu.Unlock()
+	if dropped := l.dropped.Load(); dropped > 0 {
+		event := Event{
+			Time:     time.Now(),
+			Severity: SeverityWarning,
+			Type:     "event_logger",
+			Message:  fmt.Sprintf("Dropped %d events because the event buffer was full", dropped),
+		}
+		for _, s := range l.sinks {
+			s.sink.WriteEvent(event)
+		}
+	}
+	for _, s := range l.sinks {
+		s.sink.Close()
+	}
+	l.sinks = nil
 }
 
-// SendEvent sends an event through the send-only channel
+// SendEvent sends an event to the logger
 func (p EventPublisher) SendEvent(severity Severity, eventType string, message string) {
-	select {
-	case p <- Event{
+	p.SendScopedEvent(Scope{}, severity, eventType, message)
+}
+
+// SendScopedEvent sends an event relating to the given provider, region or function.
+func (p EventPublisher) SendScopedEvent(scope Scope, severity Severity, eventType string, message string) {
+	if p.logger == nil {
+		return
+	}
+	p.logger.publish(Event{
 		Time:     time.Now(),
 		Severity: severity,
 		Type:     eventType,
+		Scope:    scope,
 		Message:  message,
-	}:
-	default:
-		fmt.Printf("event channel is full, dropping event: %s\n", message)
-	}
+	})
 }
 
 func (p EventPublisher) StreamToEvents(pipe io.ReadCloser, eventType string) {
+	p.StreamToScopedEvents(pipe, Scope{}, eventType)
+}
+
+// StreamToScopedEvents publishes every line read from pipe as a scoped event.
+func (p EventPublisher) StreamToScopedEvents(pipe io.ReadCloser, scope Scope, eventType string) {
 	defer pipe.Close()
 	scanner := bufio.NewScanner(pipe)
 	for scanner.Scan() {
 		line := scanner.Text()
-		p.SendEvent(SeverityInfo, eventType, line)
+		p.SendScopedEvent(scope, SeverityInfo, eventType, line)
 	}
-	if err := scanner.Err(); err != nil {
-		p.SendEvent(SeverityError, eventType, fmt.Sprintf("error reading pipe: %v", err))
+	// a pipe closed by exec.Cmd.Wait ends the stream
+	if err := scanner.Err(); err != nil && !errors.Is(err, os.ErrClosed) {
+		p.SendScopedEvent(scope, SeverityError, eventType, fmt.Sprintf("error reading pipe: %v", err))
 	}
 }
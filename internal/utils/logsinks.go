@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	colorReset   = "\033[0m"
+	colorCyan    = "\033[36m"
+	colorYellow  = "\033[33m"
+	colorRed     = "\033[31m"
+	colorOrange  = "\033[38;5;208m"
+	colorGreen   = "\033[32m"
+	colorMagenta = "\033[35m"
+	colorBlue    = "\033[34m"
+)
+
+// providerColors colours the scope of events by provider.
+var providerColors = map[string]string{
+	"gcp":     colorYellow,
+	"aws":     colorOrange,
+	"azure":   colorGreen,
+	"alibaba": colorMagenta,
+	"local":   colorBlue,
+}
+
+// ConsoleSink writes human-readable, colour-coded events.
+type ConsoleSink struct {
+	w io.Writer
+}
+
+// NewConsoleSink creates a console sink writing to w.
+func NewConsoleSink(w io.Writer) *ConsoleSink {
+	return &ConsoleSink{w: w}
+}
+
+func (c *ConsoleSink) WriteEvent(event Event) error {
+	var sevColor string
+	switch event.Severity {
+	case SeverityInfo:
+		sevColor = colorCyan
+	case SeverityWarning:
+		sevColor = colorYellow
+	case SeverityError:
+		sevColor = colorRed
+	default:
+		sevColor = colorReset
+	}
+
+	var scope []string
+	for _, part := range []string{event.Provider, event.Region, event.Function} {
+		if part != "" {
+			scope = append(scope, part)
+		}
+	}
+	scopeStr := ""
+	if len(scope) > 0 {
+		scopeStr = fmt.Sprintf(" [%s%s%s]", providerColor(event), strings.Join(scope, "/"), colorReset)
+	}
+
+	_, err := fmt.Fprintf(c.w, "[%s] [%s%s%s] [%s]%s %s\n",
+		event.Time.Format(time.RFC3339),
+		sevColor, event.Severity, colorReset,
+		event.Type,
+		scopeStr,
+		event.Message,
+	)
+	return err
+}
+
+// providerColor returns the colour of the event's provider, falling back to
+// the provider named in the event type for unscoped events.
+func providerColor(event Event) string {
+	if color, ok := providerColors[event.Provider]; ok {
+		return color
+	}
+	eventType := strings.ToLower(event.Type)
+	for provider, color := range providerColors {
+		if strings.Contains(eventType, provider) {
+			return color
+		}
+	}
+	return colorReset
+}
+
+func (c *ConsoleSink) Close() error {
+	return nil
+}
+
+// JSONFileSink appends events as JSON lines to a file.
+type JSONFileSink struct {
+	file    *os.File
+	encoder *json.Encoder
+}
+
+// NewJSONFileSink opens path for appending, creating missing directories.
+func NewJSONFileSink(path string) (*JSONFileSink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %v", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %v", err)
+	}
+	return &JSONFileSink{file: file, encoder: json.NewEncoder(file)}, nil
+}
+
+func (j *JSONFileSink) WriteEvent(event Event) error {
+	return j.encoder.Encode(event)
+}
+
+func (j *JSONFileSink) Close() error {
+	return j.file.Close()
+}
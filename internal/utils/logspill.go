@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// spillQueue is a FIFO of events in a temporary JSON lines file, used by the
+// spill backpressure policy while the logger's buffer is full.
+type spillQueue struct {
+	mu      sync.Mutex
+	file    *os.File
+	encoder *json.Encoder
+	// pending is the number of events written but not yet drained
+	pending int
+}
+
+func newSpillQueue(dir string) (*spillQueue, error) {
+	file, err := os.CreateTemp(dir, "classifaas-events-*.jsonl")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create spill file: %v", err)
+	}
+	return &spillQueue{file: file, encoder: json.NewEncoder(file)}, nil
+}
+
+// push appends an event to the queue.
+func (q *spillQueue) push(event Event) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.write(event)
+}
+
+// pushIfPending appends an event only if the queue is not empty, which
+// keeps events published while draining behind the spilled ones.
+func (q *spillQueue) pushIfPending(event Event) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.pending == 0 {
+		return false
+	}
+	if err := q.write(event); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to spill event: %v\n", err)
+	}
+	return true
+}
+
+func (q *spillQueue) write(event Event) error {
+	if err := q.encoder.Encode(event); err != nil {
+		return err
+	}
+	q.pending++
+	return nil
+}
+
+// drain returns all queued events and truncates the file.
+func (q *spillQueue) drain() ([]Event, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.pending == 0 {
+		return nil, nil
+	}
+	q.pending = 0
+
+	if _, err := q.file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	var readErr error
+	scanner := bufio.NewScanner(q.file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			readErr = err
+			continue
+		}
+		events = append(events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		readErr = err
+	}
+
+	if err := q.file.Truncate(0); err != nil {
+		return events, err
+	}
+	if _, err := q.file.Seek(0, io.SeekStart); err != nil {
+		return events, err
+	}
+	return events, readErr
+}
+
+// close removes the spill file.
+func (q *spillQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.file.Close()
+	os.Remove(q.file.Name())
+}
@@ -0,0 +1,50 @@
+//go:build !windows && !plan9
+
+package utils
+
+import (
+	"fmt"
+	"log/syslog"
+	"strings"
+)
+
+// SyslogSink forwards events to the local syslog daemon.
+type SyslogSink struct {
+	writer *syslog.Writer
+}
+
+// NewSyslogSink connects to the local syslog daemon, tagging messages with tag.
+func NewSyslogSink(tag string) (*SyslogSink, error) {
+	writer, err := syslog.New(syslog.LOG_INFO|syslog.LOG_USER, tag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to syslog: %v", err)
+	}
+	return &SyslogSink{writer: writer}, nil
+}
+
+func (s *SyslogSink) WriteEvent(event Event) error {
+	var scope []string
+	for _, part := range []string{event.Provider, event.Region, event.Function} {
+		if part != "" {
+			scope = append(scope, part)
+		}
+	}
+	message := event.Type
+	if len(scope) > 0 {
+		message += " [" + strings.Join(scope, "/") + "]"
+	}
+	message += ": " + event.Message
+
+	switch event.Severity {
+	case SeverityError:
+		return s.writer.Err(message)
+	case SeverityWarning:
+		return s.writer.Warning(message)
+	default:
+		return s.writer.Info(message)
+	}
+}
+
+func (s *SyslogSink) Close() error {
+	return s.writer.Close()
+}
@@ -0,0 +1,21 @@
+//go:build windows || plan9
+
+package utils
+
+import "fmt"
+
+// SyslogSink is not supported on this platform.
+type SyslogSink struct{}
+
+// NewSyslogSink always fails on platforms without syslog.
+func NewSyslogSink(tag string) (*SyslogSink, error) {
+	return nil, fmt.Errorf("syslog is not supported on this platform")
+}
+
+func (s *SyslogSink) WriteEvent(event Event) error {
+	return nil
+}
+
+func (s *SyslogSink) Close() error {
+	return nil
+}
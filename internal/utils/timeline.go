@@ -1,8 +1,12 @@
 package utils
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"sync"
+	"time"
 )
 
 type RunMode int
@@ -12,39 +16,92 @@ const (
 	RunParallel
 )
 
+// Timeline is a graph of steps. Steps run once all steps they depend on
+// succeeded; steps without declared dependencies depend on the previous
+// step in RunSequential mode and on nothing in RunParallel mode.
 type Timeline struct {
 	Description string
 	Mode        RunMode
 	Steps       []*Step
+	// MaxConcurrency bounds the number of steps running at once. Zero
+	// means unbounded.
+	MaxConcurrency int
+	// FailFast cancels running steps and skips all steps not started yet
+	// once a step failed. Otherwise only dependents of failed steps are
+	// skipped.
+	FailFast bool
+
+	mu      sync.Mutex
+	reports []StepReport
 }
 
 type StepFunc func(eventPublisher EventPublisher) error
 
+// StepContextFunc is a step function that stops when ctx is cancelled, e.g.
+// because the step timed out or another step failed in fail-fast mode.
+type StepContextFunc func(ctx context.Context, eventPublisher EventPublisher) error
+
 type Step struct {
-	Run StepFunc
+	// Name identifies the step in DependsOn and events. Steps added without
+	// name are named after their position, e.g. "step-1".
+	Name      string
+	DependsOn []string
+	// Run or RunContext is the step function. A Run function cannot be
+	// interrupted; on timeout or cancellation it is abandoned and keeps
+	// running in the background.
+	Run        StepFunc
+	RunContext StepContextFunc
+
+	// Timeout limits every attempt of the step. Zero means no limit.
+	Timeout time.Duration
+	// Retries is the number of times a failed attempt is repeated, after
+	// RetryDelay.
+	Retries    int
+	RetryDelay time.Duration
+}
+
+// StepStatus is the outcome of a step.
+type StepStatus string
+
+const (
+	StepSucceeded StepStatus = "succeeded"
+	StepFailed    StepStatus = "failed"
+	// StepSkipped steps were not run because a dependency failed.
+	StepSkipped StepStatus = "skipped"
+	// StepCancelled steps were interrupted or not started because the run
+	// was cancelled.
+	StepCancelled StepStatus = "cancelled"
+)
+
+// StepReport describes how a step of the last run ended.
+type StepReport struct {
+	Name     string
+	Status   StepStatus
+	Attempts int
+	Duration time.Duration
+	Err      error
 }
 
 func NewTimeline(description string, mode RunMode) *Timeline {
 	return &Timeline{Description: description, Mode: mode}
 }
 
-// Run executes the timeline according to the provided RunMode.
-// - events: channel to emit lifecycle events. Caller is responsible for closing it.
-// - mode: RunSequential or RunParallel.
-//
-// Panics inside step functions are recovered and emitted as EventStepPanic.
+// Run executes the steps of the timeline in dependency order, see RunContext.
 func (tl *Timeline) Run(eventPublisher EventPublisher) error {
+	return tl.RunContext(context.Background(), eventPublisher)
+}
+
+// RunContext executes the steps of the timeline in dependency order until
+// ctx is cancelled. Steps whose dependencies failed are skipped; the errors
+// of all failed steps are returned together. A summary of every step's
+// status and duration is published at the end.
+func (tl *Timeline) RunContext(ctx context.Context, eventPublisher EventPublisher) error {
 	// Emit timeline start
 	eventPublisher.SendEvent(SeverityInfo, "start_timeline", tl.Description)
-	var err error
-	if tl.Mode == RunParallel {
-		err = tl.runParallel(eventPublisher)
-	} else {
-		err = tl.runSequential(eventPublisher)
-	}
 
+	err := tl.run(ctx, eventPublisher)
 	if err != nil {
-		eventPublisher.SendEvent(SeverityError, "timeline_failed", err.Error())
+		eventPublisher.SendEvent(SeverityError, "timeline_failed", fmt.Sprintf("%s: %v", tl.Description, err))
 		return err
 	}
 
@@ -52,38 +109,252 @@ func (tl *Timeline) Run(eventPublisher EventPublisher) error {
 	return nil
 }
 
-// runSequential runs steps one after another.
-func (tl *Timeline) runSequential(eventPublisher EventPublisher) error {
-	for _, step := range tl.Steps {
-		// Call step safely
-		err := step.RunStep(eventPublisher)
-		if err != nil {
-			return err
+// Report returns the outcome of every step of the last run in step order.
+func (tl *Timeline) Report() []StepReport {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	return append([]StepReport(nil), tl.reports...)
+}
+
+// stepResult is sent by a finished step to the scheduler.
+type stepResult struct {
+	index    int
+	err      error
+	attempts int
+	duration time.Duration
+}
+
+func (tl *Timeline) run(ctx context.Context, eventPublisher EventPublisher) error {
+	deps, err := tl.dependencies()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// waiting counts the unfinished dependencies of every step
+	waiting := make([]int, len(tl.Steps))
+	dependents := make([][]int, len(tl.Steps))
+	for i, stepDeps := range deps {
+		waiting[i] = len(stepDeps)
+		for _, dep := range stepDeps {
+			dependents[dep] = append(dependents[dep], i)
 		}
 	}
-	return nil
+
+	var ready []int
+	for i := range tl.Steps {
+		if waiting[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	reports := make([]StepReport, len(tl.Steps))
+	for i, step := range tl.Steps {
+		reports[i] = StepReport{Name: step.Name}
+	}
+	results := make(chan stepResult)
+	var errs []error
+	running, finished := 0, 0
+
+	// skip marks a step and, transitively, its dependents as finished
+	// without running them
+	var skip func(i int, cause string)
+	skip = func(i int, cause string) {
+		if reports[i].Status != "" {
+			return
+		}
+		reports[i].Status = StepSkipped
+		finished++
+		eventPublisher.SendEvent(SeverityWarning, "step_skipped", fmt.Sprintf("%s: skipped because %s failed", tl.Steps[i].Name, cause))
+		for _, dependent := range dependents[i] {
+			skip(dependent, cause)
+		}
+	}
+
+	for finished < len(tl.Steps) {
+		// steps not started yet are cancelled once the run is
+		if ctx.Err() != nil {
+			for _, i := range ready {
+				reports[i].Status = StepCancelled
+				finished++
+			}
+			ready = nil
+			if running == 0 {
+				for i := range reports {
+					if reports[i].Status == "" {
+						reports[i].Status = StepCancelled
+						finished++
+					}
+				}
+				break
+			}
+		}
+
+		for len(ready) > 0 && (tl.MaxConcurrency <= 0 || running < tl.MaxConcurrency) {
+			i := ready[0]
+			ready = ready[1:]
+			running++
+			go func() {
+				results <- tl.Steps[i].run(ctx, i, eventPublisher)
+			}()
+		}
+
+		result := <-results
+		running--
+		finished++
+
+		report := &reports[result.index]
+		report.Attempts = result.attempts
+		report.Duration = result.duration
+		report.Err = result.err
+
+		if result.err != nil {
+			step := tl.Steps[result.index]
+			if ctx.Err() != nil && errors.Is(result.err, ctx.Err()) {
+				report.Status = StepCancelled
+				continue
+			}
+			report.Status = StepFailed
+			errs = append(errs, fmt.Errorf("%s: %w", step.Name, result.err))
+			eventPublisher.SendEvent(SeverityError, "step_failed", fmt.Sprintf("%s: %v", step.Name, result.err))
+			for _, dependent := range dependents[result.index] {
+				skip(dependent, step.Name)
+			}
+			if tl.FailFast {
+				cancel()
+			}
+			continue
+		}
+
+		report.Status = StepSucceeded
+		for _, dependent := range dependents[result.index] {
+			if reports[dependent].Status != "" {
+				continue
+			}
+			waiting[dependent]--
+			if waiting[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	tl.mu.Lock()
+	tl.reports = reports
+	tl.mu.Unlock()
+	tl.publishSummary(eventPublisher, reports)
+
+	if len(errs) == 0 && ctx.Err() != nil {
+		// cancelled by the caller
+		return ctx.Err()
+	}
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		return fmt.Errorf("%d steps failed: %w", len(errs), errors.Join(errs...))
+	}
+}
+
+// publishSummary publishes the counts of step outcomes followed by the
+// status and duration of each step.
+func (tl *Timeline) publishSummary(eventPublisher EventPublisher, reports []StepReport) {
+	counts := map[StepStatus]int{}
+	for _, report := range reports {
+		counts[report.Status]++
+	}
+	var parts []string
+	for _, status := range []StepStatus{StepSucceeded, StepFailed, StepSkipped, StepCancelled} {
+		if counts[status] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[status], status))
+		}
+	}
+	eventPublisher.SendEvent(SeverityInfo, "timeline_summary", fmt.Sprintf("%s: %s", tl.Description, strings.Join(parts, ", ")))
+
+	for _, report := range reports {
+		severity := SeverityInfo
+		switch report.Status {
+		case StepFailed:
+			severity = SeverityError
+		case StepSkipped, StepCancelled:
+			severity = SeverityWarning
+		}
+		message := fmt.Sprintf("%s: %s", report.Name, report.Status)
+		if report.Attempts > 0 {
+			message += fmt.Sprintf(" after %s", report.Duration.Round(time.Millisecond))
+		}
+		if report.Attempts > 1 {
+			message += fmt.Sprintf(" (%d attempts)", report.Attempts)
+		}
+		eventPublisher.SendEvent(severity, "step_summary", message)
+	}
 }
 
-// runParallel runs all steps concurrently.
-func (tl *Timeline) runParallel(eventPublisher EventPublisher) error {
-	var wg sync.WaitGroup
-	errors := []error{}
-	for _, step := range tl.Steps {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			if err := step.RunStep(eventPublisher); err != nil {
-				eventPublisher.SendEvent(SeverityError, "parallel_timeline_step_failed", err.Error())
-				errors = append(errors, err)
+// dependencies resolves the dependencies of every step to step indices and
+// rejects unknown names and cycles.
+func (tl *Timeline) dependencies() ([][]int, error) {
+	index := make(map[string]int, len(tl.Steps))
+	for i, step := range tl.Steps {
+		if step == nil || (step.Run == nil && step.RunContext == nil) {
+			return nil, fmt.Errorf("step %d or its step function is nil", i+1)
+		}
+		if step.Name == "" {
+			step.Name = fmt.Sprintf("step-%d", i+1)
+		}
+		if _, ok := index[step.Name]; ok {
+			return nil, fmt.Errorf("duplicate step name %q", step.Name)
+		}
+		index[step.Name] = i
+	}
+
+	deps := make([][]int, len(tl.Steps))
+	for i, step := range tl.Steps {
+		if len(step.DependsOn) == 0 && tl.Mode == RunSequential && i > 0 {
+			deps[i] = []int{i - 1}
+			continue
+		}
+		for _, name := range step.DependsOn {
+			dep, ok := index[name]
+			if !ok {
+				return nil, fmt.Errorf("step %q depends on unknown step %q", step.Name, name)
 			}
-		}()
+			deps[i] = append(deps[i], dep)
+		}
 	}
 
-	wg.Wait()
-	if len(errors) > 0 {
-		return fmt.Errorf("one or more parallel steps failed: %v", errors)
+	// depth-first search for cycles
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(tl.Steps))
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case visiting:
+			return fmt.Errorf("dependency cycle at step %q", tl.Steps[i].Name)
+		case visited:
+			return nil
+		}
+		state[i] = visiting
+		for _, dep := range deps[i] {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		state[i] = visited
+		return nil
 	}
-	return nil
+	for i := range tl.Steps {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+	return deps, nil
 }
 
 func (tl *Timeline) Step(run func(events EventPublisher) error) *Timeline {
@@ -94,15 +365,106 @@ func (tl *Timeline) Step(run func(events EventPublisher) error) *Timeline {
 	return tl
 }
 
+// NamedStep adds a step that runs once the named steps succeeded.
+func (tl *Timeline) NamedStep(name string, run func(events EventPublisher) error, dependsOn ...string) *Timeline {
+	tl.Steps = append(tl.Steps, &Step{Name: name, DependsOn: dependsOn, Run: run})
+	return tl
+}
+
+// AddStep adds a step configured with dependencies, timeout or retries.
+func (tl *Timeline) AddStep(step *Step) *Timeline {
+	tl.Steps = append(tl.Steps, step)
+	return tl
+}
+
+// SubTimeline adds a nested timeline as a single named step. The nested
+// timeline is cancelled together with the outer one.
+func (tl *Timeline) SubTimeline(name string, sub *Timeline, dependsOn ...string) *Timeline {
+	return tl.AddStep(&Step{Name: name, DependsOn: dependsOn, RunContext: sub.RunContext})
+}
+
+// run runs the step with retries until it succeeds, ctx is cancelled or all
+// attempts failed.
+func (s *Step) run(ctx context.Context, index int, eventPublisher EventPublisher) stepResult {
+	start := time.Now()
+	result := stepResult{index: index}
+	for {
+		result.attempts++
+		result.err = s.attempt(ctx, eventPublisher)
+		if result.err == nil || result.attempts > s.Retries || ctx.Err() != nil {
+			break
+		}
+
+		eventPublisher.SendEvent(SeverityWarning, "step_retry",
+			fmt.Sprintf("%s: attempt %d of %d failed: %v", s.Name, result.attempts, s.Retries+1, result.err))
+		select {
+		case <-ctx.Done():
+		case <-time.After(s.RetryDelay):
+		}
+	}
+	result.duration = time.Since(start)
+	return result
+}
+
+// attempt runs the step function once within the step's timeout.
+func (s *Step) attempt(ctx context.Context, eventPublisher EventPublisher) error {
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.Timeout)
+		defer cancel()
+	}
+
+	// context-aware functions return by themselves once ctx is done
+	if s.RunContext != nil {
+		return s.interruptedError(ctx, s.RunStepContext(ctx, eventPublisher))
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- s.RunStepContext(ctx, eventPublisher)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return s.interruptedError(ctx, ctx.Err())
+	}
+}
+
+// interruptedError makes err of an attempt interrupted by ctx wrap the
+// context's error, e.g. when a killed script reports its exit status.
+func (s *Step) interruptedError(ctx context.Context, err error) error {
+	if err == nil || ctx.Err() == nil {
+		return err
+	}
+	if !errors.Is(err, ctx.Err()) {
+		err = fmt.Errorf("%w: %v", ctx.Err(), err)
+	}
+	if s.Timeout > 0 && errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s: %w", s.Timeout, err)
+	}
+	return err
+}
+
+// RunStep runs the step function, recovering panics as errors.
 func (s *Step) RunStep(eventPublisher EventPublisher) error {
-	if s == nil || s.Run == nil {
+	return s.RunStepContext(context.Background(), eventPublisher)
+}
+
+// RunStepContext runs the step function with ctx, recovering panics as errors.
+func (s *Step) RunStepContext(ctx context.Context, eventPublisher EventPublisher) (err error) {
+	if s == nil || (s.Run == nil && s.RunContext == nil) {
 		eventPublisher.SendEvent(SeverityError, "invalid_step", "step or step function is nil")
 		return fmt.Errorf("step or step function is nil")
 	}
-	err := s.Run(eventPublisher)
-	if err != nil {
-		return err
+	defer func() {
+		if r := recover(); r != nil {
+			eventPublisher.SendEvent(SeverityError, "step_panic", fmt.Sprintf("%s: %v", s.Name, r))
+			err = fmt.Errorf("step panicked: %v", r)
+		}
+	}()
+	if s.RunContext != nil {
+		return s.RunContext(ctx, eventPublisher)
 	}
-
-	return nil
+	return s.Run(eventPublisher)
 }